@@ -106,6 +106,7 @@ type Manager struct {
 
 	addressMu sync.Mutex
 	accountMu sync.Mutex
+	policyMu  sync.Mutex
 }
 
 // NewManager creates a new account manager
@@ -342,6 +343,7 @@ func (m *Manager) DeleteAccount(accountID string) (err error) {
 	storeBatch := m.db.NewBatch()
 	storeBatch.Delete(aliasKey(account.Alias))
 	storeBatch.Delete(Key(account.ID))
+	storeBatch.Delete(policyKey(account.ID))
 	storeBatch.Write()
 	return nil
 }
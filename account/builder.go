@@ -1,6 +1,7 @@
 package account
 
 import (
+	"bytes"
 	"context"
 	stdjson "encoding/json"
 
@@ -8,8 +9,11 @@ import (
 	"github.com/bytom/bytom/blockchain/txbuilder"
 	"github.com/bytom/bytom/common"
 	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/contract"
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/database/storage"
 	"github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/equity/compiler"
 	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/protocol/bc"
 	"github.com/bytom/bytom/protocol/bc/types"
@@ -253,6 +257,7 @@ type spendUTXOAction struct {
 	OutputID       *bc.Hash                     `json:"output_id"`
 	UseUnconfirmed bool                         `json:"use_unconfirmed"`
 	Arguments      []txbuilder.ContractArgument `json:"arguments"`
+	Sequence       uint64                       `json:"sequence"`
 }
 
 func (a *spendUTXOAction) ActionType() string {
@@ -285,6 +290,8 @@ func (a *spendUTXOAction) Build(ctx context.Context, b *txbuilder.TemplateBuilde
 		return err
 	}
 
+	txInput.Sequence = a.Sequence
+
 	if a.Arguments == nil {
 		return b.AddInput(txInput, sigInst)
 	}
@@ -297,6 +304,177 @@ func (a *spendUTXOAction) Build(ctx context.Context, b *txbuilder.TemplateBuilde
 	return b.AddInput(txInput, sigInst)
 }
 
+// DecodeCallClauseAction returns the decoder of the action calling a clause
+// of a contract in the registry
+func (m *Manager) DecodeCallClauseAction(contracts *contract.Registry) func([]byte) (txbuilder.Action, error) {
+	return func(data []byte) (txbuilder.Action, error) {
+		a := &callClauseAction{accounts: m, contracts: contracts}
+		return a, stdjson.Unmarshal(data, a)
+	}
+}
+
+type callClauseAction struct {
+	accounts       *Manager
+	contracts      *contract.Registry
+	ContractID     json.HexBytes          `json:"contract_id"`
+	Clause         string                 `json:"clause"`
+	OutputID       *bc.Hash               `json:"output_id"`
+	Arguments      []compiler.ContractArg `json:"arguments"`
+	UseUnconfirmed bool                   `json:"use_unconfirmed"`
+	Sequence       uint64                 `json:"sequence"`
+}
+
+func (a *callClauseAction) ActionType() string {
+	return "call_contract_clause"
+}
+
+// Build spends the contract output with the witness arguments of the clause,
+// and adds the outputs the clause locks at the indexes it checks
+func (a *callClauseAction) Build(ctx context.Context, b *txbuilder.TemplateBuilder) error {
+	var missing []string
+	if len(a.ContractID) == 0 {
+		missing = append(missing, "contract_id")
+	}
+	if a.Clause == "" {
+		missing = append(missing, "clause")
+	}
+	if a.OutputID == nil {
+		missing = append(missing, "output_id")
+	}
+	if len(missing) > 0 {
+		return txbuilder.MissingFieldsError(missing...)
+	}
+
+	c, err := a.contracts.GetContract(a.ContractID)
+	if err != nil {
+		return err
+	}
+
+	if c.ABI == nil {
+		return errors.WithDetailf(contract.ErrContractABI, "contract %s is registered without abi", c.Alias)
+	}
+
+	clause, err := c.ABI.FindClause(a.Clause)
+	if err != nil {
+		return err
+	}
+
+	arguments, err := c.ABI.ClauseArguments(clause, a.Arguments)
+	if err != nil {
+		return err
+	}
+
+	u, err := a.accounts.findContractUtxo(*a.OutputID, a.UseUnconfirmed)
+	if err != nil {
+		return err
+	}
+
+	res, err := a.accounts.utxoKeeper.ReserveUtxo(u, b.MaxTime())
+	if err != nil {
+		return err
+	}
+
+	b.OnRollback(func() { a.accounts.utxoKeeper.Cancel(res.id) })
+	utxo := res.utxos[0]
+	if !bytes.Equal(utxo.ControlProgram, c.CallProgram) {
+		return errors.WithDetailf(contract.ErrContractABI, "output %s is not locked by contract %s", utxo.OutputID.String(), c.Alias)
+	}
+
+	values, err := c.ABI.LockedValues(clause, utxo.StateData, arguments, utxo.Amount, utxo.AssetID.Bytes())
+	if err != nil {
+		return err
+	}
+
+	txInput, sigInst, err := UtxoToInputs(nil, utxo)
+	if err != nil {
+		return err
+	}
+
+	txInput.Sequence = a.Sequence
+
+	for _, argument := range arguments {
+		sigInst.WitnessComponents = append(sigInst.WitnessComponents, txbuilder.DataWitness(argument))
+	}
+	if err := b.AddInput(txInput, sigInst); err != nil {
+		return err
+	}
+
+	for _, value := range values {
+		if uint64(len(b.Outputs())) != value.Index {
+			return errors.WithDetailf(contract.ErrContractABI, "clause %s checks output %d, the outputs of the previous actions take %d", clause.Name, value.Index, len(b.Outputs()))
+		}
+
+		if len(value.AssetID) != 32 {
+			return errors.WithDetailf(compiler.ErrContractArgs, "invalid asset %x locked by clause %s", value.AssetID, clause.Name)
+		}
+
+		var assetID [32]byte
+		copy(assetID[:], value.AssetID)
+		if err := b.AddOutput(types.NewOriginalTxOutput(bc.NewAssetID(assetID), value.Amount, value.Program, nil)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findContractUtxo load an unspent output from the chain utxo set, contract
+// outputs are not indexed by the wallet so they can't be found by utxoKeeper
+func (m *Manager) findContractUtxo(outputID bc.Hash, useUnconfirmed bool) (*UTXO, error) {
+	if entry, err := m.chain.GetUtxo(&outputID); err == nil {
+		if entry.Spent {
+			return nil, ErrMatchUTXO
+		}
+
+		block, err := m.chain.GetBlockByHeight(entry.BlockHeight)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range block.Transactions {
+			if u := txOutToUtxo(tx, outputID); u != nil {
+				if entry.Type == storage.CoinbaseUTXOType {
+					u.ValidHeight = entry.BlockHeight + consensus.CoinbasePendingBlockNumber
+				}
+				return u, nil
+			}
+		}
+		return nil, ErrMatchUTXO
+	}
+
+	if !useUnconfirmed {
+		return nil, ErrMatchUTXO
+	}
+
+	if tx, ok := m.chain.GetTxPool().GetUtxoTx(&outputID); ok {
+		if u := txOutToUtxo(tx, outputID); u != nil {
+			return u, nil
+		}
+	}
+	return nil, ErrMatchUTXO
+}
+
+func txOutToUtxo(tx *types.Tx, outputID bc.Hash) *UTXO {
+	e, ok := tx.Entries[outputID]
+	if !ok {
+		return nil
+	}
+
+	output, ok := e.(*bc.OriginalOutput)
+	if !ok {
+		return nil
+	}
+
+	return &UTXO{
+		OutputID:       outputID,
+		AssetID:        *output.Source.Value.AssetId,
+		Amount:         output.Source.Value.Amount,
+		ControlProgram: output.ControlProgram.Code,
+		StateData:      output.StateData,
+		SourceID:       *output.Source.Ref,
+		SourcePos:      output.Source.Position,
+	}
+}
+
 // UtxoToInputs convert an utxo to the txinput
 func UtxoToInputs(signer *signers.Signer, u *UTXO) (*types.TxInput, *txbuilder.SigningInstruction, error) {
 	txInput := types.NewSpendInput(nil, u.SourceID, u.AssetID, u.Amount, u.SourcePos, u.ControlProgram, u.StateData)
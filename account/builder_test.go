@@ -1,15 +1,29 @@
 package account
 
 import (
-	"encoding/json"
+	"bytes"
+	"context"
+	stdjson "encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/bytom/bytom/blockchain/signers"
 	"github.com/bytom/bytom/blockchain/txbuilder"
 	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/contract"
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/crypto/sha3pool"
+	"github.com/bytom/bytom/database"
+	"github.com/bytom/bytom/database/storage"
+	"github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/equity/compiler"
+	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/protocol/state"
+	"github.com/bytom/bytom/protocol/vm"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
 	"github.com/bytom/bytom/testutil"
 )
 
@@ -26,7 +40,7 @@ func TestReserveBtmUtxoChain(t *testing.T) {
 		}
 		utxos = append(utxos, utxo)
 
-		data, err := json.Marshal(utxo)
+		data, err := stdjson.Marshal(utxo)
 		if err != nil {
 			t.Fatal(err)
 		}
@@ -586,3 +600,210 @@ func TestCalcMergeGas(t *testing.T) {
 		}
 	}
 }
+
+func TestCallClauseAction(t *testing.T) {
+	m := mockAccountManager(t)
+	contracts := contract.NewRegistry(m.db)
+	abi, err := compiler.Compile(`
+contract TradeOffer(assetRequested: Asset, amountRequested: Amount, seller: Program) locks valueAmount of valueAsset {
+  clause trade() {
+    lock amountRequested of assetRequested with seller
+    unlock valueAmount of valueAsset
+  }
+  clause cancel(sig: Signature) {
+    unlock valueAmount of valueAsset
+  }
+}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var hash [32]byte
+	sha3pool.Sum256(hash[:], abi.Contract)
+	callProgram, err := vmutil.CallContractProgram(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := contracts.SaveContract(&contract.Contract{Hash: hash[:], Alias: "trade", Contract: abi.Contract, CallProgram: callProgram, ABI: abi}); err != nil {
+		t.Fatal(err)
+	}
+
+	requested, amount, seller := json.HexBytes(consensus.BTMAssetID.Bytes()), uint64(500), json.HexBytes{0x51}
+	stateData, err := abi.StateData([]compiler.ContractArg{{String: &requested}, {Integer: &amount}, {String: &seller}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var data [][]byte
+	for _, d := range stateData {
+		data = append(data, d)
+	}
+
+	input := types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 100, 0, []byte{0x51}, nil)
+	tx := types.NewTx(types.TxData{
+		Version: 1,
+		Inputs:  []*types.TxInput{input},
+		Outputs: []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 100, callProgram, data)},
+	})
+
+	genesis, err := m.chain.GetHeaderByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := &types.Block{
+		BlockHeader:  types.BlockHeader{Version: 1, Height: 1, PreviousBlockHash: genesis.Hash(), Timestamp: genesis.Timestamp + 1},
+		Transactions: []*types.Tx{tx},
+	}
+
+	store := database.NewStore(m.db)
+	if err := store.SaveBlock(block); err != nil {
+		t.Fatal(err)
+	}
+
+	outputID := *tx.ResultIds[0]
+	view := state.NewUtxoViewpoint()
+	view.Entries[outputID] = storage.NewUtxoEntry(storage.NormalUTXOType, block.Height, false)
+	if err := store.SaveChainStatus(&block.BlockHeader, []*types.BlockHeader{&block.BlockHeader}, view, state.NewContractViewpoint(), 0, &bc.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		action      string
+		outputs     int
+		outputID    *bc.Hash
+		wantArgs    []txbuilder.DataWitness
+		wantOutputs []*types.TxOutput
+		wantErr     error
+	}{
+		{
+			action:      `{"contract_id": "%x", "clause": "trade", "output_id": "%s"}`,
+			wantArgs:    []txbuilder.DataWitness{vm.Uint64Bytes(0)},
+			wantOutputs: []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 500, seller, nil)},
+		},
+		{
+			action:   `{"contract_id": "%x", "clause": "cancel", "output_id": "%s", "arguments": [{"string": "a1b2"}]}`,
+			wantArgs: []txbuilder.DataWitness{{0xa1, 0xb2}, vm.Uint64Bytes(1)},
+		},
+		{
+			action:  `{"contract_id": "%x", "clause": "cancel", "output_id": "%s", "arguments": [{"integer": 1}]}`,
+			wantErr: compiler.ErrContractArgs,
+		},
+		{
+			action:  `{"contract_id": "%x", "clause": "unknown", "output_id": "%s"}`,
+			wantErr: compiler.ErrContractArgs,
+		},
+		{
+			action:  `{"contract_id": "%x", "clause": "trade", "output_id": "%s"}`,
+			outputs: 1,
+			wantErr: contract.ErrContractABI,
+		},
+		{
+			action:   `{"contract_id": "%x", "clause": "trade", "output_id": "%s"}`,
+			outputID: &bc.Hash{V0: 2},
+			wantErr:  ErrMatchUTXO,
+		},
+	}
+
+	for i, c := range cases {
+		id := outputID
+		if c.outputID != nil {
+			id = *c.outputID
+		}
+
+		action, err := m.DecodeCallClauseAction(contracts)([]byte(fmt.Sprintf(c.action, hash, id.String())))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		b := txbuilder.NewBuilder(time.Now().Add(time.Minute))
+		for j := 0; j < c.outputs; j++ {
+			if err := b.AddOutput(types.NewOriginalTxOutput(*consensus.BTMAssetID, 1, []byte{0x51}, nil)); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		err = action.Build(context.Background(), b)
+		b.Rollback()
+		if errors.Root(err) != c.wantErr {
+			t.Fatalf("case %d: got error %v want %v", i, err, c.wantErr)
+		}
+		if c.wantErr != nil {
+			continue
+		}
+
+		tpl, _, err := b.Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		gotArgs := []txbuilder.DataWitness{}
+		for _, component := range tpl.SigningInstructions[0].WitnessComponents {
+			gotArgs = append(gotArgs, component.(txbuilder.DataWitness))
+		}
+		if !testutil.DeepEqual(gotArgs, c.wantArgs) {
+			t.Errorf("case %d: got arguments %x want %x", i, gotArgs, c.wantArgs)
+		}
+
+		if len(tpl.Transaction.Outputs) != len(c.wantOutputs) {
+			t.Fatalf("case %d: got %d outputs want %d", i, len(tpl.Transaction.Outputs), len(c.wantOutputs))
+		}
+		for j, output := range tpl.Transaction.Outputs {
+			if output.Amount != c.wantOutputs[j].Amount || *output.AssetId != *c.wantOutputs[j].AssetId || !bytes.Equal(output.ControlProgram, c.wantOutputs[j].ControlProgram) {
+				t.Errorf("case %d: got output %v want %v", i, output, c.wantOutputs[j])
+			}
+		}
+	}
+}
+
+func TestSpendUTXOAction(t *testing.T) {
+	m := mockAccountManager(t)
+	account := m.createTestAccount(t, "alice", nil)
+	cp, err := m.CreateAddress(account.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxo := &UTXO{
+		OutputID:            bc.Hash{V0: 1},
+		SourceID:            bc.Hash{V0: 2},
+		AssetID:             *consensus.BTMAssetID,
+		Amount:              100,
+		ControlProgram:      cp.ControlProgram,
+		AccountID:           account.ID,
+		Address:             cp.Address,
+		ControlProgramIndex: cp.KeyIndex,
+		Change:              cp.Change,
+	}
+	data, err := stdjson.Marshal(utxo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m.db.Set(StandardUTXOKey(utxo.OutputID), data)
+	action, err := m.DecodeSpendUTXOAction([]byte(fmt.Sprintf(`{"output_id": "%s"}`, utxo.OutputID.String())))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b := txbuilder.NewBuilder(time.Now().Add(time.Minute))
+	if err := action.Build(context.Background(), b); err != nil {
+		t.Fatal(err)
+	}
+
+	tpl, _, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	components := tpl.SigningInstructions[0].WitnessComponents
+	if len(components) != 2 {
+		t.Fatalf("got %d witness components want 2", len(components))
+	}
+
+	witness, ok := components[0].(*txbuilder.RawTxSigWitness)
+	if !ok || witness.Quorum != 1 || len(witness.Keys) != 1 || witness.Keys[0].XPub != testutil.TestXPub {
+		t.Fatalf("got witness component %+v, want the witness keys of the account", components[0])
+	}
+}
@@ -0,0 +1,212 @@
+package account
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bytom/bytom/blockchain/signers"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+// An account descriptor is a portable text form of the signer behind an
+// account. Every key expression carries its origin, which is the derive rule
+// and the account key index, followed by the hex encoded root xpub:
+//
+//	wpkh([bip44/1]<xpub>)#<checksum>
+//	wsh(multi(<quorum>,[bip44/1]<xpub1>,[bip44/1]<xpub2>,...))#<checksum>
+//
+// The checksum uses the same polymod construction as bitcoin output
+// descriptors so that typos and truncations are caught before import.
+const (
+	descriptorInputCharset    = "0123456789()[],'/*abcdefgh@:$%{}IJKLMNOPQRSTUVWXYZ&+-.;<=>?!^_|~ijklmnopqrstuvwxyzABCDEFGH`#\"\\ "
+	descriptorChecksumCharset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+	descriptorChecksumLength  = 8
+)
+
+var (
+	ErrDescriptorFormat   = errors.New("Invalid account descriptor format")
+	ErrDescriptorChecksum = errors.New("Invalid account descriptor checksum")
+)
+
+var deriveRuleNames = map[uint8]string{
+	signers.BIP0032: "bip32",
+	signers.BIP0044: "bip44",
+}
+
+// Descriptor describes the signer of an account
+type Descriptor struct {
+	XPubs      []chainkd.XPub
+	Quorum     int
+	KeyIndex   uint64
+	DeriveRule uint8
+}
+
+// NewDescriptor returns the descriptor of the given account
+func NewDescriptor(account *Account) *Descriptor {
+	return &Descriptor{
+		XPubs:      account.XPubs,
+		Quorum:     account.Quorum,
+		KeyIndex:   account.KeyIndex,
+		DeriveRule: account.DeriveRule,
+	}
+}
+
+// String encodes the descriptor with its checksum appended
+func (d *Descriptor) String() string {
+	rule := deriveRuleNames[d.DeriveRule]
+	keys := make([]string, len(d.XPubs))
+	for i, xpub := range d.XPubs {
+		keys[i] = fmt.Sprintf("[%s/%d]%s", rule, d.KeyIndex, xpub.String())
+	}
+
+	var desc string
+	if len(d.XPubs) == 1 {
+		desc = fmt.Sprintf("wpkh(%s)", keys[0])
+	} else {
+		desc = fmt.Sprintf("wsh(multi(%d,%s))", d.Quorum, strings.Join(keys, ","))
+	}
+	return desc + "#" + descriptorChecksum(desc)
+}
+
+// ParseDescriptor decodes the descriptor string and verifies its checksum
+func ParseDescriptor(str string) (*Descriptor, error) {
+	str = strings.TrimSpace(str)
+	pos := strings.LastIndex(str, "#")
+	if pos < 0 {
+		return nil, errors.WithDetail(ErrDescriptorChecksum, "missing checksum")
+	}
+
+	desc, checksum := str[:pos], str[pos+1:]
+	if expect := descriptorChecksum(desc); expect == "" || checksum != expect {
+		return nil, errors.WithDetailf(ErrDescriptorChecksum, "checksum %s mismatch", checksum)
+	}
+
+	d := &Descriptor{}
+	var keys []string
+	switch {
+	case strings.HasPrefix(desc, "wpkh(") && strings.HasSuffix(desc, ")"):
+		keys = []string{strings.TrimSuffix(strings.TrimPrefix(desc, "wpkh("), ")")}
+		d.Quorum = 1
+	case strings.HasPrefix(desc, "wsh(multi(") && strings.HasSuffix(desc, "))"):
+		args := strings.Split(strings.TrimSuffix(strings.TrimPrefix(desc, "wsh(multi("), "))"), ",")
+		if len(args) < 3 {
+			return nil, errors.WithDetail(ErrDescriptorFormat, "multi requires a quorum and at least two keys")
+		}
+
+		quorum, err := strconv.Atoi(args[0])
+		if err != nil {
+			return nil, errors.WithDetailf(ErrDescriptorFormat, "bad quorum %s", args[0])
+		}
+		keys, d.Quorum = args[1:], quorum
+	default:
+		return nil, errors.WithDetail(ErrDescriptorFormat, "unsupported script type")
+	}
+
+	for i, key := range keys {
+		rule, index, xpub, err := parseDescriptorKey(key)
+		if err != nil {
+			return nil, err
+		}
+
+		if i > 0 && (rule != d.DeriveRule || index != d.KeyIndex) {
+			return nil, errors.WithDetail(ErrDescriptorFormat, "all keys must share the same origin")
+		}
+		d.DeriveRule, d.KeyIndex = rule, index
+		d.XPubs = append(d.XPubs, xpub)
+	}
+	return d, nil
+}
+
+// Account creates a new account with the given alias from the descriptor
+func (d *Descriptor) Account(alias string) (*Account, error) {
+	return CreateAccount(d.XPubs, d.Quorum, alias, d.KeyIndex, d.DeriveRule)
+}
+
+// parseDescriptorKey decodes the key expression [rule/index]xpub
+func parseDescriptorKey(key string) (uint8, uint64, chainkd.XPub, error) {
+	var xpub chainkd.XPub
+	end := strings.Index(key, "]")
+	if !strings.HasPrefix(key, "[") || end < 0 {
+		return 0, 0, xpub, errors.WithDetailf(ErrDescriptorFormat, "key %s has no origin", key)
+	}
+
+	origin := strings.Split(key[1:end], "/")
+	if len(origin) != 2 {
+		return 0, 0, xpub, errors.WithDetailf(ErrDescriptorFormat, "bad key origin %s", key[1:end])
+	}
+
+	rule, ok := uint8(0), false
+	for r, name := range deriveRuleNames {
+		if name == origin[0] {
+			rule, ok = r, true
+		}
+	}
+	if !ok {
+		return 0, 0, xpub, errors.WithDetailf(ErrDeriveRule, "unknown derive rule %s", origin[0])
+	}
+
+	index, err := strconv.ParseUint(origin[1], 10, 64)
+	if err != nil {
+		return 0, 0, xpub, errors.WithDetailf(ErrDescriptorFormat, "bad key index %s", origin[1])
+	}
+
+	if err := xpub.UnmarshalText([]byte(key[end+1:])); err != nil {
+		return 0, 0, xpub, errors.WithDetailf(signers.ErrBadXPub, "key %s", key[end+1:])
+	}
+	return rule, index, xpub, nil
+}
+
+func descriptorPolyMod(c uint64, val int) uint64 {
+	c0 := c >> 35
+	c = ((c & 0x7ffffffff) << 5) ^ uint64(val)
+	if c0&1 != 0 {
+		c ^= 0xf5dee51989
+	}
+	if c0&2 != 0 {
+		c ^= 0xa9fdca3312
+	}
+	if c0&4 != 0 {
+		c ^= 0x1bab10e32d
+	}
+	if c0&8 != 0 {
+		c ^= 0x3706b1677a
+	}
+	if c0&16 != 0 {
+		c ^= 0x644d626ffd
+	}
+	return c
+}
+
+// descriptorChecksum returns the checksum of the descriptor, or an empty
+// string when the descriptor contains characters outside the input charset
+func descriptorChecksum(desc string) string {
+	c, cls, clsCount := uint64(1), 0, 0
+	for _, ch := range desc {
+		pos := strings.IndexRune(descriptorInputCharset, ch)
+		if pos < 0 {
+			return ""
+		}
+
+		c = descriptorPolyMod(c, pos&31)
+		cls = cls*3 + pos>>5
+		if clsCount++; clsCount == 3 {
+			c = descriptorPolyMod(c, cls)
+			cls, clsCount = 0, 0
+		}
+	}
+	if clsCount > 0 {
+		c = descriptorPolyMod(c, cls)
+	}
+	for i := 0; i < descriptorChecksumLength; i++ {
+		c = descriptorPolyMod(c, 0)
+	}
+	c ^= 1
+
+	checksum := make([]byte, descriptorChecksumLength)
+	for i := range checksum {
+		checksum[i] = descriptorChecksumCharset[(c>>(5*(7-uint(i))))&31]
+	}
+	return string(checksum)
+}
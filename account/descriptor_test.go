@@ -0,0 +1,116 @@
+package account
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/bytom/bytom/blockchain/signers"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/testutil"
+)
+
+func TestDescriptorChecksum(t *testing.T) {
+	// test vector from the bitcoin output descriptor documentation
+	if got := descriptorChecksum("raw(deadbeef)"); got != "89f8spxm" {
+		t.Fatalf("checksum got %s want 89f8spxm", got)
+	}
+
+	if got := descriptorChecksum("wpkh(é)"); got != "" {
+		t.Fatalf("checksum of invalid charset got %s want empty", got)
+	}
+}
+
+func TestDescriptorRoundTrip(t *testing.T) {
+	_, xpub1, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, xpub2, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []*Descriptor{
+		{
+			XPubs:      []chainkd.XPub{testutil.TestXPub},
+			Quorum:     1,
+			KeyIndex:   1,
+			DeriveRule: signers.BIP0044,
+		},
+		{
+			XPubs:      []chainkd.XPub{xpub1, xpub2},
+			Quorum:     2,
+			KeyIndex:   7,
+			DeriveRule: signers.BIP0032,
+		},
+	}
+
+	for i, c := range cases {
+		got, err := ParseDescriptor(c.String())
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+
+		if !reflect.DeepEqual(got, c) {
+			t.Errorf("case %d: got %v want %v", i, got, c)
+		}
+	}
+}
+
+func TestParseDescriptorError(t *testing.T) {
+	valid := (&Descriptor{XPubs: []chainkd.XPub{testutil.TestXPub}, Quorum: 1, KeyIndex: 1, DeriveRule: signers.BIP0044}).String()
+	withChecksum := func(desc string) string { return desc + "#" + descriptorChecksum(desc) }
+
+	cases := []struct {
+		desc string
+		err  error
+	}{
+		{desc: valid[:len(valid)-9], err: ErrDescriptorChecksum},
+		{desc: valid[:len(valid)-1] + "q", err: ErrDescriptorChecksum},
+		{desc: withChecksum("pkh([bip44/1]" + testutil.TestXPub.String() + ")"), err: ErrDescriptorFormat},
+		{desc: withChecksum("wpkh(" + testutil.TestXPub.String() + ")"), err: ErrDescriptorFormat},
+		{desc: withChecksum("wpkh([bip99/1]" + testutil.TestXPub.String() + ")"), err: ErrDeriveRule},
+		{desc: withChecksum("wpkh([bip44/1]00)"), err: signers.ErrBadXPub},
+		{desc: withChecksum("wsh(multi(1,[bip44/1]" + testutil.TestXPub.String() + "))"), err: ErrDescriptorFormat},
+	}
+
+	for i, c := range cases {
+		if _, err := ParseDescriptor(c.desc); errors.Root(err) != c.err {
+			t.Errorf("case %d: got error %v want %v", i, err, c.err)
+		}
+	}
+}
+
+func TestDescriptorAccount(t *testing.T) {
+	m := mockAccountManager(t)
+	account, err := m.Create([]chainkd.XPub{testutil.TestXPub}, 1, "test-descriptor", signers.BIP0044)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	d, err := ParseDescriptor(NewDescriptor(account).String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	imported, err := d.Account("imported")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := CreateCtrlProgram(account, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := CreateCtrlProgram(imported, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Address != want.Address {
+		t.Errorf("imported account address got %s want %s", got.Address, want.Address)
+	}
+}
@@ -0,0 +1,485 @@
+package account
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/bytom/bytom/blockchain/txbuilder"
+	"github.com/bytom/bytom/common"
+	"github.com/bytom/bytom/crypto/sha3pool"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+)
+
+const (
+	policyTimeLayout = "15:04"
+
+	// scrypt parameters of the policy passphrase hash
+	policyScryptN      = 1 << 15
+	policyScryptR      = 8
+	policyScryptP      = 1
+	policyScryptKeyLen = 32
+)
+
+var (
+	policyPrefix        = []byte("AccountPolicy:")
+	policySpendPrefix   = []byte("AccountPolicySpend:")
+	policyPassphraseKey = []byte("AccountPolicyPassphrase")
+)
+
+// pre-define policy errors for supporting bytom errorFormatter
+var (
+	ErrPolicyFormat     = errors.New("Invalid account policy")
+	ErrPolicyDailyLimit = errors.New("Account daily spend limit exceeded")
+	ErrPolicyAddress    = errors.New("Destination address is not allowed by account policy")
+	ErrPolicyFee        = errors.New("Transaction fee exceeds account policy limit")
+	ErrPolicyTimeWindow = errors.New("Spending is not allowed at this time by account policy")
+	ErrPolicyAuth       = errors.New("Policy passphrase is required to change account policy")
+)
+
+// TimeWindow is a UTC time of day range in "15:04" format, a window whose
+// start is later than its end wraps over midnight
+type TimeWindow struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// Policy restricts how the funds of an account can be spent, zero value
+// fields are not restricted
+type Policy struct {
+	AccountID        string            `json:"account_id"`
+	DailyLimits      map[string]uint64 `json:"daily_limits,omitempty"`
+	AllowedAddresses []string          `json:"allowed_addresses,omitempty"`
+	MaxFee           uint64            `json:"max_fee,omitempty"`
+	TimeWindows      []*TimeWindow     `json:"time_windows,omitempty"`
+}
+
+// policyPassphrase is the salted scrypt hash of the admin passphrase changing
+// the policies, it's independent of the account keys so whoever is able to
+// spend can't lift the policy
+type policyPassphrase struct {
+	Salt []byte `json:"salt"`
+	Hash []byte `json:"hash"`
+}
+
+// accountSpend is the value an account sends out of itself in a transaction
+type accountSpend struct {
+	amounts      map[string]uint64
+	destinations [][]byte
+}
+
+func policyKey(accountID string) []byte {
+	return append(policyPrefix, []byte(accountID)...)
+}
+
+func policySpendDayPrefix(accountID string, now time.Time) []byte {
+	return append(policySpendPrefix, []byte(fmt.Sprintf("%s:%s:", accountID, now.UTC().Format("20060102")))...)
+}
+
+func policySpendKey(accountID string, now time.Time, txID string) []byte {
+	return append(policySpendDayPrefix(accountID, now), []byte(txID)...)
+}
+
+func parseTimeOfDay(str string) (int, error) {
+	t, err := time.Parse(policyTimeLayout, str)
+	if err != nil {
+		return 0, errors.WithDetailf(ErrPolicyFormat, "invalid time of day %s", str)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (w *TimeWindow) contains(now time.Time) (bool, error) {
+	start, err := parseTimeOfDay(w.Start)
+	if err != nil {
+		return false, err
+	}
+
+	end, err := parseTimeOfDay(w.End)
+	if err != nil {
+		return false, err
+	}
+
+	now = now.UTC()
+	minute := now.Hour()*60 + now.Minute()
+	if start <= end {
+		return minute >= start && minute < end, nil
+	}
+	return minute >= start || minute < end, nil
+}
+
+func (p *Policy) validate() error {
+	for assetID := range p.DailyLimits {
+		if len(assetID) != 64 {
+			return errors.WithDetailf(ErrPolicyFormat, "invalid asset id %s", assetID)
+		}
+	}
+
+	for _, window := range p.TimeWindows {
+		if _, err := window.contains(time.Now()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hashPolicyPassphrase(passphrase string, salt []byte) ([]byte, error) {
+	return scrypt.Key([]byte(passphrase), salt, policyScryptN, policyScryptR, policyScryptP, policyScryptKeyLen)
+}
+
+// checkPolicyPassphrase returns ErrPolicyAuth unless the passphrase matches
+// the saved one, the policies can't be changed before a passphrase is set
+func (m *Manager) checkPolicyPassphrase(passphrase string) error {
+	rawPassphrase := m.db.Get(policyPassphraseKey)
+	if rawPassphrase == nil {
+		return errors.WithDetail(ErrPolicyAuth, "policy passphrase is not set")
+	}
+
+	saved := &policyPassphrase{}
+	if err := json.Unmarshal(rawPassphrase, saved); err != nil {
+		return err
+	}
+
+	hash, err := hashPolicyPassphrase(passphrase, saved.Salt)
+	if err != nil {
+		return err
+	}
+
+	if subtle.ConstantTimeCompare(hash, saved.Hash) != 1 {
+		return ErrPolicyAuth
+	}
+	return nil
+}
+
+// SetPolicyPassphrase sets the admin passphrase changing the policies, the
+// old passphrase is required once a passphrase is set
+func (m *Manager) SetPolicyPassphrase(oldPassphrase, passphrase string) error {
+	m.policyMu.Lock()
+	defer m.policyMu.Unlock()
+
+	if passphrase == "" {
+		return errors.WithDetail(ErrPolicyFormat, "empty policy passphrase")
+	}
+
+	if m.db.Get(policyPassphraseKey) != nil {
+		if err := m.checkPolicyPassphrase(oldPassphrase); err != nil {
+			return err
+		}
+	}
+
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return err
+	}
+
+	hash, err := hashPolicyPassphrase(passphrase, salt)
+	if err != nil {
+		return err
+	}
+
+	rawPassphrase, err := json.Marshal(&policyPassphrase{Salt: salt, Hash: hash})
+	if err != nil {
+		return err
+	}
+
+	m.db.Set(policyPassphraseKey, rawPassphrase)
+	return nil
+}
+
+// SetPolicy saves the spending policy of the account, replacing the old one,
+// it requires the policy passphrase
+func (m *Manager) SetPolicy(policy *Policy, passphrase string) error {
+	if _, err := m.FindByID(policy.AccountID); err != nil {
+		return err
+	}
+
+	if err := m.checkPolicyPassphrase(passphrase); err != nil {
+		return err
+	}
+
+	if err := policy.validate(); err != nil {
+		return err
+	}
+
+	for _, address := range policy.AllowedAddresses {
+		if _, err := m.getProgramByAddress(address); err != nil {
+			return errors.WithDetailf(ErrInvalidAddress, "allowed address %s", address)
+		}
+	}
+
+	rawPolicy, err := json.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	m.db.Set(policyKey(policy.AccountID), rawPolicy)
+	return nil
+}
+
+// GetPolicy returns the spending policy of the account, nil if the account
+// has no policy
+func (m *Manager) GetPolicy(accountID string) (*Policy, error) {
+	rawPolicy := m.db.Get(policyKey(accountID))
+	if rawPolicy == nil {
+		return nil, nil
+	}
+
+	policy := &Policy{}
+	return policy, json.Unmarshal(rawPolicy, policy)
+}
+
+// DeletePolicy removes the spending policy of the account, it requires the
+// policy passphrase
+func (m *Manager) DeletePolicy(accountID, passphrase string) error {
+	if _, err := m.FindByID(accountID); err != nil {
+		return err
+	}
+
+	if err := m.checkPolicyPassphrase(passphrase); err != nil {
+		return err
+	}
+
+	m.db.Delete(policyKey(accountID))
+	return nil
+}
+
+func (m *Manager) getLocalCtrlProgram(prog []byte) *CtrlProgram {
+	var hash common.Hash
+	sha3pool.Sum256(hash[:], prog)
+	rawProgram := m.db.Get(ContractKey(hash))
+	if rawProgram == nil {
+		return nil
+	}
+
+	cp := &CtrlProgram{}
+	if err := json.Unmarshal(rawProgram, cp); err != nil {
+		return nil
+	}
+	return cp
+}
+
+// txAccountSpends groups the value leaving each local account in the
+// transaction, outputs back to the same account are treated as change
+func (m *Manager) txAccountSpends(tx *types.Tx) map[string]*accountSpend {
+	spends := map[string]*accountSpend{}
+	for _, input := range tx.Inputs {
+		if _, ok := input.TypedInput.(*types.IssuanceInput); ok {
+			continue
+		}
+
+		cp := m.getLocalCtrlProgram(input.ControlProgram())
+		if cp == nil {
+			continue
+		}
+
+		if _, ok := spends[cp.AccountID]; !ok {
+			spends[cp.AccountID] = &accountSpend{amounts: map[string]uint64{}}
+		}
+		assetID := input.AssetID()
+		spends[cp.AccountID].amounts[assetID.String()] += input.Amount()
+	}
+
+	for accountID, spend := range spends {
+		for _, output := range tx.Outputs {
+			assetID := output.AssetId.String()
+			if cp := m.getLocalCtrlProgram(output.ControlProgram); cp != nil && cp.AccountID == accountID {
+				if spend.amounts[assetID] > output.Amount {
+					spend.amounts[assetID] -= output.Amount
+				} else {
+					spend.amounts[assetID] = 0
+				}
+				continue
+			}
+
+			spend.destinations = append(spend.destinations, output.ControlProgram)
+		}
+	}
+	return spends
+}
+
+// dailySpent sums the recorded spends of the account in the day of now,
+// the record of the excluded transaction is skipped
+func (m *Manager) dailySpent(accountID string, now time.Time, excludeTxID string) (map[string]uint64, error) {
+	spent := map[string]uint64{}
+	prefix := policySpendDayPrefix(accountID, now)
+	iter := m.db.IteratorPrefix(prefix)
+	defer iter.Release()
+
+	for iter.Next() {
+		if strings.TrimPrefix(string(iter.Key()), string(prefix)) == excludeTxID {
+			continue
+		}
+
+		amounts := map[string]uint64{}
+		if err := json.Unmarshal(iter.Value(), &amounts); err != nil {
+			return nil, err
+		}
+
+		for assetID, amount := range amounts {
+			spent[assetID] += amount
+		}
+	}
+	return spent, nil
+}
+
+func (m *Manager) checkPolicy(policy *Policy, tx *types.Tx, spend *accountSpend, now time.Time) error {
+	if len(policy.TimeWindows) != 0 {
+		allowed := false
+		for _, window := range policy.TimeWindows {
+			ok, err := window.contains(now)
+			if err != nil {
+				return err
+			}
+
+			allowed = allowed || ok
+		}
+
+		if !allowed {
+			return errors.WithDetailf(ErrPolicyTimeWindow, "account %s at %s", policy.AccountID, now.UTC().Format(policyTimeLayout))
+		}
+	}
+
+	if policy.MaxFee != 0 && tx.Fee() > policy.MaxFee {
+		return errors.WithDetailf(ErrPolicyFee, "fee %d is greater than %d", tx.Fee(), policy.MaxFee)
+	}
+
+	if len(policy.AllowedAddresses) != 0 {
+		allowed := map[string]bool{}
+		for _, address := range policy.AllowedAddresses {
+			program, err := m.getProgramByAddress(address)
+			if err != nil {
+				return err
+			}
+
+			allowed[string(program)] = true
+		}
+
+		for _, destination := range spend.destinations {
+			if !allowed[string(destination)] {
+				return errors.WithDetailf(ErrPolicyAddress, "control program %x", destination)
+			}
+		}
+	}
+
+	if len(policy.DailyLimits) == 0 {
+		return nil
+	}
+
+	spent, err := m.dailySpent(policy.AccountID, now, tx.ID.String())
+	if err != nil {
+		return err
+	}
+
+	for assetID, limit := range policy.DailyLimits {
+		if total := spent[assetID] + spend.amounts[assetID]; total > limit {
+			return errors.WithDetailf(ErrPolicyDailyLimit, "asset %s spend %d is greater than %d", assetID, total, limit)
+		}
+	}
+	return nil
+}
+
+// CheckPolicy verifies the transaction against the policies of every local
+// account it spends from
+func (m *Manager) CheckPolicy(tx *types.Tx, now time.Time) error {
+	for accountID, spend := range m.txAccountSpends(tx) {
+		policy, err := m.GetPolicy(accountID)
+		if err != nil {
+			return err
+		}
+
+		if policy == nil {
+			continue
+		}
+
+		if err := m.checkPolicy(policy, tx, spend, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CheckTemplatePolicy verifies the transaction of a freshly built template
+// against the account policies, the reserved UTXOs are released when the
+// template is rejected
+func (m *Manager) CheckTemplatePolicy(tpl *txbuilder.Template, now time.Time) error {
+	if tpl.Transaction == nil {
+		return nil
+	}
+
+	err := m.CheckPolicy(tpl.Transaction, now)
+	if err == nil {
+		return nil
+	}
+
+	outputIDs := []bc.Hash{}
+	for _, input := range tpl.Transaction.Inputs {
+		if outputID, err := input.SpentOutputID(); err == nil {
+			outputIDs = append(outputIDs, outputID)
+		}
+	}
+	m.utxoKeeper.CancelByOutputs(outputIDs)
+	return err
+}
+
+// SpendPolicy checks the transactions against the account policies and counts
+// them into the daily spends under one lock, a transaction is counted once it
+// passes the check whether or not it gets fully signed. Recording the same
+// transaction again overwrites the old record, none of the transactions is
+// counted when one of them is rejected.
+func (m *Manager) SpendPolicy(txs []*types.Tx, now time.Time) error {
+	m.policyMu.Lock()
+	defer m.policyMu.Unlock()
+
+	saved := map[string][]byte{}
+	for _, tx := range txs {
+		err := m.CheckPolicy(tx, now)
+		if err == nil {
+			err = m.recordPolicySpend(tx, now, saved)
+		}
+
+		if err != nil {
+			for key, value := range saved {
+				if value == nil {
+					m.db.Delete([]byte(key))
+				} else {
+					m.db.Set([]byte(key), value)
+				}
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// recordPolicySpend counts the transaction into the daily spend of the
+// accounts with a daily limit, the overwritten records are kept in saved
+func (m *Manager) recordPolicySpend(tx *types.Tx, now time.Time, saved map[string][]byte) error {
+	for accountID, spend := range m.txAccountSpends(tx) {
+		policy, err := m.GetPolicy(accountID)
+		if err != nil {
+			return err
+		}
+
+		if policy == nil || len(policy.DailyLimits) == 0 {
+			continue
+		}
+
+		rawSpend, err := json.Marshal(spend.amounts)
+		if err != nil {
+			return err
+		}
+
+		key := policySpendKey(accountID, now, tx.ID.String())
+		if _, ok := saved[string(key)]; !ok {
+			saved[string(key)] = m.db.Get(key)
+		}
+		m.db.Set(key, rawSpend)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package account
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+)
+
+func mockPolicyTx(sourceID byte, input uint64, from []byte, outputs map[string]uint64) *types.Tx {
+	txData := types.TxData{
+		Inputs: []*types.TxInput{types.NewSpendInput(nil, bc.NewHash([32]byte{sourceID}), *consensus.BTMAssetID, input, 0, from, nil)},
+	}
+	for program, amount := range outputs {
+		txData.Outputs = append(txData.Outputs, types.NewOriginalTxOutput(*consensus.BTMAssetID, amount, []byte(program), nil))
+	}
+	return types.NewTx(txData)
+}
+
+func TestAccountPolicy(t *testing.T) {
+	m := mockAccountManager(t)
+	acc1 := m.createTestAccount(t, "alice", nil)
+	acc2 := m.createTestAccount(t, "bob", nil)
+	acc3 := m.createTestAccount(t, "carol", nil)
+
+	var programs []*CtrlProgram
+	for _, acc := range []*Account{acc1, acc1, acc2, acc3} {
+		cp, err := m.CreateAddress(acc.ID, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		programs = append(programs, cp)
+	}
+	from, change, allowed, denied := string(programs[0].ControlProgram), string(programs[1].ControlProgram), string(programs[2].ControlProgram), string(programs[3].ControlProgram)
+
+	if err := m.SetPolicy(&Policy{AccountID: acc1.ID}, ""); errors.Root(err) != ErrPolicyAuth {
+		t.Fatalf("set policy without passphrase got error %v want %v", err, ErrPolicyAuth)
+	}
+
+	if err := m.SetPolicyPassphrase("", "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SetPolicyPassphrase("wrong", "other"); errors.Root(err) != ErrPolicyAuth {
+		t.Fatalf("change passphrase with wrong passphrase got error %v want %v", err, ErrPolicyAuth)
+	}
+
+	if err := m.SetPolicy(&Policy{AccountID: acc1.ID, TimeWindows: []*TimeWindow{{Start: "8:00", End: "25:00"}}}, "admin"); errors.Root(err) != ErrPolicyFormat {
+		t.Fatalf("set invalid policy got error %v want %v", err, ErrPolicyFormat)
+	}
+
+	policy := &Policy{
+		AccountID:        acc1.ID,
+		DailyLimits:      map[string]uint64{consensus.BTMAssetID.String(): 1000},
+		AllowedAddresses: []string{programs[2].Address},
+		MaxFee:           100,
+		TimeWindows:      []*TimeWindow{{Start: "08:00", End: "20:00"}, {Start: "22:00", End: "02:00"}},
+	}
+	if err := m.SetPolicy(policy, "wrong"); errors.Root(err) != ErrPolicyAuth {
+		t.Fatalf("set policy with wrong passphrase got error %v want %v", err, ErrPolicyAuth)
+	}
+
+	if err := m.SetPolicy(policy, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	noon := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	tx := mockPolicyTx(1, 700, []byte(from), map[string]uint64{allowed: 500, change: 150})
+	if err := m.SpendPolicy([]*types.Tx{tx}, noon); err != nil {
+		t.Fatal(err)
+	}
+
+	over := mockPolicyTx(7, 400, []byte(from), map[string]uint64{allowed: 300, change: 50})
+	if err := m.SpendPolicy([]*types.Tx{over, over}, noon); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.SpendPolicy([]*types.Tx{mockPolicyTx(8, 100, []byte(from), map[string]uint64{allowed: 40, change: 50}), mockPolicyTx(9, 200, []byte(from), map[string]uint64{allowed: 90, change: 100})}, noon); errors.Root(err) != ErrPolicyDailyLimit {
+		t.Fatalf("spend over daily limit got error %v want %v", err, ErrPolicyDailyLimit)
+	}
+
+	if spent, err := m.dailySpent(acc1.ID, noon, ""); err != nil || spent[consensus.BTMAssetID.String()] != 900 {
+		t.Fatalf("daily spent got %v, %v want 900", spent, err)
+	}
+
+	cases := []struct {
+		tx  *types.Tx
+		now time.Time
+		err error
+	}{
+		{tx: tx, now: noon},
+		{tx: mockPolicyTx(2, 700, []byte(from), map[string]uint64{allowed: 500, change: 150}), now: noon, err: ErrPolicyDailyLimit},
+		{tx: mockPolicyTx(2, 700, []byte(from), map[string]uint64{allowed: 500, change: 150}), now: noon.Add(24 * time.Hour)},
+		{tx: mockPolicyTx(3, 700, []byte(from), map[string]uint64{denied: 100, change: 550}), now: noon, err: ErrPolicyAddress},
+		{tx: mockPolicyTx(4, 700, []byte(from), map[string]uint64{allowed: 100, change: 400}), now: noon, err: ErrPolicyFee},
+		{tx: mockPolicyTx(5, 100, []byte(from), map[string]uint64{allowed: 50}), now: noon.Add(-9 * time.Hour), err: ErrPolicyTimeWindow},
+		{tx: mockPolicyTx(5, 100, []byte(from), map[string]uint64{allowed: 50}), now: noon.Add(11 * time.Hour)},
+		{tx: mockPolicyTx(6, 700, []byte(allowed), map[string]uint64{denied: 650}), now: noon.Add(-9 * time.Hour)},
+	}
+
+	for i, c := range cases {
+		if err := m.CheckPolicy(c.tx, c.now); errors.Root(err) != c.err {
+			t.Errorf("case %d: got error %v want %v", i, err, c.err)
+		}
+	}
+
+	if err := m.DeletePolicy(acc1.ID, "wrong"); errors.Root(err) != ErrPolicyAuth {
+		t.Fatalf("delete policy with wrong passphrase got error %v want %v", err, ErrPolicyAuth)
+	}
+
+	if err := m.DeletePolicy(acc1.ID, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.CheckPolicy(cases[1].tx, noon); err != nil {
+		t.Errorf("check without policy got error %v", err)
+	}
+}
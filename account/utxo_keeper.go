@@ -93,6 +93,18 @@ func (uk *utxoKeeper) Cancel(rid uint64) {
 	uk.mtx.Unlock()
 }
 
+// CancelByOutputs cancels the reservations holding any of the outputs
+func (uk *utxoKeeper) CancelByOutputs(outputIDs []bc.Hash) {
+	uk.mtx.Lock()
+	defer uk.mtx.Unlock()
+
+	for _, outputID := range outputIDs {
+		if rid, ok := uk.reserved[outputID]; ok {
+			uk.cancel(rid)
+		}
+	}
+}
+
 // ListUnconfirmed return all the unconfirmed utxos
 func (uk *utxoKeeper) ListUnconfirmed() []*UTXO {
 	uk.mtx.Lock()
@@ -159,6 +171,23 @@ func (uk *utxoKeeper) ReserveParticular(outHash bc.Hash, useUnconfirmed bool, ex
 		return nil, err
 	}
 
+	return uk.reserve(u, exp)
+}
+
+// ReserveUtxo reserve an output which is not indexed by the wallet, such as
+// an output locked by a contract
+func (uk *utxoKeeper) ReserveUtxo(u *UTXO, exp time.Time) (*reservation, error) {
+	uk.mtx.Lock()
+	defer uk.mtx.Unlock()
+
+	if _, ok := uk.reserved[u.OutputID]; ok {
+		return nil, ErrReserved
+	}
+
+	return uk.reserve(u, exp)
+}
+
+func (uk *utxoKeeper) reserve(u *UTXO, exp time.Time) (*reservation, error) {
 	if u.ValidHeight > uk.currentHeight() {
 		return nil, ErrImmature
 	}
@@ -13,6 +13,7 @@ import (
 	"github.com/bytom/bytom/consensus"
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
 	"github.com/bytom/bytom/protocol/vm/vmutil"
+	"github.com/bytom/bytom/wallet"
 )
 
 // POST /create-account
@@ -77,6 +78,65 @@ func (a *API) deleteAccount(ctx context.Context, filter struct {
 	return NewSuccessResponse(nil)
 }
 
+// POST /import-descriptor
+func (a *API) importDescriptor(ctx context.Context, ins struct {
+	Descriptor string `json:"descriptor"`
+	Alias      string `json:"alias"`
+}) Response {
+	descriptor, err := account.ParseDescriptor(ins.Descriptor)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	acc, err := descriptor.Account(ins.Alias)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := a.wallet.AccountMgr.SaveAccount(acc); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := a.wallet.RecoveryMgr.AddrResurrect([]*account.Account{acc}); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	a.wallet.RescanBlocks()
+	annotatedAccount := account.Annotated(acc)
+	log.WithField("account ID", annotatedAccount.ID).Info("Imported account from descriptor")
+
+	return NewSuccessResponse(annotatedAccount)
+}
+
+type exportDescriptorResp struct {
+	AccountID    string `json:"account_id"`
+	AccountAlias string `json:"account_alias"`
+	Descriptor   string `json:"descriptor"`
+}
+
+// POST /export-descriptor
+func (a *API) exportDescriptor(ctx context.Context, ins struct {
+	AccountID    string `json:"account_id"`
+	AccountAlias string `json:"account_alias"`
+}) Response {
+	var acc *account.Account
+	var err error
+	if ins.AccountAlias != "" {
+		acc, err = a.wallet.AccountMgr.FindByAlias(ins.AccountAlias)
+	} else {
+		acc, err = a.wallet.AccountMgr.FindByID(ins.AccountID)
+	}
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(&exportDescriptorResp{
+		AccountID:    acc.ID,
+		AccountAlias: acc.Alias,
+		Descriptor:   account.NewDescriptor(acc).String(),
+	})
+}
+
 type validateAddressResp struct {
 	Valid   bool `json:"valid"`
 	IsLocal bool `json:"is_local"`
@@ -115,12 +175,14 @@ func (a *API) validateAddress(ctx context.Context, ins struct {
 }
 
 type addressResp struct {
-	AccountAlias   string `json:"account_alias"`
-	AccountID      string `json:"account_id"`
-	Address        string `json:"address"`
-	ControlProgram string `json:"control_program"`
-	Change         bool   `json:"change"`
-	KeyIndex       uint64 `json:"key_index"`
+	AccountAlias   string   `json:"account_alias"`
+	AccountID      string   `json:"account_id"`
+	Address        string   `json:"address"`
+	ControlProgram string   `json:"control_program"`
+	Change         bool     `json:"change"`
+	KeyIndex       uint64   `json:"key_index"`
+	Labels         []string `json:"labels,omitempty"`
+	Note           string   `json:"note,omitempty"`
 }
 
 // SortByIndex implements sort.Interface for addressResp slices
@@ -133,6 +195,7 @@ func (a SortByIndex) Less(i, j int) bool { return a[i].KeyIndex < a[j].KeyIndex
 func (a *API) listAddresses(ctx context.Context, ins struct {
 	AccountID    string `json:"account_id"`
 	AccountAlias string `json:"account_alias"`
+	Label        string `json:"label"`
 	From         uint   `json:"from"`
 	Count        uint   `json:"count"`
 }) Response {
@@ -162,6 +225,15 @@ func (a *API) listAddresses(ctx context.Context, ins struct {
 		if cp.Address == "" || cp.AccountID != target.ID {
 			continue
 		}
+
+		label := a.wallet.GetLabel(wallet.LabelAddress, cp.Address)
+		if ins.Label != "" && (label == nil || !label.Match(ins.Label)) {
+			continue
+		}
+
+		if label == nil {
+			label = &wallet.Label{}
+		}
 		addresses = append(addresses, addressResp{
 			AccountAlias:   target.Alias,
 			AccountID:      cp.AccountID,
@@ -169,6 +241,8 @@ func (a *API) listAddresses(ctx context.Context, ins struct {
 			ControlProgram: hex.EncodeToString(cp.ControlProgram),
 			Change:         cp.Change,
 			KeyIndex:       cp.KeyIndex,
+			Labels:         label.Labels,
+			Note:           label.Note,
 		})
 	}
 
@@ -111,6 +111,7 @@ type API struct {
 	accessTokens    *accesstoken.CredentialStore
 	chain           *protocol.Chain
 	contractTracer  *contract.TraceService
+	bcrpIndex       *contract.BCRPIndex
 	server          *http.Server
 	handler         http.Handler
 	blockProposer   *blockproposer.BlockProposer
@@ -180,12 +181,13 @@ type NetSync interface {
 }
 
 // NewAPI create and initialize the API
-func NewAPI(sync NetSync, wallet *wallet.Wallet, blockProposer *blockproposer.BlockProposer, chain *protocol.Chain, traceService *contract.TraceService, config *cfg.Config, token *accesstoken.CredentialStore, dispatcher *event.Dispatcher, notificationMgr *websocket.WSNotificationManager) *API {
+func NewAPI(sync NetSync, wallet *wallet.Wallet, blockProposer *blockproposer.BlockProposer, chain *protocol.Chain, traceService *contract.TraceService, bcrpIndex *contract.BCRPIndex, config *cfg.Config, token *accesstoken.CredentialStore, dispatcher *event.Dispatcher, notificationMgr *websocket.WSNotificationManager) *API {
 	api := &API{
 		sync:            sync,
 		wallet:          wallet,
 		chain:           chain,
 		contractTracer:  traceService,
+		bcrpIndex:       bcrpIndex,
 		accessTokens:    token,
 		blockProposer:   blockProposer,
 		eventDispatcher: dispatcher,
@@ -211,6 +213,12 @@ func (a *API) buildHandler() {
 		m.Handle("/update-account-alias", jsonHandler(a.updateAccountAlias))
 		m.Handle("/list-accounts", jsonHandler(a.listAccounts))
 		m.Handle("/delete-account", jsonHandler(a.deleteAccount))
+		m.Handle("/import-descriptor", jsonHandler(a.importDescriptor))
+		m.Handle("/export-descriptor", jsonHandler(a.exportDescriptor))
+		m.Handle("/set-account-policy", jsonHandler(a.setAccountPolicy))
+		m.Handle("/get-account-policy", jsonHandler(a.getAccountPolicy))
+		m.Handle("/delete-account-policy", jsonHandler(a.deleteAccountPolicy))
+		m.Handle("/set-account-policy-passphrase", jsonHandler(a.setAccountPolicyPassphrase))
 
 		m.Handle("/create-account-receiver", jsonHandler(a.createAccountReceiver))
 		m.Handle("/list-addresses", jsonHandler(a.listAddresses))
@@ -231,7 +239,18 @@ func (a *API) buildHandler() {
 		m.Handle("/delete-key", jsonHandler(a.pseudohsmDeleteKey))
 		m.Handle("/reset-key-password", jsonHandler(a.pseudohsmResetPassword))
 		m.Handle("/check-key-password", jsonHandler(a.pseudohsmCheckPassword))
+		m.Handle("/unlock-key", jsonHandler(a.pseudohsmUnlockKey))
+		m.Handle("/lock-key", jsonHandler(a.pseudohsmLockKey))
+		m.Handle("/list-unlocked-keys", jsonHandler(a.pseudohsmListUnlockedKeys))
+		m.Handle("/export-key-shares", jsonHandler(a.pseudohsmExportKeyShares))
+		m.Handle("/import-key-shares", jsonHandler(a.pseudohsmImportKeyShares))
+		m.Handle("/check-key-kdfs", jsonHandler(a.pseudohsmCheckKeyKDFs))
+		m.Handle("/upgrade-keys", jsonHandler(a.pseudohsmUpgradeKeys))
+		m.Handle("/derive-xpub", jsonHandler(a.deriveXPub))
+		m.Handle("/sign-with-path", jsonHandler(a.signWithPath))
 		m.Handle("/sign-message", jsonHandler(a.signMessage))
+		m.Handle("/sign-message-proof", jsonHandler(a.signMessageProof))
+		m.Handle("/generate-reserve-proof", jsonHandler(a.generateReserveProof))
 
 		m.Handle("/build-transaction", jsonHandler(a.build))
 		m.Handle("/build-chain-transactions", jsonHandler(a.buildChainTxs))
@@ -245,12 +264,17 @@ func (a *API) buildHandler() {
 		m.Handle("/list-unspent-outputs", jsonHandler(a.listUnspentOutputs))
 		m.Handle("/list-account-votes", jsonHandler(a.listAccountVotes))
 
+		m.Handle("/set-label", jsonHandler(a.setLabel))
+		m.Handle("/delete-label", jsonHandler(a.deleteLabel))
+		m.Handle("/list-labels", jsonHandler(a.listLabels))
+
 		m.Handle("/decode-program", jsonHandler(a.decodeProgram))
 
 		m.Handle("/backup-wallet", jsonHandler(a.backupWalletImage))
 		m.Handle("/restore-wallet", jsonHandler(a.restoreWalletImage))
 		m.Handle("/rescan-wallet", jsonHandler(a.rescanWallet))
 		m.Handle("/wallet-info", jsonHandler(a.getWalletInfo))
+		m.Handle("/export-wallet-history", jsonHandler(a.exportWalletHistory))
 		m.Handle("/recovery-wallet", jsonHandler(a.recoveryFromRootXPubs))
 	} else {
 		log.Warn("Please enable wallet")
@@ -264,13 +288,18 @@ func (a *API) buildHandler() {
 	m.Handle("/delete-access-token", jsonHandler(a.deleteAccessToken))
 	m.Handle("/check-access-token", jsonHandler(a.checkAccessToken))
 
+	m.Handle("/compile-contract", jsonHandler(a.compileContract))
 	m.Handle("/create-contract", jsonHandler(a.createContract))
 	m.Handle("/update-contract-alias", jsonHandler(a.updateContractAlias))
 	m.Handle("/get-contract", jsonHandler(a.getContract))
 	m.Handle("/list-contracts", jsonHandler(a.listContracts))
+	m.Handle("/list-registered-contracts", jsonHandler(a.listRegisteredContracts))
+	m.Handle("/get-registered-contract", jsonHandler(a.getRegisteredContract))
 
 	m.Handle("/submit-transaction", jsonHandler(a.submit))
 	m.Handle("/submit-transactions", jsonHandler(a.submitTxs))
+	m.Handle("/simulate-transaction", jsonHandler(a.simulateTx))
+	m.Handle("/profile-transaction", jsonHandler(a.profileTx))
 	m.Handle("/estimate-transaction-gas", jsonHandler(a.estimateTxGas))
 	m.Handle("/estimate-chain-transaction-gas", jsonHandler(a.estimateChainTxGas))
 
@@ -288,6 +317,8 @@ func (a *API) buildHandler() {
 	m.Handle("/set-mining", jsonHandler(a.setMining))
 
 	m.Handle("/verify-message", jsonHandler(a.verifyMessage))
+	m.Handle("/verify-message-proof", jsonHandler(a.verifyMessageProof))
+	m.Handle("/verify-reserve-proof", jsonHandler(a.verifyReserveProof))
 
 	m.Handle("/gas-rate", jsonHandler(a.gasRate))
 	m.Handle("/net-info", jsonHandler(a.getNetInfo))
@@ -301,6 +332,7 @@ func (a *API) buildHandler() {
 	m.Handle("/get-vote-result", jsonHandler(a.getVoteResult))
 
 	m.Handle("/get-contract-instance", jsonHandler(a.getContractInstance))
+	m.Handle("/list-contract-instances", jsonHandler(a.listContractInstances))
 	m.Handle("/create-contract-instance", jsonHandler(a.createContractInstance))
 	m.Handle("/remove-contract-instance", jsonHandler(a.removeContractInstance))
 
@@ -27,6 +27,7 @@ type BlockTx struct {
 	Version   uint64                   `json:"version"`
 	Size      uint64                   `json:"size"`
 	TimeRange uint64                   `json:"time_range"`
+	LockTime  uint64                   `json:"lock_time,omitempty"`
 	Inputs    []*query.AnnotatedInput  `json:"inputs"`
 	Outputs   []*query.AnnotatedOutput `json:"outputs"`
 	MuxID     bc.Hash                  `json:"mux_id"`
@@ -92,6 +93,7 @@ func (a *API) getBlock(ins BlockReq) Response {
 			Version:   orig.Version,
 			Size:      orig.SerializedSize,
 			TimeRange: orig.TimeRange,
+			LockTime:  orig.LockTime,
 			Inputs:    []*query.AnnotatedInput{},
 			Outputs:   []*query.AnnotatedOutput{},
 		}
@@ -1,12 +1,14 @@
 package api
 
 import (
+	"bytes"
 	"context"
 	"strings"
 
 	"github.com/bytom/bytom/contract"
 	"github.com/bytom/bytom/crypto/sha3pool"
 	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/equity/compiler"
 	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/protocol/bc"
 	"github.com/bytom/bytom/protocol/vm/vmutil"
@@ -23,12 +25,30 @@ var (
 func (a *API) createContract(_ context.Context, ins struct {
 	Alias    string             `json:"alias"`
 	Contract chainjson.HexBytes `json:"contract"`
+	Source   string             `json:"source"`
 }) Response {
 	ins.Alias = strings.TrimSpace(ins.Alias)
 	if ins.Alias == "" {
 		return NewErrorResponse(ErrNullContractAlias)
 	}
 
+	// the abi is compiled from the equity source here rather than taken from
+	// the client, so the clauses always describe the registered contract
+	var abi *compiler.Contract
+	if ins.Source != "" {
+		var err error
+		if abi, err = compiler.Compile(ins.Source); err != nil {
+			return NewErrorResponse(err)
+		}
+
+		if ins.Contract == nil {
+			ins.Contract = abi.Contract
+		}
+		if !bytes.Equal(ins.Contract, abi.Contract) {
+			return NewErrorResponse(contract.ErrContractABI)
+		}
+	}
+
 	if ins.Contract == nil {
 		return NewErrorResponse(ErrNullContract)
 	}
@@ -52,6 +72,7 @@ func (a *API) createContract(_ context.Context, ins struct {
 		Contract:        ins.Contract,
 		CallProgram:     callProgram,
 		RegisterProgram: registerProgram,
+		ABI:             abi,
 	}
 	if err := a.wallet.ContractReg.SaveContract(c); err != nil {
 		return NewErrorResponse(err)
@@ -60,6 +81,40 @@ func (a *API) createContract(_ context.Context, ins struct {
 	return NewSuccessResponse(c)
 }
 
+// CompileContractResp is the response of compile-contract, the instance
+// program and the state data are only returned with the contract arguments
+type CompileContractResp struct {
+	*compiler.Contract
+	InstanceProgram chainjson.HexBytes   `json:"instance_program,omitempty"`
+	StateData       []chainjson.HexBytes `json:"state_data,omitempty"`
+}
+
+// POST /compile-contract
+func (a *API) compileContract(_ context.Context, ins struct {
+	Contract string                 `json:"contract"`
+	Args     []compiler.ContractArg `json:"args"`
+}) Response {
+	c, err := compiler.Compile(ins.Contract)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	resp := &CompileContractResp{Contract: c}
+	if ins.Args == nil {
+		return NewSuccessResponse(resp)
+	}
+
+	if resp.InstanceProgram, err = c.Instantiate(ins.Args); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if resp.StateData, err = c.StateData(ins.Args); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(resp)
+}
+
 // POST /update-contract-alias
 func (a *API) updateContractAlias(_ context.Context, ins struct {
 	ID    chainjson.HexBytes `json:"id"`
@@ -108,10 +163,25 @@ func (a *API) listContracts(_ context.Context) Response {
 }
 
 type ContractInstance struct {
-	UTXOs       []*contract.UTXO     `json:"utxos"`
-	TxHash      *bc.Hash             `json:"tx_hash"`
-	Status      contract.Status      `json:"status"`
-	Unconfirmed []*contract.TreeNode `json:"unconfirmed"`
+	TraceID        string                     `json:"trace_id"`
+	UTXOs          []*contract.UTXO           `json:"utxos"`
+	TxHash         *bc.Hash                   `json:"tx_hash"`
+	Status         contract.Status            `json:"status"`
+	TransferHeight uint64                     `json:"transfer_height"`
+	History        []*contract.TransferRecord `json:"history"`
+	Unconfirmed    []*contract.TreeNode       `json:"unconfirmed"`
+}
+
+func newContractInstance(instance *contract.Instance) *ContractInstance {
+	return &ContractInstance{
+		TraceID:        instance.TraceID,
+		UTXOs:          instance.UTXOs,
+		TxHash:         instance.TxHash,
+		Status:         instance.Status,
+		TransferHeight: instance.TransferHeight,
+		History:        instance.History,
+		Unconfirmed:    instance.Unconfirmed,
+	}
 }
 
 func (a *API) getContractInstance(_ context.Context, ins struct {
@@ -122,12 +192,35 @@ func (a *API) getContractInstance(_ context.Context, ins struct {
 		return NewErrorResponse(err)
 	}
 
-	return NewSuccessResponse(&ContractInstance{
-		UTXOs:       instance.UTXOs,
-		TxHash:      instance.TxHash,
-		Status:      instance.Status,
-		Unconfirmed: instance.Unconfirmed,
-	})
+	return NewSuccessResponse(newContractInstance(instance))
+}
+
+// POST /list-contract-instances
+func (a *API) listContractInstances(_ context.Context, filter struct {
+	Program     chainjson.HexBytes `json:"program"`
+	AssetID     *bc.AssetID        `json:"asset_id"`
+	Status      contract.Status    `json:"status"`
+	StartHeight uint64             `json:"start_height"`
+	EndHeight   uint64             `json:"end_height"`
+	From        uint               `json:"from"`
+	Count       uint               `json:"count"`
+}) Response {
+	instances, err := a.contractTracer.ListInstances(&contract.InstanceFilter{
+		Program:     filter.Program,
+		AssetID:     filter.AssetID,
+		Status:      filter.Status,
+		StartHeight: filter.StartHeight,
+		EndHeight:   filter.EndHeight,
+	}, filter.From, filter.Count)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	resp := []*ContractInstance{}
+	for _, instance := range instances {
+		resp = append(resp, newContractInstance(instance))
+	}
+	return NewSuccessResponse(resp)
 }
 
 func (a *API) createContractInstance(_ context.Context, ins struct {
@@ -155,3 +248,36 @@ func (a *API) removeContractInstance(_ context.Context, ins struct {
 
 	return NewSuccessResponse(nil)
 }
+
+// RegisteredContractResp is the registered contract with the outputs locked
+// under its call program
+type RegisteredContractResp struct {
+	*contract.RegisteredContract
+	UTXOs []*contract.LockedUTXO `json:"utxos"`
+}
+
+// POST /list-registered-contracts
+func (a *API) listRegisteredContracts(_ context.Context) Response {
+	contracts, err := a.bcrpIndex.ListContracts()
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(contracts)
+}
+
+// POST /get-registered-contract
+func (a *API) getRegisteredContract(_ context.Context, ins struct {
+	ID chainjson.HexBytes `json:"id"`
+}) Response {
+	if len(ins.ID) != 32 {
+		return NewErrorResponse(ErrNullContractID)
+	}
+
+	c, utxos, err := a.bcrpIndex.GetContract(ins.ID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(&RegisteredContractResp{RegisteredContract: c, UTXOs: utxos})
+}
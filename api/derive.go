@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom/bytom/encoding/json"
+)
+
+// DeriveXPubResp is response for derive xpub
+type DeriveXPubResp struct {
+	RootXPub    chainkd.XPub         `json:"root_xpub"`
+	Path        []chainjson.HexBytes `json:"path"`
+	DerivedXPub chainkd.XPub         `json:"derived_xpub"`
+	PublicKey   string               `json:"public_key"`
+}
+
+func convertPath(path []chainjson.HexBytes) [][]byte {
+	result := [][]byte{}
+	for _, p := range path {
+		result = append(result, p)
+	}
+	return result
+}
+
+// POST /derive-xpub
+func (a *API) deriveXPub(ctx context.Context, ins struct {
+	XPub chainkd.XPub         `json:"xpub"`
+	Path []chainjson.HexBytes `json:"path"`
+}) Response {
+	derivedXPub, err := a.wallet.PathSigner.DeriveXPub(ins.XPub, convertPath(ins.Path))
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(&DeriveXPubResp{
+		RootXPub:    ins.XPub,
+		Path:        ins.Path,
+		DerivedXPub: derivedXPub,
+		PublicKey:   hex.EncodeToString(derivedXPub.PublicKey()),
+	})
+}
+
+// POST /sign-with-path
+func (a *API) signWithPath(ctx context.Context, ins struct {
+	XPub     chainkd.XPub         `json:"xpub"`
+	Path     []chainjson.HexBytes `json:"path"`
+	Message  chainjson.HexBytes   `json:"message"`
+	Password string               `json:"password"`
+}) Response {
+	path := convertPath(ins.Path)
+	derivedXPub, err := a.wallet.PathSigner.DeriveXPub(ins.XPub, path)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	sig, err := a.wallet.PathSigner.XSign(ins.XPub, path, ins.Message, ins.Password)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(SignMsgResp{
+		Signature:   hex.EncodeToString(sig),
+		DerivedXPub: derivedXPub,
+	})
+}
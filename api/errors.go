@@ -5,16 +5,22 @@ import (
 
 	"github.com/bytom/bytom/account"
 	"github.com/bytom/bytom/asset"
+	"github.com/bytom/bytom/blockchain/memo"
+	"github.com/bytom/bytom/blockchain/message"
 	"github.com/bytom/bytom/blockchain/pseudohsm"
 	"github.com/bytom/bytom/blockchain/rpc"
+	"github.com/bytom/bytom/blockchain/signer"
 	"github.com/bytom/bytom/blockchain/signers"
 	"github.com/bytom/bytom/blockchain/txbuilder"
 	"github.com/bytom/bytom/contract"
+	"github.com/bytom/bytom/equity/compiler"
 	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/net/http/httperror"
 	"github.com/bytom/bytom/net/http/httpjson"
 	"github.com/bytom/bytom/protocol/validation"
 	"github.com/bytom/bytom/protocol/vm"
+	"github.com/bytom/bytom/wallet"
+	"github.com/bytom/bytom/wallet/mnemonic"
 )
 
 var (
@@ -46,14 +52,18 @@ var respErrFormatter = map[error]httperror.Info{
 	ErrDefault: {500, "BTM000", "Bytom API Error"},
 
 	// Signers error namespace (2xx)
-	signers.ErrBadQuorum: {400, "BTM200", "Quorum must be greater than or equal to 1, and must be less than or equal to the length of xpubs"},
-	signers.ErrBadXPub:   {400, "BTM201", "Invalid xpub format"},
-	signers.ErrNoXPubs:   {400, "BTM202", "At least one xpub is required"},
-	signers.ErrDupeXPub:  {400, "BTM203", "Root XPubs cannot contain the same key more than once"},
+	signers.ErrBadQuorum:          {400, "BTM200", "Quorum must be greater than or equal to 1, and must be less than or equal to the length of xpubs"},
+	signers.ErrBadXPub:            {400, "BTM201", "Invalid xpub format"},
+	signers.ErrNoXPubs:            {400, "BTM202", "At least one xpub is required"},
+	signers.ErrDupeXPub:           {400, "BTM203", "Root XPubs cannot contain the same key more than once"},
+	account.ErrDescriptorFormat:   {400, "BTM204", "Invalid account descriptor format"},
+	account.ErrDescriptorChecksum: {400, "BTM205", "Invalid account descriptor checksum"},
 
 	// Contract error namespace (3xx)
-	contract.ErrContractDuplicated: {400, "BTM302", "Contract is duplicated"},
-	contract.ErrContractNotFound:   {400, "BTM303", "Contract not found"},
+	contract.ErrContractDuplicated:         {400, "BTM302", "Contract is duplicated"},
+	contract.ErrContractNotFound:           {400, "BTM303", "Contract not found"},
+	contract.ErrContractABI:                {400, "BTM304", "Contract abi mismatched"},
+	contract.ErrRegisteredContractNotFound: {400, "BTM305", "Registered contract not found"},
 
 	// Transaction error namespace (7xx)
 	// Build transaction error namespace (70x ~ 72x)
@@ -72,6 +82,12 @@ var respErrFormatter = map[error]httperror.Info{
 	txbuilder.ErrOrphanTx:           {400, "BTM712", "Transaction input UTXO not found"},
 	txbuilder.ErrExtTxFee:           {400, "BTM713", "Transaction fee exceeded max limit"},
 	txbuilder.ErrNoGasInput:         {400, "BTM714", "Transaction has no gas input"},
+	account.ErrPolicyFormat:         {400, "BTM715", "Invalid account policy"},
+	account.ErrPolicyDailyLimit:     {400, "BTM716", "Account daily spend limit exceeded"},
+	account.ErrPolicyAddress:        {400, "BTM717", "Destination address is not allowed by account policy"},
+	account.ErrPolicyFee:            {400, "BTM718", "Transaction fee exceeds account policy limit"},
+	account.ErrPolicyTimeWindow:     {400, "BTM719", "Spending is not allowed at this time by account policy"},
+	account.ErrPolicyAuth:           {400, "BTM720", "Policy passphrase is required to change account policy"},
 
 	// Submit transaction error namespace (73x ~ 79x)
 	// Validation error (73x ~ 75x)
@@ -94,6 +110,9 @@ var respErrFormatter = map[error]httperror.Info{
 	validation.ErrUnbalanced:                {400, "BTM746", "Unbalanced asset amount between input and output"},
 	validation.ErrOverGasCredit:             {400, "BTM747", "Gas credit has been spent"},
 	validation.ErrGasCalculate:              {400, "BTM748", "Gas usage calculate got a math error"},
+	validation.ErrVMVersion:                 {400, "BTM749", "VM version is not active at the block height"},
+	validation.ErrTxNotFinal:                {400, "BTM750", "Transaction lock time is not reached"},
+	validation.ErrLockTimeNotActive:         {400, "BTM751", "Transaction lock time is not active at the block height"},
 
 	// VM error (76x ~ 78x)
 	vm.ErrAltStackUnderflow:  {400, "BTM760", "Alt stack underflow"},
@@ -117,6 +136,29 @@ var respErrFormatter = map[error]httperror.Info{
 	pseudohsm.ErrDuplicateKeyAlias: {400, "BTM800", "Key Alias already exists"},
 	pseudohsm.ErrLoadKey:           {400, "BTM801", "Key not found or wrong password"},
 	pseudohsm.ErrDecrypt:           {400, "BTM802", "Could not decrypt key with given passphrase"},
+	signer.ErrKeyNotFound:          {400, "BTM803", "Key not found in signer"},
+	signer.ErrUnauthorized:         {400, "BTM804", "Remote signer rejected the credential"},
+	signer.ErrRemote:               {400, "BTM805", "Remote signer failed"},
+	signer.ErrKeyNotAllowed:        {400, "BTM806", "Key is not allowed to sign by signer policy"},
+	signer.ErrPathNotAllowed:       {400, "BTM807", "Derivation path is not allowed by signer policy"},
+	signer.ErrRateLimit:            {400, "BTM808", "Signing rate limit exceeded"},
+	pseudohsm.ErrKeyLocked:         {400, "BTM809", "Key is locked"},
+	pseudohsm.ErrUnlockScope:       {400, "BTM810", "Invalid unlock scope"},
+	pseudohsm.ErrUnlockTimeout:     {400, "BTM811", "Invalid unlock timeout"},
+	pseudohsm.ErrScopeDenied:       {400, "BTM812", "Unlocked key is not allowed for this operation"},
+	pseudohsm.ErrShareThreshold:    {400, "BTM813", "Share threshold must be at least 2 and not greater than share count"},
+	pseudohsm.ErrShareMismatch:     {400, "BTM814", "Shares do not belong to the same share set"},
+	pseudohsm.ErrShareNotEnough:    {400, "BTM815", "Not enough shares to restore the key"},
+	pseudohsm.ErrDuplicateKey:      {400, "BTM816", "Key already exists"},
+	mnemonic.ErrShareChecksum:      {400, "BTM817", "Share checksum incorrect"},
+	mnemonic.ErrShareLength:        {400, "BTM818", "Share mnemonic length error"},
+	pseudohsm.ErrUnsupportedKDF:    {400, "BTM819", "Unsupported key derivation function"},
+	message.ErrQuorum:              {400, "BTM820", "Not enough signatures for the signed message"},
+	message.ErrUTXO:                {400, "BTM821", "UTXO is not controlled by the signed message address"},
+	wallet.ErrReserveProof:         {400, "BTM822", "Invalid reserve proof"},
+	memo.ErrSize:                   {400, "BTM823", "Memo is too long"},
+	compiler.ErrCompile:            {400, "BTM825", "Equity contract compile error"},
+	compiler.ErrContractArgs:       {400, "BTM826", "Invalid contract arguments"},
 }
 
 // Map error values to standard bytom error codes. Missing entries
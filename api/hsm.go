@@ -2,11 +2,15 @@ package api
 
 import (
 	"context"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/bytom/bytom/blockchain/pseudohsm"
 	"github.com/bytom/bytom/blockchain/txbuilder"
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc/types"
 )
 
 type createKeyResp struct {
@@ -50,7 +54,11 @@ func (a *API) pseudohsmUpdateKeyAlias(ctx context.Context, in struct {
 }
 
 func (a *API) pseudohsmListKeys(ctx context.Context) Response {
-	return NewSuccessResponse(a.wallet.Hsm.ListKeys())
+	keys, err := a.wallet.Signer.ListKeys()
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(keys)
 }
 
 func (a *API) pseudohsmDeleteKey(ctx context.Context, x struct {
@@ -72,12 +80,18 @@ func (a *API) signTemplate(ctx context.Context, x struct {
 	Password string             `json:"password"`
 	Txs      txbuilder.Template `json:"transaction"`
 }) Response {
+	if err := a.spendSignPolicy([]*txbuilder.Template{&x.Txs}); err != nil {
+		return NewErrorResponse(err)
+	}
+
 	if err := txbuilder.Sign(ctx, &x.Txs, x.Password, a.pseudohsmSignTemplate); err != nil {
 		log.WithField("build err", err).Error("fail on sign transaction.")
 		return NewErrorResponse(err)
 	}
+
+	signComplete := txbuilder.SignProgress(&x.Txs)
 	log.Info("Sign Transaction complete.")
-	return NewSuccessResponse(&signTemplateResp{Tx: &x.Txs, SignComplete: txbuilder.SignProgress(&x.Txs)})
+	return NewSuccessResponse(&signTemplateResp{Tx: &x.Txs, SignComplete: signComplete})
 }
 
 type signTemplatesResp struct {
@@ -89,6 +103,10 @@ func (a *API) signTemplates(ctx context.Context, x struct {
 	Password string                `json:"password"`
 	Txs      []*txbuilder.Template `json:"transactions"`
 }) Response {
+	if err := a.spendSignPolicy(x.Txs); err != nil {
+		return NewErrorResponse(err)
+	}
+
 	signComplete := true
 	for _, tx := range x.Txs {
 		if err := txbuilder.Sign(ctx, tx, x.Password, a.pseudohsmSignTemplate); err != nil {
@@ -102,8 +120,129 @@ func (a *API) signTemplates(ctx context.Context, x struct {
 	return NewSuccessResponse(&signTemplatesResp{Tx: x.Txs, SignComplete: signComplete})
 }
 
+// spendSignPolicy rejects signing the templates violating the account
+// policies, the passed templates are counted into the daily spends before
+// signing so a partially signed template still takes its quota
+func (a *API) spendSignPolicy(tpls []*txbuilder.Template) error {
+	txs := []*types.Tx{}
+	for _, tpl := range tpls {
+		if tpl.Transaction != nil {
+			txs = append(txs, tpl.Transaction)
+		}
+	}
+	return a.wallet.AccountMgr.SpendPolicy(txs, time.Now())
+}
+
 func (a *API) pseudohsmSignTemplate(ctx context.Context, xpub chainkd.XPub, path [][]byte, data [32]byte, password string) ([]byte, error) {
-	return a.wallet.Hsm.XSign(xpub, path, data[:], password)
+	return a.signWithSession(xpub, path, data[:], password, pseudohsm.ScopeTransaction)
+}
+
+// signWithSession signs by the unlocked key when no password is given and the
+// key is unlocked, otherwise by the signer with the password
+func (a *API) signWithSession(xpub chainkd.XPub, path [][]byte, msg []byte, password, scope string) ([]byte, error) {
+	if password == "" {
+		sig, err := a.wallet.Hsm.XSignUnlocked(xpub, path, msg, scope)
+		if errors.Root(err) != pseudohsm.ErrKeyLocked {
+			return sig, err
+		}
+	}
+	return a.wallet.Signer.XSign(xpub, path, msg, password)
+}
+
+type unlockKeyResp struct {
+	XPub   chainkd.XPub `json:"xpub"`
+	Expiry time.Time    `json:"expiry"`
+}
+
+// POST /unlock-key
+func (a *API) pseudohsmUnlockKey(ctx context.Context, ins struct {
+	XPub     chainkd.XPub `json:"xpub"`
+	Password string       `json:"password"`
+	Timeout  uint64       `json:"timeout"`
+	Scopes   []string     `json:"scopes"`
+}) Response {
+	if len(ins.Scopes) == 0 {
+		ins.Scopes = []string{pseudohsm.ScopeTransaction}
+	}
+
+	expiry, err := a.wallet.Hsm.Unlock(ins.XPub, ins.Password, time.Duration(ins.Timeout)*time.Second, ins.Scopes)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(&unlockKeyResp{XPub: ins.XPub, Expiry: expiry})
+}
+
+// POST /lock-key
+func (a *API) pseudohsmLockKey(ctx context.Context, ins struct {
+	XPub chainkd.XPub `json:"xpub"`
+}) Response {
+	if err := a.wallet.Hsm.Lock(ins.XPub); err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(nil)
+}
+
+// POST /list-unlocked-keys
+func (a *API) pseudohsmListUnlockedKeys(ctx context.Context) Response {
+	return NewSuccessResponse(a.wallet.Hsm.ListUnlockedKeys())
+}
+
+// POST /export-key-shares
+func (a *API) pseudohsmExportKeyShares(ctx context.Context, ins struct {
+	XPub      chainkd.XPub `json:"xpub"`
+	Password  string       `json:"password"`
+	Threshold int          `json:"threshold"`
+	Count     int          `json:"count"`
+	Language  string       `json:"language"`
+}) Response {
+	if ins.Language == "" {
+		ins.Language = "en"
+	}
+
+	shares, err := a.wallet.Hsm.ExportKeyShares(ins.XPub, ins.Password, ins.Threshold, ins.Count, ins.Language)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(map[string][]string{"shares": shares})
+}
+
+// POST /import-key-shares
+func (a *API) pseudohsmImportKeyShares(ctx context.Context, ins struct {
+	Alias    string   `json:"alias"`
+	Password string   `json:"password"`
+	Shares   []string `json:"shares"`
+	Language string   `json:"language"`
+}) Response {
+	if ins.Language == "" {
+		ins.Language = "en"
+	}
+
+	xpub, err := a.wallet.Hsm.ImportKeyFromShares(ins.Alias, ins.Password, ins.Shares, ins.Language)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(&createKeyResp{Alias: xpub.Alias, XPub: xpub.XPub, File: xpub.File})
+}
+
+// POST /check-key-kdfs
+func (a *API) pseudohsmCheckKeyKDFs(ctx context.Context) Response {
+	kdfs, err := a.wallet.Hsm.CheckKeyKDFs()
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(kdfs)
+}
+
+// POST /upgrade-keys
+func (a *API) pseudohsmUpgradeKeys(ctx context.Context, ins struct {
+	Password string `json:"password"`
+	KDF      string `json:"kdf"`
+}) Response {
+	upgrades, err := a.wallet.Hsm.UpgradeKeys(ins.Password, ins.KDF)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(upgrades)
 }
 
 // ResetPasswordResp is response for reset key password
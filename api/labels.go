@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+)
+
+// POST /set-label
+func (a *API) setLabel(ctx context.Context, ins struct {
+	Target string   `json:"target"`
+	ID     string   `json:"id"`
+	Labels []string `json:"labels"`
+	Note   string   `json:"note"`
+}) Response {
+	label, err := a.wallet.SetLabel(ins.Target, ins.ID, ins.Labels, ins.Note)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(label)
+}
+
+// POST /delete-label
+func (a *API) deleteLabel(ctx context.Context, ins struct {
+	Target string `json:"target"`
+	ID     string `json:"id"`
+}) Response {
+	if err := a.wallet.DeleteLabel(ins.Target, ins.ID); err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(nil)
+}
+
+// POST /list-labels
+func (a *API) listLabels(ctx context.Context, filter struct {
+	Target  string `json:"target"`
+	Keyword string `json:"keyword"`
+	From    uint   `json:"from"`
+	Count   uint   `json:"count"`
+}) Response {
+	labels, err := a.wallet.ListLabels(filter.Target, filter.Keyword)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	start, end := getPageRange(len(labels), filter.From, filter.Count)
+	return NewSuccessResponse(labels[start:end])
+}
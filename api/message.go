@@ -6,12 +6,16 @@ import (
 	"encoding/hex"
 	"strings"
 
+	"github.com/bytom/bytom/blockchain/message"
+	"github.com/bytom/bytom/blockchain/pseudohsm"
 	"github.com/bytom/bytom/blockchain/signers"
 	"github.com/bytom/bytom/common"
 	"github.com/bytom/bytom/consensus"
 	"github.com/bytom/bytom/crypto"
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
 	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/wallet"
 )
 
 // SignMsgResp is response for sign message
@@ -41,7 +45,7 @@ func (a *API) signMessage(ctx context.Context, ins struct {
 	}
 	derivedXPubs := chainkd.DeriveXPubs(account.XPubs, path)
 
-	sig, err := a.wallet.Hsm.XSign(account.XPubs[0], path, ins.Message, ins.Password)
+	sig, err := a.signWithSession(account.XPubs[0], path, message.LegacyDigest(ins.Message), ins.Password, pseudohsm.ScopeMessage)
 	if err != nil {
 		return NewErrorResponse(err)
 	}
@@ -79,8 +83,78 @@ func (a *API) verifyMessage(ctx context.Context, ins struct {
 		return NewSuccessResponse(VerifyMsgResp{VerifyResult: false})
 	}
 
-	if ed25519.Verify(ins.DerivedXPub.PublicKey(), ins.Message, sig) {
+	if ed25519.Verify(ins.DerivedXPub.PublicKey(), message.LegacyDigest(ins.Message), sig) {
 		return NewSuccessResponse(VerifyMsgResp{VerifyResult: true})
 	}
 	return NewSuccessResponse(VerifyMsgResp{VerifyResult: false})
 }
+
+// signMessageProof signs the versioned message with every account key held
+// by the signer, the utxos make it a proof-of-reserves statement
+func (a *API) signMessageProof(ctx context.Context, ins struct {
+	Address  string             `json:"address"`
+	Message  chainjson.HexBytes `json:"message"`
+	UTXOs    []bc.Hash          `json:"utxos"`
+	Password string             `json:"password"`
+}) Response {
+	signed, err := a.wallet.SignMessage(ins.Address, ins.Message, ins.UTXOs, a.messageSignFunc(ins.Password))
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(signed)
+}
+
+func (a *API) messageSignFunc(password string) wallet.SignFunc {
+	return func(xpub chainkd.XPub, path [][]byte, msg []byte) ([]byte, error) {
+		return a.signWithSession(xpub, path, msg, password, pseudohsm.ScopeMessage)
+	}
+}
+
+// VerifyMessageProofResp is response for verify message proof
+type VerifyMessageProofResp struct {
+	VerifyResult bool   `json:"result"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// verifyMessageProof checks the versioned signed message against the network
+// of the node, it needs no wallet
+func (a *API) verifyMessageProof(ctx context.Context, ins message.SignedMessage) Response {
+	if err := message.Verify(&ins, &consensus.ActiveNetParams); err != nil {
+		return NewSuccessResponse(VerifyMessageProofResp{VerifyResult: false, Reason: err.Error()})
+	}
+	return NewSuccessResponse(VerifyMessageProofResp{VerifyResult: true})
+}
+
+// generateReserveProof proves the outputs of the accounts at the block
+// height, every address signs the snapshot block hash over its outputs
+func (a *API) generateReserveProof(ctx context.Context, ins struct {
+	AccountIDs     []string `json:"account_ids"`
+	AccountAliases []string `json:"account_aliases"`
+	BlockHeight    uint64   `json:"block_height"`
+	Password       string   `json:"password"`
+}) Response {
+	accountIDs := ins.AccountIDs
+	for _, alias := range ins.AccountAliases {
+		acc, err := a.wallet.AccountMgr.FindByAlias(alias)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		accountIDs = append(accountIDs, acc.ID)
+	}
+
+	proof, err := a.wallet.GenerateReserveProof(accountIDs, ins.BlockHeight, a.messageSignFunc(ins.Password))
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(proof)
+}
+
+// verifyReserveProof checks the reserve proof against the main chain of the
+// node and reports the proved totals
+func (a *API) verifyReserveProof(ctx context.Context, ins wallet.ReserveProof) Response {
+	report, err := wallet.VerifyReserveProof(a.chain, &ins)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(report)
+}
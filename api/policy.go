@@ -0,0 +1,92 @@
+package api
+
+import (
+	"context"
+
+	"github.com/bytom/bytom/account"
+)
+
+func (a *API) findPolicyAccountID(accountID, accountAlias string) (string, error) {
+	if accountAlias == "" {
+		return accountID, nil
+	}
+
+	acc, err := a.wallet.AccountMgr.FindByAlias(accountAlias)
+	if err != nil {
+		return "", err
+	}
+	return acc.ID, nil
+}
+
+// POST /set-account-policy
+func (a *API) setAccountPolicy(ctx context.Context, ins struct {
+	AccountID        string                `json:"account_id"`
+	AccountAlias     string                `json:"account_alias"`
+	DailyLimits      map[string]uint64     `json:"daily_limits"`
+	AllowedAddresses []string              `json:"allowed_addresses"`
+	MaxFee           uint64                `json:"max_fee"`
+	TimeWindows      []*account.TimeWindow `json:"time_windows"`
+	Passphrase       string                `json:"passphrase"`
+}) Response {
+	accountID, err := a.findPolicyAccountID(ins.AccountID, ins.AccountAlias)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	policy := &account.Policy{
+		AccountID:        accountID,
+		DailyLimits:      ins.DailyLimits,
+		AllowedAddresses: ins.AllowedAddresses,
+		MaxFee:           ins.MaxFee,
+		TimeWindows:      ins.TimeWindows,
+	}
+	if err := a.wallet.AccountMgr.SetPolicy(policy, ins.Passphrase); err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(policy)
+}
+
+// POST /get-account-policy
+func (a *API) getAccountPolicy(ctx context.Context, ins struct {
+	AccountID    string `json:"account_id"`
+	AccountAlias string `json:"account_alias"`
+}) Response {
+	accountID, err := a.findPolicyAccountID(ins.AccountID, ins.AccountAlias)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	policy, err := a.wallet.AccountMgr.GetPolicy(accountID)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(policy)
+}
+
+// POST /delete-account-policy
+func (a *API) deleteAccountPolicy(ctx context.Context, ins struct {
+	AccountID    string `json:"account_id"`
+	AccountAlias string `json:"account_alias"`
+	Passphrase   string `json:"passphrase"`
+}) Response {
+	accountID, err := a.findPolicyAccountID(ins.AccountID, ins.AccountAlias)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	if err := a.wallet.AccountMgr.DeletePolicy(accountID, ins.Passphrase); err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(nil)
+}
+
+// POST /set-account-policy-passphrase
+func (a *API) setAccountPolicyPassphrase(ctx context.Context, ins struct {
+	OldPassphrase string `json:"old_passphrase"`
+	Passphrase    string `json:"passphrase"`
+}) Response {
+	if err := a.wallet.AccountMgr.SetPolicyPassphrase(ins.OldPassphrase, ins.Passphrase); err != nil {
+		return NewErrorResponse(err)
+	}
+	return NewSuccessResponse(nil)
+}
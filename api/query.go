@@ -18,6 +18,7 @@ import (
 	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/protocol/bc"
 	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/wallet"
 )
 
 // POST /list-accounts
@@ -128,12 +129,25 @@ func (a *API) getTransaction(ctx context.Context, txInfo struct {
 
 // POST /list-transactions
 func (a *API) listTransactions(ctx context.Context, filter struct {
-	ID          string `json:"id"`
-	AccountID   string `json:"account_id"`
-	Detail      bool   `json:"detail"`
-	Unconfirmed bool   `json:"unconfirmed"`
-	From        uint   `json:"from"`
-	Count       uint   `json:"count"`
+	ID          string   `json:"id"`
+	AccountID   string   `json:"account_id"`
+	Detail      bool     `json:"detail"`
+	Unconfirmed bool     `json:"unconfirmed"`
+	StartHeight uint64   `json:"start_height"`
+	EndHeight   uint64   `json:"end_height"`
+	StartTime   uint64   `json:"start_time"`
+	EndTime     uint64   `json:"end_time"`
+	AssetID     string   `json:"asset_id"`
+	MinAmount   uint64   `json:"min_amount"`
+	MaxAmount   uint64   `json:"max_amount"`
+	Direction   string   `json:"direction"`
+	Address     string   `json:"address"`
+	TxTypes     []string `json:"tx_types"`
+	Label       string   `json:"label"`
+	Memo        string   `json:"memo"`
+	Cursor      string   `json:"cursor"`
+	From        uint     `json:"from"`
+	Count       uint     `json:"count"`
 }) Response {
 	transactions := []*query.AnnotatedTx{}
 	var err error
@@ -150,17 +164,33 @@ func (a *API) listTransactions(ctx context.Context, filter struct {
 		}
 		transactions = []*query.AnnotatedTx{transaction}
 	} else {
-		transactions, err = a.wallet.GetTransactions(filter.AccountID)
-		if err != nil {
-			return NewErrorResponse(err)
+		txFilter := &wallet.TxFilter{
+			AccountID:   filter.AccountID,
+			Unconfirmed: filter.Unconfirmed,
+			StartHeight: filter.StartHeight,
+			EndHeight:   filter.EndHeight,
+			StartTime:   filter.StartTime,
+			EndTime:     filter.EndTime,
+			AssetID:     filter.AssetID,
+			MinAmount:   filter.MinAmount,
+			MaxAmount:   filter.MaxAmount,
+			Direction:   filter.Direction,
+			Address:     filter.Address,
+			TxTypes:     filter.TxTypes,
+			Label:       filter.Label,
+			Memo:        filter.Memo,
+			Cursor:      filter.Cursor,
 		}
 
-		if filter.Unconfirmed {
-			unconfirmedTxs, err := a.wallet.GetUnconfirmedTxs(filter.AccountID)
-			if err != nil {
-				return NewErrorResponse(err)
-			}
-			transactions = append(unconfirmedTxs, transactions...)
+		// the legacy offset paging needs the whole list, cursor paging
+		// only reads as many transactions as a page holds
+		if filter.From == 0 {
+			txFilter.Count = filter.Count
+		}
+
+		transactions, err = a.wallet.ListTransactions(txFilter)
+		if err != nil {
+			return NewErrorResponse(err)
 		}
 	}
 
@@ -192,6 +222,7 @@ func (a *API) getUnconfirmedTx(ctx context.Context, filter struct {
 		Version:   txDesc.Tx.Version,
 		Size:      txDesc.Tx.SerializedSize,
 		TimeRange: txDesc.Tx.TimeRange,
+		LockTime:  txDesc.Tx.LockTime,
 		Inputs:    []*query.AnnotatedInput{},
 		Outputs:   []*query.AnnotatedOutput{},
 	}
@@ -242,6 +273,7 @@ type RawTx struct {
 	Version   uint64                   `json:"version"`
 	Size      uint64                   `json:"size"`
 	TimeRange uint64                   `json:"time_range"`
+	LockTime  uint64                   `json:"lock_time,omitempty"`
 	Inputs    []*query.AnnotatedInput  `json:"inputs"`
 	Outputs   []*query.AnnotatedOutput `json:"outputs"`
 	Fee       uint64                   `json:"fee"`
@@ -256,6 +288,7 @@ func (a *API) decodeRawTransaction(ctx context.Context, ins struct {
 		Version:   ins.Tx.Version,
 		Size:      ins.Tx.SerializedSize,
 		TimeRange: ins.Tx.TimeRange,
+		LockTime:  ins.Tx.LockTime,
 		Inputs:    []*query.AnnotatedInput{},
 		Outputs:   []*query.AnnotatedOutput{},
 	}
@@ -278,6 +311,7 @@ func (a *API) listUnspentOutputs(ctx context.Context, filter struct {
 	ID            string `json:"id"`
 	Unconfirmed   bool   `json:"unconfirmed"`
 	SmartContract bool   `json:"smart_contract"`
+	Label         string `json:"label"`
 	From          uint   `json:"from"`
 	Count         uint   `json:"count"`
 }) Response {
@@ -293,6 +327,14 @@ func (a *API) listUnspentOutputs(ctx context.Context, filter struct {
 
 	UTXOs := []query.AnnotatedUTXO{}
 	for _, utxo := range accountUTXOs {
+		label := a.wallet.GetLabel(wallet.LabelOutput, utxo.OutputID.String())
+		if filter.Label != "" && (label == nil || !label.Match(filter.Label)) {
+			continue
+		}
+
+		if label == nil {
+			label = &wallet.Label{}
+		}
 		UTXOs = append([]query.AnnotatedUTXO{{
 			AccountID:           utxo.AccountID,
 			OutputID:            utxo.OutputID.String(),
@@ -307,6 +349,8 @@ func (a *API) listUnspentOutputs(ctx context.Context, filter struct {
 			Alias:               a.wallet.AccountMgr.GetAliasByID(utxo.AccountID),
 			AssetAlias:          a.wallet.AssetReg.GetAliasByID(utxo.AssetID.String()),
 			Change:              utxo.Change,
+			Labels:              label.Labels,
+			Note:                label.Note,
 		}}, UTXOs...)
 	}
 	start, end := getPageRange(len(UTXOs), filter.From, filter.Count)
@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/bytom/bytom/blockchain/txbuilder"
+	chainjson "github.com/bytom/bytom/encoding/json"
 )
 
 func (a *API) createAccountReceiver(ctx context.Context, ins struct {
@@ -28,5 +29,6 @@ func (a *API) createAccountReceiver(ctx context.Context, ins struct {
 	return NewSuccessResponse(&txbuilder.Receiver{
 		ControlProgram: program.ControlProgram,
 		Address:        program.Address,
+		MemoPublicKey:  chainjson.HexBytes(a.wallet.MemoPublicKey(program)),
 	})
 }
@@ -23,6 +23,7 @@ type BuildRequest struct {
 	Actions   []map[string]interface{} `json:"actions"`
 	TTL       json.Duration            `json:"ttl"`
 	TimeRange uint64                   `json:"time_range"`
+	LockTime  uint64                   `json:"lock_time"`
 }
 
 func (a *API) completeMissingIDs(ctx context.Context, br *BuildRequest) error {
@@ -32,6 +32,7 @@ func (a *API) actionDecoder(action string) (func([]byte) (txbuilder.Action, erro
 		"spend_account":                a.wallet.AccountMgr.DecodeSpendAction,
 		"spend_account_unspent_output": a.wallet.AccountMgr.DecodeSpendUTXOAction,
 		"veto":                         a.wallet.AccountMgr.DecodeVetoAction,
+		"call_contract_clause":         a.wallet.AccountMgr.DecodeCallClauseAction(a.wallet.ContractReg),
 	}
 	decoder, ok := decoders[action]
 	return decoder, ok
@@ -63,7 +64,7 @@ func (a *API) buildSingle(ctx context.Context, req *BuildRequest) (*txbuilder.Te
 	}
 
 	maxTime := time.Now().Add(req.TTL.Duration)
-	tpl, err := txbuilder.Build(ctx, req.Tx, actions, maxTime, req.TimeRange)
+	tpl, err := txbuilder.Build(ctx, req.Tx, actions, maxTime, req.TimeRange, req.LockTime)
 	if errors.Root(err) == txbuilder.ErrAction {
 		// append each of the inner errors contained in the data.
 		var Errs string
@@ -84,6 +85,10 @@ func (a *API) buildSingle(ctx context.Context, req *BuildRequest) (*txbuilder.Te
 	if tpl.SigningInstructions == nil {
 		tpl.SigningInstructions = []*txbuilder.SigningInstruction{}
 	}
+
+	if err := a.wallet.AccountMgr.CheckTemplatePolicy(tpl, time.Now()); err != nil {
+		return nil, err
+	}
 	return tpl, nil
 }
 
@@ -173,6 +178,12 @@ func (a *API) buildTxs(ctx context.Context, req *BuildRequest) ([]*txbuilder.Tem
 	}
 
 	tpls = append(tpls, tpl)
+	for _, tpl := range tpls {
+		if err := a.wallet.AccountMgr.CheckPolicy(tpl.Transaction, time.Now()); err != nil {
+			builder.Rollback()
+			return nil, err
+		}
+	}
 	return tpls, nil
 }
 
@@ -221,6 +232,30 @@ func (a *API) submitTxs(ctx context.Context, ins struct {
 	return NewSuccessResponse(&submitTxsResp{TxID: txHashs})
 }
 
+// POST /simulate-transaction
+func (a *API) simulateTx(ctx context.Context, ins struct {
+	Tx types.Tx `json:"raw_transaction"`
+}) Response {
+	simulation, err := a.chain.SimulateTx(&ins.Tx)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(simulation)
+}
+
+// POST /profile-transaction
+func (a *API) profileTx(ctx context.Context, ins struct {
+	Tx types.Tx `json:"raw_transaction"`
+}) Response {
+	profile, err := a.chain.ProfileTx(&ins.Tx)
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	return NewSuccessResponse(profile)
+}
+
 // POST /estimate-transaction-gas
 func (a *API) estimateTxGas(ctx context.Context, in struct {
 	TxTemplate txbuilder.Template `json:"transaction_template"`
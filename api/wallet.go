@@ -8,6 +8,7 @@ import (
 	"github.com/bytom/bytom/blockchain/pseudohsm"
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
 	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/wallet"
 )
 
 // POST /wallet error
@@ -20,6 +21,7 @@ type WalletImage struct {
 	AccountImage *account.Image      `json:"account_image"`
 	AssetImage   *asset.Image        `json:"asset_image"`
 	KeyImages    *pseudohsm.KeyImage `json:"key_images"`
+	LabelImage   *wallet.LabelImage  `json:"label_image,omitempty"`
 }
 
 func (a *API) restoreWalletImage(ctx context.Context, image WalletImage) Response {
@@ -32,6 +34,11 @@ func (a *API) restoreWalletImage(ctx context.Context, image WalletImage) Respons
 	if err := a.wallet.AccountMgr.Restore(image.AccountImage); err != nil {
 		return NewErrorResponse(errors.Wrap(err, "restore account image"))
 	}
+	if image.LabelImage != nil {
+		if err := a.wallet.RestoreLabels(image.LabelImage); err != nil {
+			return NewErrorResponse(errors.Wrap(err, "restore label image"))
+		}
+	}
 
 	var allAccounts []*account.Account
 	for _, acctImage := range image.AccountImage.Slice {
@@ -59,11 +66,16 @@ func (a *API) backupWalletImage() Response {
 	if err != nil {
 		return NewErrorResponse(errors.Wrap(err, "backup account image"))
 	}
+	labelImage, err := a.wallet.BackupLabels()
+	if err != nil {
+		return NewErrorResponse(errors.Wrap(err, "backup label image"))
+	}
 
 	image := &WalletImage{
 		KeyImages:    keyImages,
 		AssetImage:   assetImage,
 		AccountImage: accountImage,
+		LabelImage:   labelImage,
 	}
 	return NewSuccessResponse(image)
 }
@@ -98,6 +110,51 @@ func (a *API) getWalletInfo() Response {
 	})
 }
 
+type exportHistoryCSVResp struct {
+	CSV string `json:"csv"`
+}
+
+// POST /export-wallet-history
+func (a *API) exportWalletHistory(ctx context.Context, ins struct {
+	AccountID    string `json:"account_id"`
+	AccountAlias string `json:"account_alias"`
+	Format       string `json:"format"`
+	StartTime    uint64 `json:"start_time"`
+	EndTime      uint64 `json:"end_time"`
+	CostBasis    bool   `json:"cost_basis"`
+}) Response {
+	accountID := ins.AccountID
+	if ins.AccountAlias != "" {
+		acc, err := a.wallet.AccountMgr.FindByAlias(ins.AccountAlias)
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		accountID = acc.ID
+	}
+
+	export, err := a.wallet.ExportHistory(&wallet.ExportFilter{
+		AccountID: accountID,
+		StartTime: ins.StartTime,
+		EndTime:   ins.EndTime,
+		CostBasis: ins.CostBasis,
+	})
+	if err != nil {
+		return NewErrorResponse(err)
+	}
+
+	switch ins.Format {
+	case "", wallet.ExportJSON:
+		return NewSuccessResponse(export)
+	case wallet.ExportCSV:
+		csv, err := export.CSV()
+		if err != nil {
+			return NewErrorResponse(err)
+		}
+		return NewSuccessResponse(&exportHistoryCSVResp{CSV: csv})
+	}
+	return NewErrorResponse(errors.WithDetailf(wallet.ErrExportFormat, "format %s", ins.Format))
+}
+
 func (a *API) recoveryFromRootXPubs(ctx context.Context, in struct {
 	XPubs []chainkd.XPub `json:"xpubs"`
 }) Response {
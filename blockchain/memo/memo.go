@@ -0,0 +1,131 @@
+// Package memo implements the encrypted output memo convention. A memo is a
+// state data item of the output encrypted to a public key of the recipient,
+// usually the derived key of the receiving address:
+//
+//	"memo" || version || ephemeral public key (32) || nonce (12) || AES-256-GCM ciphertext
+//
+// The key is the SHA3-256 of the ECDH shared point, the ephemeral key and the
+// recipient key, so only the holder of the recipient private key reads it.
+package memo
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+
+	"github.com/bytom/bytom/crypto"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+const (
+	// Version is the current version of the memo envelope
+	Version = 1
+
+	// MaxSize is the longest plaintext memo
+	MaxSize = 256
+
+	magic      = "memo"
+	nonceSize  = 12
+	headerSize = len(magic) + 1 + ed25519.PublicKeySize + nonceSize
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrSize    = errors.New("memo is too long")
+	ErrDecrypt = errors.New("memo can't be decrypted with the key")
+)
+
+// Envelope is a parsed encrypted memo
+type Envelope struct {
+	Ephemeral  ed25519.PublicKey
+	Nonce      []byte
+	Ciphertext []byte
+}
+
+func newAEAD(shared, ephemeral, recipient []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(crypto.Sha256([]byte("Bytom memo"), shared, ephemeral, recipient))
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// Seal encrypts the memo to the recipient public key and returns the state
+// data item of the output
+func Seal(recipient ed25519.PublicKey, memo []byte) ([]byte, error) {
+	if len(memo) > MaxSize {
+		return nil, errors.WithDetailf(ErrSize, "got %d bytes want at most %d", len(memo), MaxSize)
+	}
+
+	ephemeral, err := chainkd.NewXPrv(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	shared, err := ephemeral.ECDH(recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	ephemeralPub := ephemeral.XPub().PublicKey()
+	aead, err := newAEAD(shared, ephemeralPub, recipient)
+	if err != nil {
+		return nil, err
+	}
+
+	data := append([]byte(magic), Version)
+	data = append(data, ephemeralPub...)
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	data = append(data, nonce...)
+	return aead.Seal(data, nonce, memo, []byte(magic)), nil
+}
+
+// Parse recognizes an encrypted memo in the state data item
+func Parse(data []byte) (*Envelope, bool) {
+	if len(data) <= headerSize || !bytes.HasPrefix(data, []byte(magic)) || data[len(magic)] != Version {
+		return nil, false
+	}
+
+	offset := len(magic) + 1
+	return &Envelope{
+		Ephemeral:  ed25519.PublicKey(data[offset : offset+ed25519.PublicKeySize]),
+		Nonce:      data[offset+ed25519.PublicKeySize : headerSize],
+		Ciphertext: data[headerSize:],
+	}, true
+}
+
+// Find returns the first encrypted memo of the output state data
+func Find(stateData [][]byte) (*Envelope, bool) {
+	for _, data := range stateData {
+		if envelope, ok := Parse(data); ok {
+			return envelope, true
+		}
+	}
+	return nil, false
+}
+
+// Open decrypts the memo with the private key of the recipient
+func (e *Envelope) Open(xprv chainkd.XPrv) ([]byte, error) {
+	shared, err := xprv.ECDH(e.Ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(shared, e.Ephemeral, xprv.XPub().PublicKey())
+	if err != nil {
+		return nil, err
+	}
+
+	memo, err := aead.Open(nil, e.Nonce, e.Ciphertext, []byte(magic))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return memo, nil
+}
@@ -0,0 +1,55 @@
+package memo
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+func TestSealOpen(t *testing.T) {
+	root, err := chainkd.NewXPrv(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recipient := root.Derive([][]byte{{0x2c, 0, 0, 0}, {0x01, 0, 0, 0}})
+	data, err := Seal(recipient.XPub().PublicKey(), []byte("deposit tag 10086"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, ok := Find([][]byte{[]byte("other state"), data})
+	if !ok {
+		t.Fatal("sealed memo is not recognized")
+	}
+
+	memo, err := envelope.Open(recipient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(memo, []byte("deposit tag 10086")) {
+		t.Errorf("opened memo got %s", memo)
+	}
+
+	if _, err := envelope.Open(root); err != ErrDecrypt {
+		t.Errorf("open with another key got error %v want %v", err, ErrDecrypt)
+	}
+
+	data[len(data)-1] ^= 1
+	envelope, _ = Parse(data)
+	if _, err := envelope.Open(recipient); err != ErrDecrypt {
+		t.Errorf("open tampered memo got error %v want %v", err, ErrDecrypt)
+	}
+
+	if _, ok := Parse([]byte("memo")); ok {
+		t.Error("short data should not be recognized as memo")
+	}
+
+	if _, err := Seal(recipient.XPub().PublicKey(), make([]byte, MaxSize+1)); errors.Root(err) != ErrSize {
+		t.Errorf("seal long memo got error %v want %v", err, ErrSize)
+	}
+}
@@ -0,0 +1,164 @@
+// Package message implements the versioned message signing format proving
+// the ownership of P2WPKH and P2WSH addresses.
+//
+// The signed digest is the SHA3-256 of
+//
+//	"Bytom Signed Message:\n" || version || varstr(chain_id) || varstr(address) || varstr(message) || varint(n) || utxo_1 .. utxo_n
+//
+// so a signature can neither be replayed on another network, for another
+// address, nor be mistaken for a transaction signature. A statement listing
+// UTXOs is the proof-of-reserves mode, the owner signs over those outputs.
+package message
+
+import (
+	"bytes"
+	"crypto/ed25519"
+
+	"github.com/bytom/bytom/common"
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/crypto"
+	"github.com/bytom/bytom/encoding/blockchain"
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+const (
+	// Version is the current version of the signed message format
+	Version = 1
+
+	// DomainPrefix separates the message digest from any other signed data
+	DomainPrefix = "Bytom Signed Message:\n"
+
+	// LegacyDomainPrefix separates the digest of the unversioned
+	// sign-message from transaction sighashes and versioned statements
+	LegacyDomainPrefix = "Bytom Legacy Signed Message:\n"
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrVersion   = errors.New("unsupported signed message version")
+	ErrChainID   = errors.New("signed message is for another chain")
+	ErrAddress   = errors.New("signed message address is neither P2WPKH nor P2WSH")
+	ErrSignature = errors.New("signed message signature is invalid")
+	ErrQuorum    = errors.New("signed message does not have enough signatures")
+	ErrUTXO      = errors.New("utxo is not controlled by the signed message address")
+)
+
+// Statement is the content covered by the signatures
+type Statement struct {
+	Version int                `json:"version"`
+	ChainID string             `json:"chain_id"`
+	Address string             `json:"address"`
+	Message chainjson.HexBytes `json:"message"`
+	UTXOs   []bc.Hash          `json:"utxos,omitempty"`
+}
+
+// Signature is the signature of one key over the statement digest
+type Signature struct {
+	PublicKey chainjson.HexBytes `json:"public_key"`
+	Signature chainjson.HexBytes `json:"signature"`
+}
+
+// SignedMessage is the statement with the signatures, the redeem script is
+// only carried by P2WSH addresses
+type SignedMessage struct {
+	Statement
+	Signatures   []*Signature       `json:"signatures"`
+	RedeemScript chainjson.HexBytes `json:"redeem_script,omitempty"`
+}
+
+// NewStatement returns the statement of the address on the active network
+func NewStatement(address string, msg []byte, utxos []bc.Hash) *Statement {
+	return &Statement{
+		Version: Version,
+		ChainID: consensus.ActiveNetParams.Name,
+		Address: address,
+		Message: msg,
+		UTXOs:   utxos,
+	}
+}
+
+// Digest returns the hash the keys sign
+func (s *Statement) Digest() []byte {
+	buf := bytes.NewBufferString(DomainPrefix)
+	buf.WriteByte(byte(s.Version))
+	blockchain.WriteVarstr31(buf, []byte(s.ChainID))
+	blockchain.WriteVarstr31(buf, []byte(s.Address))
+	blockchain.WriteVarstr31(buf, s.Message)
+	blockchain.WriteVarint31(buf, uint64(len(s.UTXOs)))
+	for _, utxo := range s.UTXOs {
+		utxo.WriteTo(buf)
+	}
+	return crypto.Sha256(buf.Bytes())
+}
+
+// LegacyDigest returns the hash the keys sign for the unversioned
+// sign-message, the raw message is never signed as it is
+func LegacyDigest(msg []byte) []byte {
+	buf := bytes.NewBufferString(LegacyDomainPrefix)
+	blockchain.WriteVarstr31(buf, msg)
+	return crypto.Sha256(buf.Bytes())
+}
+
+// Verify checks the signed message is bound to the address on the network
+// of the params, a P2WSH address needs the signatures of its quorum
+func Verify(m *SignedMessage, params *consensus.Params) error {
+	if m.Version != Version {
+		return errors.WithDetailf(ErrVersion, "version %d", m.Version)
+	}
+
+	if m.ChainID != params.Name {
+		return errors.WithDetailf(ErrChainID, "got %s want %s", m.ChainID, params.Name)
+	}
+
+	address, err := common.DecodeAddress(m.Address, params)
+	if err != nil {
+		return err
+	}
+
+	digest := m.Digest()
+	switch address := address.(type) {
+	case *common.AddressWitnessPubKeyHash:
+		if len(m.Signatures) != 1 {
+			return errors.WithDetailf(ErrQuorum, "got %d signatures want 1", len(m.Signatures))
+		}
+
+		sig := m.Signatures[0]
+		if len(sig.PublicKey) != ed25519.PublicKeySize || !bytes.Equal(crypto.Ripemd160(sig.PublicKey), address.ScriptAddress()) {
+			return errors.WithDetail(ErrSignature, "public key does not match the address")
+		}
+
+		if !ed25519.Verify(ed25519.PublicKey(sig.PublicKey), digest, sig.Signature) {
+			return ErrSignature
+		}
+		return nil
+
+	case *common.AddressWitnessScriptHash:
+		if !bytes.Equal(crypto.Sha256(m.RedeemScript), address.ScriptAddress()) {
+			return errors.WithDetail(ErrSignature, "redeem script does not match the address")
+		}
+
+		pubkeys, quorum, err := vmutil.ParseP2SPMultiSigProgram(m.RedeemScript)
+		if err != nil {
+			return err
+		}
+
+		signed := map[int]bool{}
+		for _, sig := range m.Signatures {
+			for i, pubkey := range pubkeys {
+				if !signed[i] && bytes.Equal(pubkey, sig.PublicKey) && ed25519.Verify(pubkey, digest, sig.Signature) {
+					signed[i] = true
+					break
+				}
+			}
+		}
+
+		if len(signed) < quorum {
+			return errors.WithDetailf(ErrQuorum, "got %d valid signatures want %d", len(signed), quorum)
+		}
+		return nil
+	}
+	return ErrAddress
+}
@@ -0,0 +1,110 @@
+package message
+
+import (
+	"bytes"
+	"crypto/rand"
+	"testing"
+
+	"github.com/bytom/bytom/common"
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/crypto"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+func mockKeys(t *testing.T, n int) []chainkd.XPrv {
+	xprvs := []chainkd.XPrv{}
+	for i := 0; i < n; i++ {
+		xprv, err := chainkd.NewXPrv(rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		xprvs = append(xprvs, xprv)
+	}
+	return xprvs
+}
+
+func sign(m *SignedMessage, xprv chainkd.XPrv) {
+	m.Signatures = append(m.Signatures, &Signature{PublicKey: []byte(xprv.XPub().PublicKey()), Signature: xprv.Sign(m.Digest())})
+}
+
+func TestVerifyP2WPKH(t *testing.T) {
+	xprvs := mockKeys(t, 2)
+	address, err := common.NewAddressWitnessPubKeyHash(crypto.Ripemd160(xprvs[0].XPub().PublicKey()), &consensus.ActiveNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &SignedMessage{Statement: *NewStatement(address.EncodeAddress(), []byte("proof of ownership"), nil)}
+	sign(m, xprvs[0])
+	if err := Verify(m, &consensus.ActiveNetParams); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Verify(m, &consensus.TestNetParams); err == nil {
+		t.Error("verify on another chain should fail")
+	}
+
+	m.ChainID = consensus.TestNetParams.Name
+	if err := Verify(m, &consensus.ActiveNetParams); errors.Root(err) != ErrChainID {
+		t.Errorf("verify with another chain id got error %v want %v", err, ErrChainID)
+	}
+
+	m.ChainID = consensus.ActiveNetParams.Name
+	m.UTXOs = []bc.Hash{bc.NewHash([32]byte{1})}
+	if err := Verify(m, &consensus.ActiveNetParams); errors.Root(err) != ErrSignature {
+		t.Errorf("verify with changed utxos got error %v want %v", err, ErrSignature)
+	}
+
+	m.UTXOs = nil
+	m.Signatures = nil
+	sign(m, xprvs[1])
+	if err := Verify(m, &consensus.ActiveNetParams); errors.Root(err) != ErrSignature {
+		t.Errorf("verify with another key got error %v want %v", err, ErrSignature)
+	}
+}
+
+func TestVerifyP2WSH(t *testing.T) {
+	xprvs := mockKeys(t, 3)
+	redeemScript, err := vmutil.P2SPMultiSigProgram(chainkd.XPubKeys([]chainkd.XPub{xprvs[0].XPub(), xprvs[1].XPub(), xprvs[2].XPub()}), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	address, err := common.NewAddressWitnessScriptHash(crypto.Sha256(redeemScript), &consensus.ActiveNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	utxos := []bc.Hash{bc.NewHash([32]byte{1}), bc.NewHash([32]byte{2})}
+	m := &SignedMessage{Statement: *NewStatement(address.EncodeAddress(), []byte("reserves"), utxos), RedeemScript: redeemScript}
+	sign(m, xprvs[2])
+	if err := Verify(m, &consensus.ActiveNetParams); errors.Root(err) != ErrQuorum {
+		t.Errorf("verify with one signature got error %v want %v", err, ErrQuorum)
+	}
+
+	sign(m, xprvs[2])
+	if err := Verify(m, &consensus.ActiveNetParams); errors.Root(err) != ErrQuorum {
+		t.Errorf("verify with a duplicated signature got error %v want %v", err, ErrQuorum)
+	}
+
+	sign(m, xprvs[0])
+	if err := Verify(m, &consensus.ActiveNetParams); err != nil {
+		t.Fatal(err)
+	}
+
+	m.RedeemScript = m.RedeemScript[1:]
+	if err := Verify(m, &consensus.ActiveNetParams); errors.Root(err) != ErrSignature {
+		t.Errorf("verify with a bad redeem script got error %v want %v", err, ErrSignature)
+	}
+}
+
+func TestLegacyDigest(t *testing.T) {
+	msg := []byte("legacy message")
+	digest := LegacyDigest(msg)
+	if bytes.Equal(digest, msg) || bytes.Equal(digest, NewStatement("", msg, nil).Digest()) {
+		t.Fatal("legacy digest is not domain separated")
+	}
+}
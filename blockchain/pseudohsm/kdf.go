@@ -0,0 +1,161 @@
+package pseudohsm
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var ErrUnsupportedKDF = errors.New("unsupported key derivation function")
+
+// KeyKDF is the encryption status of a key file, the weak keys should be
+// upgraded by UpgradeKeys
+type KeyKDF struct {
+	Alias     string                 `json:"alias"`
+	XPub      chainkd.XPub           `json:"xpub"`
+	File      string                 `json:"file"`
+	Version   int                    `json:"version"`
+	KDF       string                 `json:"kdf"`
+	KDFParams map[string]interface{} `json:"kdf_params"`
+	Weak      bool                   `json:"weak"`
+	Reason    string                 `json:"reason,omitempty"`
+}
+
+// KeyUpgrade is the result of upgrading the encryption of a key file
+type KeyUpgrade struct {
+	Alias    string       `json:"alias"`
+	XPub     chainkd.XPub `json:"xpub"`
+	Upgraded bool         `json:"upgraded"`
+	Reason   string       `json:"reason,omitempty"`
+}
+
+// weakKDFReason explains why the kdf parameters are weaker than the ones
+// used for new keys, an empty result means the parameters are strong enough
+func weakKDFReason(crypto cryptoJSON) string {
+	param := func(name string) int {
+		if _, ok := crypto.KDFParams[name]; !ok {
+			return 0
+		}
+		return ensureInt(crypto.KDFParams[name])
+	}
+
+	switch crypto.KDF {
+	case KDFScrypt:
+		n, r, p := param("n"), param("r"), param("p")
+		if n*r*p < LightScryptN*scryptR*LightScryptP || n < LightScryptN {
+			return fmt.Sprintf("scrypt n=%d r=%d p=%d is weaker than n=%d r=%d p=%d", n, r, p, LightScryptN, scryptR, LightScryptP)
+		}
+
+	case KDFArgon2id:
+		t, m := param("t"), param("m")
+		if t < Argon2idTime || m < Argon2idMemory {
+			return fmt.Sprintf("argon2id t=%d m=%d is weaker than t=%d m=%d", t, m, Argon2idTime, Argon2idMemory)
+		}
+
+	case "pbkdf2":
+		return "pbkdf2 is not memory hard"
+
+	default:
+		return fmt.Sprintf("unsupported kdf %s", crypto.KDF)
+	}
+	return ""
+}
+
+func readEncryptedKey(file string) (*encryptedKeyJSON, error) {
+	keyjson, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	k := new(encryptedKeyJSON)
+	if err := json.Unmarshal(keyjson, k); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// CheckKeyKDFs reports the key derivation function of every key file and
+// whether its parameters are weak, no password is needed.
+func (h *HSM) CheckKeyKDFs() ([]*KeyKDF, error) {
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+
+	result := []*KeyKDF{}
+	for _, xpub := range h.cache.keys() {
+		k, err := readEncryptedKey(xpub.File)
+		if err != nil {
+			return nil, err
+		}
+
+		params := map[string]interface{}{}
+		for name, value := range k.Crypto.KDFParams {
+			if name != "salt" {
+				params[name] = value
+			}
+		}
+
+		reason := weakKDFReason(k.Crypto)
+		result = append(result, &KeyKDF{
+			Alias:     xpub.Alias,
+			XPub:      xpub.XPub,
+			File:      xpub.File,
+			Version:   k.Version,
+			KDF:       k.Crypto.KDF,
+			KDFParams: params,
+			Weak:      reason != "",
+			Reason:    reason,
+		})
+	}
+	return result, nil
+}
+
+// UpgradeKeys re-encrypts every key file that decrypts by the password with
+// the current parameters of the kdf, an empty kdf keeps the kdf of each key
+// and only moves pbkdf2 keys to scrypt. Keys with a different password are
+// left untouched and reported as not upgraded.
+func (h *HSM) UpgradeKeys(auth string, kdf string) ([]*KeyUpgrade, error) {
+	if kdf != "" && kdf != KDFScrypt && kdf != KDFArgon2id {
+		return nil, ErrUnsupportedKDF
+	}
+
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+
+	result := []*KeyUpgrade{}
+	for _, xpub := range h.cache.keys() {
+		upgrade := &KeyUpgrade{Alias: xpub.Alias, XPub: xpub.XPub}
+		result = append(result, upgrade)
+
+		xpb, xkey, err := h.loadDecryptedKey(xpub.XPub, auth)
+		if err != nil {
+			upgrade.Reason = err.Error()
+			continue
+		}
+
+		keyKDF := kdf
+		if keyKDF == "" {
+			k, err := readEncryptedKey(xpb.File)
+			if err != nil {
+				zeroKey(xkey)
+				return result, err
+			}
+
+			if keyKDF = k.Crypto.KDF; keyKDF != KDFArgon2id {
+				keyKDF = KDFScrypt
+			}
+		}
+
+		err = h.keyStore.StoreKeyWithKDF(xpb.File, xkey, auth, keyKDF)
+		zeroKey(xkey)
+		if err != nil {
+			return result, err
+		}
+
+		upgrade.Upgraded = true
+	}
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package pseudohsm
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestUpgradeKeys(t *testing.T) {
+	dir, err := ioutil.TempDir(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hsm, _ := New(dir)
+	xpub, _, err := hsm.XCreate("upgrade", "password", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _, err := hsm.XCreate("other", "other_password", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, xkey, err := hsm.loadDecryptedKey(xpub.XPub, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	weakJSON, err := EncryptKey(xkey, "password", veryLightScryptN, veryLightScryptP)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writeKeyFile(xpub.File, weakJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	checkWeak := func(want map[string]bool, wantKDF string) {
+		kdfs, err := hsm.CheckKeyKDFs()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(kdfs) != len(want) {
+			t.Fatalf("got %d keys want %d", len(kdfs), len(want))
+		}
+
+		for _, kdf := range kdfs {
+			if kdf.Weak != want[kdf.Alias] {
+				t.Errorf("key %s got weak %v want %v: %s", kdf.Alias, kdf.Weak, want[kdf.Alias], kdf.Reason)
+			}
+
+			if kdf.Alias == xpub.Alias && kdf.KDF != wantKDF {
+				t.Errorf("key %s got kdf %s want %s", kdf.Alias, kdf.KDF, wantKDF)
+			}
+
+			if _, ok := kdf.KDFParams["salt"]; ok {
+				t.Errorf("key %s reports the kdf salt", kdf.Alias)
+			}
+		}
+	}
+	checkWeak(map[string]bool{"upgrade": true, "other": false}, KDFScrypt)
+
+	if _, err := hsm.UpgradeKeys("password", "bcrypt"); err != ErrUnsupportedKDF {
+		t.Fatalf("upgrade with bcrypt got error %v want %v", err, ErrUnsupportedKDF)
+	}
+
+	upgrades, err := hsm.UpgradeKeys("password", KDFArgon2id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, upgrade := range upgrades {
+		if upgrade.Upgraded != (upgrade.XPub == xpub.XPub) {
+			t.Errorf("key %s got upgraded %v", upgrade.Alias, upgrade.Upgraded)
+		}
+	}
+	checkWeak(map[string]bool{"upgrade": false, "other": false}, KDFArgon2id)
+
+	msg := []byte("upgrade")
+	sig, err := hsm.XSign(xpub.XPub, nil, msg, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !xpub.XPub.Verify(msg, sig) {
+		t.Error("upgraded key signature verify failed")
+	}
+
+	if _, err := hsm.XSign(other.XPub, nil, msg, "other_password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := hsm.UpgradeKeys("password", ""); err != nil {
+		t.Fatal(err)
+	}
+	checkWeak(map[string]bool{"upgrade": false, "other": false}, KDFArgon2id)
+
+	if _, err := hsm.UpgradeKeys("password", KDFScrypt); err != nil {
+		t.Fatal(err)
+	}
+	checkWeak(map[string]bool{"upgrade": false, "other": false}, KDFScrypt)
+}
@@ -13,7 +13,10 @@ import (
 
 const (
 	version = 1
-	keytype = "bytom_kd"
+	// versionArgon2id key files may be encrypted by the argon2id kdf, scrypt
+	// key files keep version 1 so that older nodes can still load them
+	versionArgon2id = 2
+	keytype         = "bytom_kd"
 )
 
 // XKey struct type for keystore file
@@ -30,6 +33,9 @@ type keyStore interface {
 	GetKey(alias string, filename string, auth string) (*XKey, error)
 	// Writes and encrypts the key.
 	StoreKey(filename string, k *XKey, auth string) error
+	// Writes and encrypts the key with the given kdf, an empty kdf uses the
+	// parameters of the key store.
+	StoreKeyWithKDF(filename string, k *XKey, auth string, kdf string) error
 	// Joins filename with the key directory unless it is already absolute.
 	JoinPath(filename string) string
 }
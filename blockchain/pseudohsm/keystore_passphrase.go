@@ -20,6 +20,7 @@ import (
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
 	"github.com/bytom/bytom/crypto/randentropy"
 	"github.com/pborman/uuid"
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/pbkdf2"
 	"golang.org/x/crypto/scrypt"
 )
@@ -27,6 +28,11 @@ import (
 const (
 	keyHeaderKDF = "scrypt"
 
+	// KDFScrypt derives the encryption key by scrypt
+	KDFScrypt = "scrypt"
+	// KDFArgon2id derives the encryption key by argon2id
+	KDFArgon2id = "argon2id"
+
 	// StandardScryptN n,r,p = 2^18, 8, 1 uses 256MB memory and approx 1s CPU time on a modern CPU.
 	StandardScryptN = 1 << 18
 	// StandardScryptP fit above
@@ -39,8 +45,31 @@ const (
 
 	scryptR     = 8
 	scryptDKLen = 32
+
+	// Argon2idTime t,m,p = 3, 64MB, 4 takes approx 150ms CPU time on a modern CPU.
+	Argon2idTime = 3
+	// Argon2idMemory fit above, in KiB
+	Argon2idMemory = 64 * 1024
+	// Argon2idThreads fit above
+	Argon2idThreads = 4
 )
 
+// KDFParams is the key derivation function and its cost parameters used to
+// encrypt a key
+type KDFParams struct {
+	KDF           string
+	ScryptN       int
+	ScryptP       int
+	Argon2Time    uint32
+	Argon2Memory  uint32
+	Argon2Threads uint8
+}
+
+// DefaultArgon2idParams returns the recommended argon2id parameters
+func DefaultArgon2idParams() *KDFParams {
+	return &KDFParams{KDF: KDFArgon2id, Argon2Time: Argon2idTime, Argon2Memory: Argon2idMemory, Argon2Threads: Argon2idThreads}
+}
+
 type keyStorePassphrase struct {
 	keysDirPath string
 	scryptN     int
@@ -65,7 +94,20 @@ func (ks keyStorePassphrase) GetKey(alias string, filename, auth string) (*XKey,
 }
 
 func (ks keyStorePassphrase) StoreKey(filename string, key *XKey, auth string) error {
-	keyjson, err := EncryptKey(key, auth, ks.scryptN, ks.scryptP)
+	return ks.StoreKeyWithKDF(filename, key, auth, "")
+}
+
+func (ks keyStorePassphrase) StoreKeyWithKDF(filename string, key *XKey, auth string, kdf string) error {
+	params := &KDFParams{KDF: KDFScrypt, ScryptN: ks.scryptN, ScryptP: ks.scryptP}
+	switch kdf {
+	case "", KDFScrypt:
+	case KDFArgon2id:
+		params = DefaultArgon2idParams()
+	default:
+		return ErrUnsupportedKDF
+	}
+
+	keyjson, err := EncryptKeyWithKDF(key, auth, params)
 	if err != nil {
 		return err
 	}
@@ -82,12 +124,47 @@ func (ks keyStorePassphrase) JoinPath(filename string) string {
 // EncryptKey encrypts a key using the specified scrypt parameters into a json
 // blob that can be decrypted later on.
 func EncryptKey(key *XKey, auth string, scryptN, scryptP int) ([]byte, error) {
+	return EncryptKeyWithKDF(key, auth, &KDFParams{KDF: KDFScrypt, ScryptN: scryptN, ScryptP: scryptP})
+}
+
+// EncryptKeyWithKDF encrypts a key using the specified kdf parameters into a
+// json blob that can be decrypted later on.
+func EncryptKeyWithKDF(key *XKey, auth string, params *KDFParams) ([]byte, error) {
 	authArray := []byte(auth)
 	salt := randentropy.GetEntropyCSPRNG(32)
-	derivedKey, err := scrypt.Key(authArray, salt, scryptN, scryptR, scryptP, scryptDKLen)
-	if err != nil {
-		return nil, err
+	kdfParamsJSON := make(map[string]interface{}, 5)
+	kdfParamsJSON["dklen"] = scryptDKLen
+	kdfParamsJSON["salt"] = hex.EncodeToString(salt)
+
+	var derivedKey []byte
+	keyVersion := version
+	switch params.KDF {
+	case KDFScrypt:
+		var err error
+		if derivedKey, err = scrypt.Key(authArray, salt, params.ScryptN, scryptR, params.ScryptP, scryptDKLen); err != nil {
+			return nil, err
+		}
+
+		kdfParamsJSON["n"] = params.ScryptN
+		kdfParamsJSON["r"] = scryptR
+		kdfParamsJSON["p"] = params.ScryptP
+
+	case KDFArgon2id:
+		if params.Argon2Time < 1 || params.Argon2Threads < 1 {
+			return nil, ErrUnsupportedKDF
+		}
+
+		derivedKey = argon2.IDKey(authArray, salt, params.Argon2Time, params.Argon2Memory, params.Argon2Threads, scryptDKLen)
+		kdfParamsJSON["t"] = params.Argon2Time
+		kdfParamsJSON["m"] = params.Argon2Memory
+		kdfParamsJSON["p"] = params.Argon2Threads
+		kdfParamsJSON["v"] = argon2.Version
+		keyVersion = versionArgon2id
+
+	default:
+		return nil, ErrUnsupportedKDF
 	}
+
 	encryptKey := derivedKey[:16]
 	keyBytes := key.XPrv[:]
 
@@ -97,12 +174,6 @@ func EncryptKey(key *XKey, auth string, scryptN, scryptP int) ([]byte, error) {
 		return nil, err
 	}
 	mac := crypto.Sha256(derivedKey[16:32], cipherText)
-	scryptParamsJSON := make(map[string]interface{}, 5)
-	scryptParamsJSON["n"] = scryptN
-	scryptParamsJSON["r"] = scryptR
-	scryptParamsJSON["p"] = scryptP
-	scryptParamsJSON["dklen"] = scryptDKLen
-	scryptParamsJSON["salt"] = hex.EncodeToString(salt)
 
 	cipherParamsJSON := cipherparamsJSON{
 		IV: hex.EncodeToString(iv),
@@ -111,15 +182,15 @@ func EncryptKey(key *XKey, auth string, scryptN, scryptP int) ([]byte, error) {
 		Cipher:       "aes-128-ctr",
 		CipherText:   hex.EncodeToString(cipherText),
 		CipherParams: cipherParamsJSON,
-		KDF:          "scrypt",
-		KDFParams:    scryptParamsJSON,
+		KDF:          params.KDF,
+		KDFParams:    kdfParamsJSON,
 		MAC:          hex.EncodeToString(mac),
 	}
 	encryptedKeyJSON := encryptedKeyJSON{
 		cryptoStruct,
 		key.ID.String(),
 		key.KeyType,
-		version,
+		keyVersion,
 		key.Alias,
 		hex.EncodeToString(key.XPub[:]),
 	}
@@ -163,10 +234,14 @@ func DecryptKey(keyjson []byte, auth string) (*XKey, error) {
 }
 
 func decryptKey(keyProtected *encryptedKeyJSON, auth string) (keyBytes []byte, keyID []byte, err error) {
-	if keyProtected.Version != version {
+	if keyProtected.Version != version && keyProtected.Version != versionArgon2id {
 		return nil, nil, fmt.Errorf("Version not supported: %v", keyProtected.Version)
 	}
 
+	if keyProtected.Crypto.KDF == KDFArgon2id && keyProtected.Version < versionArgon2id {
+		return nil, nil, fmt.Errorf("KDF %s not supported by version %v", KDFArgon2id, keyProtected.Version)
+	}
+
 	if keyProtected.Type != keytype {
 		return nil, nil, fmt.Errorf("Key type not supported: %v", keyProtected.Type)
 	}
@@ -231,6 +306,15 @@ func getKDFKey(cryptoJSON cryptoJSON, auth string) ([]byte, error) {
 		}
 		key := pbkdf2.Key(authArray, salt, c, dkLen, sha256.New)
 		return key, nil
+
+	} else if cryptoJSON.KDF == KDFArgon2id {
+		t := ensureInt(cryptoJSON.KDFParams["t"])
+		m := ensureInt(cryptoJSON.KDFParams["m"])
+		p := ensureInt(cryptoJSON.KDFParams["p"])
+		if t < 1 || m < 1 || p < 1 || p > 255 {
+			return nil, fmt.Errorf("Invalid argon2id params: t=%d m=%d p=%d", t, m, p)
+		}
+		return argon2.IDKey(authArray, salt, uint32(t), uint32(m), uint8(p), uint32(dkLen)), nil
 	}
 
 	return nil, fmt.Errorf("Unsupported KDF: %s", cryptoJSON.KDF)
@@ -0,0 +1,15 @@
+// +build darwin freebsd linux netbsd openbsd
+
+package pseudohsm
+
+import "syscall"
+
+// mlockKey keeps the key memory from being swapped to disk, it is best
+// effort since the process may lack the privilege
+func mlockKey(b []byte) {
+	syscall.Mlock(b)
+}
+
+func munlockKey(b []byte) {
+	syscall.Munlock(b)
+}
@@ -0,0 +1,10 @@
+// +build !darwin,!freebsd,!linux,!netbsd,!openbsd
+
+// This is the fallback implementation of key memory locking.
+// It is used on the platforms without mlock.
+
+package pseudohsm
+
+func mlockKey(b []byte) {}
+
+func munlockKey(b []byte) {}
@@ -34,6 +34,9 @@ type HSM struct {
 	cacheMu  sync.Mutex
 	keyStore keyStore
 	cache    *keyCache
+
+	unlockMu sync.Mutex
+	unlocked map[chainkd.XPub]*unlockedKey
 }
 
 // XPub type for pubkey for anyone can see
@@ -49,6 +52,7 @@ func New(keypath string) (*HSM, error) {
 	return &HSM{
 		keyStore: &keyStorePassphrase{keydir, LightScryptN, LightScryptP},
 		cache:    newKeyCache(keydir),
+		unlocked: make(map[chainkd.XPub]*unlockedKey),
 	}, nil
 }
 
@@ -104,10 +108,15 @@ func (h *HSM) ImportKeyFromMnemonic(alias string, auth string, mnemonic string,
 func (h *HSM) createKeyFromMnemonic(alias string, auth string, mnemonic string) (*XPub, error) {
 	// Generate a Bip32 HD wallet for the mnemonic and a user supplied password
 	seed := mnem.NewSeed(mnemonic, "")
-	xprv, xpub, err := chainkd.NewXKeys(bytes.NewBuffer(seed))
+	xprv, _, err := chainkd.NewXKeys(bytes.NewBuffer(seed))
 	if err != nil {
 		return nil, err
 	}
+	return h.createKeyFromXPrv(alias, auth, xprv)
+}
+
+func (h *HSM) createKeyFromXPrv(alias string, auth string, xprv chainkd.XPrv) (*XPub, error) {
+	xpub := xprv.XPub()
 	id := uuid.NewRandom()
 	key := &XKey{
 		ID:      id,
@@ -234,6 +243,7 @@ func (h *HSM) XDelete(xpub chainkd.XPub, auth string) error {
 		return err
 	}
 
+	h.Lock(xpub)
 	h.cacheMu.Lock()
 	// The order is crucial here. The key is dropped from the
 	// cache after the file is gone so that a reload happening in
@@ -0,0 +1,136 @@
+package pseudohsm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"strings"
+
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/crypto/shamir"
+	"github.com/bytom/bytom/errors"
+	mnem "github.com/bytom/bytom/wallet/mnemonic"
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrShareThreshold = errors.New("share threshold must be at least 2 and not greater than share count 16")
+	ErrShareMismatch  = errors.New("shares do not belong to the same share set")
+	ErrShareNotEnough = errors.New("not enough shares to restore the key")
+	ErrDuplicateKey   = errors.New("key already exists")
+)
+
+// ExportKeyShares splits the root private key to count share mnemonics, any
+// threshold of them restore the key by ImportKeyFromShares. The threshold is
+// at least 2 so that no single share can restore the key on its own.
+func (h *HSM) ExportKeyShares(xpub chainkd.XPub, auth string, threshold, count int, language string) ([]string, error) {
+	if threshold < 2 || threshold > count || count > shamir.MaxShares {
+		return nil, ErrShareThreshold
+	}
+
+	xprv, err := h.LoadChainKDKey(xpub, auth)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		for i := range xprv {
+			xprv[i] = 0
+		}
+	}()
+
+	points, err := shamir.Split(xprv[:], threshold, count)
+	if err != nil {
+		return nil, err
+	}
+
+	var id [2]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+
+	mnemonics := []string{}
+	for _, point := range points {
+		share := &mnem.Share{
+			ID:        binary.BigEndian.Uint16(id[:]),
+			Threshold: threshold,
+			Count:     count,
+			Index:     int(point.X),
+			Value:     point.Y,
+		}
+		mnemonic, err := mnem.NewShareMnemonic(share, language)
+		if err != nil {
+			return nil, err
+		}
+
+		mnemonics = append(mnemonics, mnemonic)
+	}
+	return mnemonics, nil
+}
+
+// ImportKeyFromShares restores the root private key from the share mnemonics
+// of one share set and stores it in the db.
+func (h *HSM) ImportKeyFromShares(alias string, auth string, mnemonics []string, language string) (*XPub, error) {
+	h.cacheMu.Lock()
+	defer h.cacheMu.Unlock()
+
+	normalizedAlias := strings.ToLower(strings.TrimSpace(alias))
+	if ok := h.cache.hasAlias(normalizedAlias); ok {
+		return nil, ErrDuplicateKeyAlias
+	}
+
+	shares := []*mnem.Share{}
+	for _, mnemonic := range mnemonics {
+		share, err := mnem.ParseShareMnemonic(mnemonic, language)
+		if err != nil {
+			return nil, err
+		}
+
+		shares = append(shares, share)
+	}
+
+	points := []*shamir.Share{}
+	indexes := map[int]bool{}
+	for _, share := range shares {
+		if share.ID != shares[0].ID || share.Threshold != shares[0].Threshold || share.Count != shares[0].Count {
+			return nil, ErrShareMismatch
+		}
+
+		if indexes[share.Index] {
+			continue
+		}
+
+		indexes[share.Index] = true
+		points = append(points, &shamir.Share{X: byte(share.Index), Y: share.Value})
+	}
+
+	if len(shares) == 0 || len(points) < shares[0].Threshold {
+		return nil, ErrShareNotEnough
+	}
+
+	secret, err := shamir.Combine(points)
+	if err != nil {
+		return nil, err
+	}
+
+	var xprv chainkd.XPrv
+	copy(xprv[:], secret)
+	for i := range secret {
+		secret[i] = 0
+	}
+	defer func() {
+		for i := range xprv {
+			xprv[i] = 0
+		}
+	}()
+
+	if h.cache.hasKey(xprv.XPub()) {
+		return nil, ErrDuplicateKey
+	}
+
+	xpub, err := h.createKeyFromXPrv(normalizedAlias, auth, xprv)
+	if err != nil {
+		return nil, err
+	}
+
+	h.cache.add(*xpub)
+	return xpub, nil
+}
@@ -0,0 +1,69 @@
+package pseudohsm
+
+import (
+	"testing"
+
+	"github.com/bytom/bytom/errors"
+	mnem "github.com/bytom/bytom/wallet/mnemonic"
+)
+
+func TestKeyShares(t *testing.T) {
+	hsm, _ := New(dirPath)
+	xpub, _, err := hsm.XCreate("share_key", "password", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := hsm.ExportKeyShares(xpub.XPub, "password", 1, 3, "en"); err != ErrShareThreshold {
+		t.Fatalf("export 1 of 3 shares got error %v want %v", err, ErrShareThreshold)
+	}
+
+	shares, err := hsm.ExportKeyShares(xpub.XPub, "password", 2, 3, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(shares) != 3 {
+		t.Fatalf("got %d shares want 3", len(shares))
+	}
+
+	if _, err := hsm.ImportKeyFromShares("restored", "password", shares[:1], "en"); err != ErrShareNotEnough {
+		t.Fatalf("import from one share got error %v want %v", err, ErrShareNotEnough)
+	}
+
+	if _, err := hsm.ImportKeyFromShares("restored", "password", []string{shares[1], shares[1]}, "en"); err != ErrShareNotEnough {
+		t.Fatalf("import from duplicated shares got error %v want %v", err, ErrShareNotEnough)
+	}
+
+	if _, err := hsm.ImportKeyFromShares("restored", "password", shares[1:], "en"); errors.Root(err) != ErrDuplicateKey {
+		t.Fatalf("import existing key got error %v want %v", err, ErrDuplicateKey)
+	}
+
+	otherShares, err := hsm.ExportKeyShares(xpub.XPub, "password", 2, 3, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := hsm.XDelete(xpub.XPub, "password"); err != nil {
+		t.Fatal(err)
+	}
+
+	share, _ := mnem.ParseShareMnemonic(shares[0], "en")
+	otherShare, _ := mnem.ParseShareMnemonic(otherShares[1], "en")
+	if _, err := hsm.ImportKeyFromShares("restored", "password", []string{shares[0], otherShares[1]}, "en"); share.ID != otherShare.ID && err != ErrShareMismatch {
+		t.Fatalf("import mixed share sets got error %v want %v", err, ErrShareMismatch)
+	}
+
+	restored, err := hsm.ImportKeyFromShares("restored", "new_password", []string{shares[2], shares[0]}, "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if restored.XPub != xpub.XPub {
+		t.Fatalf("restored xpub got %x want %x", restored.XPub, xpub.XPub)
+	}
+
+	if err := hsm.XDelete(restored.XPub, "new_password"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,180 @@
+package pseudohsm
+
+import (
+	"sort"
+	"time"
+
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+const (
+	// ScopeTransaction allows the unlocked key to sign transactions
+	ScopeTransaction = "transaction"
+	// ScopeMessage allows the unlocked key to sign messages
+	ScopeMessage = "message"
+
+	// MaxUnlockTimeout is the longest time a key can stay unlocked
+	MaxUnlockTimeout = 24 * time.Hour
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrKeyLocked     = errors.New("key is locked")
+	ErrUnlockScope   = errors.New("invalid unlock scope")
+	ErrUnlockTimeout = errors.New("invalid unlock timeout")
+	ErrScopeDenied   = errors.New("unlocked key is not allowed for this operation")
+)
+
+var unlockScopes = map[string]bool{
+	ScopeTransaction: true,
+	ScopeMessage:     true,
+}
+
+// UnlockedKey is the public status of an unlocked key
+type UnlockedKey struct {
+	XPub   chainkd.XPub `json:"xpub"`
+	Scopes []string     `json:"scopes"`
+	Expiry time.Time    `json:"expiry"`
+}
+
+type unlockedKey struct {
+	xprv   *chainkd.XPrv
+	scopes map[string]bool
+	expiry time.Time
+	timer  *time.Timer
+}
+
+// zero wipes the private key and releases its locked memory
+func (k *unlockedKey) zero() {
+	k.timer.Stop()
+	for i := range k.xprv {
+		k.xprv[i] = 0
+	}
+	munlockKey(k.xprv[:])
+}
+
+// Unlock decrypts the key once and holds it in memory for the timeout, the
+// unlocked key only signs for the given scopes. Unlocking an unlocked key
+// replaces its timeout and scopes.
+func (h *HSM) Unlock(xpub chainkd.XPub, auth string, timeout time.Duration, scopes []string) (time.Time, error) {
+	if timeout <= 0 || timeout > MaxUnlockTimeout {
+		return time.Time{}, errors.WithDetailf(ErrUnlockTimeout, "timeout must be in (0, %s]", MaxUnlockTimeout)
+	}
+
+	if len(scopes) == 0 {
+		return time.Time{}, errors.WithDetail(ErrUnlockScope, "at least one scope is required")
+	}
+
+	scopeSet := map[string]bool{}
+	for _, scope := range scopes {
+		if !unlockScopes[scope] {
+			return time.Time{}, errors.WithDetailf(ErrUnlockScope, "scope %s", scope)
+		}
+		scopeSet[scope] = true
+	}
+
+	xprv, err := h.LoadChainKDKey(xpub, auth)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	key := &unlockedKey{xprv: new(chainkd.XPrv), scopes: scopeSet, expiry: time.Now().Add(timeout)}
+	mlockKey(key.xprv[:])
+	*key.xprv = xprv
+	for i := range xprv {
+		xprv[i] = 0
+	}
+
+	h.unlockMu.Lock()
+	defer h.unlockMu.Unlock()
+
+	if old, ok := h.unlocked[xpub]; ok {
+		old.zero()
+	}
+
+	key.timer = time.AfterFunc(timeout, func() { h.expire(xpub, key) })
+	h.unlocked[xpub] = key
+	return key.expiry, nil
+}
+
+func (h *HSM) expire(xpub chainkd.XPub, key *unlockedKey) {
+	h.unlockMu.Lock()
+	defer h.unlockMu.Unlock()
+
+	if h.unlocked[xpub] == key {
+		delete(h.unlocked, xpub)
+		key.zero()
+	}
+}
+
+// Lock wipes the unlocked key from memory
+func (h *HSM) Lock(xpub chainkd.XPub) error {
+	h.unlockMu.Lock()
+	defer h.unlockMu.Unlock()
+
+	key, ok := h.unlocked[xpub]
+	if !ok {
+		return ErrKeyLocked
+	}
+
+	delete(h.unlocked, xpub)
+	key.zero()
+	return nil
+}
+
+// ListUnlockedKeys returns the keys currently unlocked
+func (h *HSM) ListUnlockedKeys() []UnlockedKey {
+	h.unlockMu.Lock()
+	defer h.unlockMu.Unlock()
+
+	keys := []UnlockedKey{}
+	for xpub, key := range h.unlocked {
+		unlocked := UnlockedKey{XPub: xpub, Expiry: key.expiry}
+		for scope := range key.scopes {
+			unlocked.Scopes = append(unlocked.Scopes, scope)
+		}
+		sort.Strings(unlocked.Scopes)
+		keys = append(keys, unlocked)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Expiry.Before(keys[j].Expiry) })
+	return keys
+}
+
+// derivedUnlocked returns the derived private key of the unlocked key when
+// the key is unlocked for the scope, the caller must zero it after use
+func (h *HSM) derivedUnlocked(xpub chainkd.XPub, path [][]byte, scope string) (chainkd.XPrv, error) {
+	h.unlockMu.Lock()
+	defer h.unlockMu.Unlock()
+
+	key, ok := h.unlocked[xpub]
+	if !ok || time.Now().After(key.expiry) {
+		return chainkd.XPrv{}, ErrKeyLocked
+	}
+
+	if !key.scopes[scope] {
+		return chainkd.XPrv{}, errors.WithDetailf(ErrScopeDenied, "scope %s", scope)
+	}
+
+	xprv := *key.xprv
+	if len(path) > 0 {
+		xprv = xprv.Derive(path)
+	}
+	return xprv, nil
+}
+
+// XSignUnlocked signs the msg with the unlocked key when the key is unlocked
+// for the scope, the KDF is not run again
+func (h *HSM) XSignUnlocked(xpub chainkd.XPub, path [][]byte, msg []byte, scope string) ([]byte, error) {
+	xprv, err := h.derivedUnlocked(xpub, path, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := xprv.Sign(msg)
+	for i := range xprv {
+		xprv[i] = 0
+	}
+	return sig, nil
+}
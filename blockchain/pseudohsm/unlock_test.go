@@ -0,0 +1,106 @@
+package pseudohsm
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/bytom/bytom/errors"
+)
+
+func TestUnlockKey(t *testing.T) {
+	hsm, _ := New(dirPath)
+	xpub, _, err := hsm.XCreate("unlock", "password", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hsm.XDelete(xpub.XPub, "password")
+
+	msg := []byte("message")
+	path := [][]byte{{0x2c, 0, 0, 0}}
+	if _, err := hsm.XSignUnlocked(xpub.XPub, path, msg, ScopeTransaction); err != ErrKeyLocked {
+		t.Fatalf("sign with locked key got error %v want %v", err, ErrKeyLocked)
+	}
+
+	cases := []struct {
+		timeout time.Duration
+		scopes  []string
+		err     error
+	}{
+		{timeout: 0, scopes: []string{ScopeTransaction}, err: ErrUnlockTimeout},
+		{timeout: MaxUnlockTimeout + time.Second, scopes: []string{ScopeTransaction}, err: ErrUnlockTimeout},
+		{timeout: time.Minute, scopes: nil, err: ErrUnlockScope},
+		{timeout: time.Minute, scopes: []string{"block"}, err: ErrUnlockScope},
+	}
+	for i, c := range cases {
+		if _, err := hsm.Unlock(xpub.XPub, "password", c.timeout, c.scopes); errors.Root(err) != c.err {
+			t.Errorf("case %d: got error %v want %v", i, err, c.err)
+		}
+	}
+
+	if _, err := hsm.Unlock(xpub.XPub, "wrong", time.Minute, []string{ScopeTransaction}); err != ErrLoadKey {
+		t.Fatalf("unlock with wrong password got error %v want %v", err, ErrLoadKey)
+	}
+
+	if _, err := hsm.Unlock(xpub.XPub, "password", time.Minute, []string{ScopeTransaction}); err != nil {
+		t.Fatal(err)
+	}
+
+	want, err := hsm.XSign(xpub.XPub, path, msg, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := hsm.XSignUnlocked(xpub.XPub, path, msg, ScopeTransaction)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("unlocked signature got %x want %x", got, want)
+	}
+
+	if _, err := hsm.XSignUnlocked(xpub.XPub, path, msg, ScopeMessage); errors.Root(err) != ErrScopeDenied {
+		t.Errorf("sign out of scope got error %v want %v", err, ErrScopeDenied)
+	}
+
+	if _, err := hsm.Unlock(xpub.XPub, "password", time.Minute, []string{ScopeTransaction, ScopeMessage}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := hsm.XSignUnlocked(xpub.XPub, path, msg, ScopeMessage); err != nil {
+		t.Errorf("sign message after unlock for message got error %v", err)
+	}
+
+	if keys := hsm.ListUnlockedKeys(); len(keys) != 1 || keys[0].XPub != xpub.XPub {
+		t.Errorf("list unlocked keys got %v", keys)
+	}
+
+	if err := hsm.Lock(xpub.XPub); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := hsm.XSignUnlocked(xpub.XPub, path, msg, ScopeTransaction); err != ErrKeyLocked {
+		t.Errorf("sign after lock got error %v want %v", err, ErrKeyLocked)
+	}
+
+	if _, err := hsm.Unlock(xpub.XPub, "password", 50*time.Millisecond, []string{ScopeMessage}); err != nil {
+		t.Fatal(err)
+	}
+
+	hsm.unlockMu.Lock()
+	key := hsm.unlocked[xpub.XPub]
+	hsm.unlockMu.Unlock()
+	time.Sleep(200 * time.Millisecond)
+	if _, err := hsm.XSignUnlocked(xpub.XPub, path, msg, ScopeMessage); err != ErrKeyLocked {
+		t.Errorf("sign after expiry got error %v want %v", err, ErrKeyLocked)
+	}
+
+	hsm.unlockMu.Lock()
+	defer hsm.unlockMu.Unlock()
+	for _, b := range key.xprv {
+		if b != 0 {
+			t.Fatal("expired key is not zeroed")
+		}
+	}
+}
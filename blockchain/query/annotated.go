@@ -19,6 +19,8 @@ type AnnotatedTx struct {
 	Inputs                 []*AnnotatedInput  `json:"inputs"`
 	Outputs                []*AnnotatedOutput `json:"outputs"`
 	Size                   uint64             `json:"size"`
+	Labels                 []string           `json:"labels,omitempty"`
+	Note                   string             `json:"note,omitempty"`
 }
 
 //AnnotatedInput means an annotated transaction input.
@@ -32,6 +34,7 @@ type AnnotatedInput struct {
 	ControlProgram   chainjson.HexBytes   `json:"control_program,omitempty"`
 	Address          string               `json:"address,omitempty"`
 	SpentOutputID    *bc.Hash             `json:"spent_output_id,omitempty"`
+	Sequence         uint64               `json:"sequence,omitempty"`
 	AccountID        string               `json:"account_id,omitempty"`
 	AccountAlias     string               `json:"account_alias,omitempty"`
 	Arbitrary        chainjson.HexBytes   `json:"arbitrary,omitempty"`
@@ -63,6 +66,15 @@ type AnnotatedOutput struct {
 
 	// assign value when output is not retirement type
 	StateData []string `json:"state_data,omitempty"`
+
+	// user metadata attached by the wallet
+	Labels []string `json:"labels,omitempty"`
+	Note   string   `json:"note,omitempty"`
+
+	// memo decrypted by the receiving wallet, EncryptedMemo marks a memo the
+	// wallet can't decrypt until the key is unlocked for memo
+	Memo          string `json:"memo,omitempty"`
+	EncryptedMemo bool   `json:"encrypted_memo,omitempty"`
 }
 
 //AnnotatedAccount means an annotated account.
@@ -98,18 +110,20 @@ type AnnotatedSigner struct {
 
 //AnnotatedUTXO means an annotated utxo.
 type AnnotatedUTXO struct {
-	Alias               string `json:"account_alias"`
-	OutputID            string `json:"id"`
-	AssetID             string `json:"asset_id"`
-	AssetAlias          string `json:"asset_alias"`
-	Amount              uint64 `json:"amount"`
-	AccountID           string `json:"account_id"`
-	Address             string `json:"address"`
-	ControlProgramIndex uint64 `json:"control_program_index"`
-	Program             string `json:"program"`
-	SourceID            string `json:"source_id"`
-	SourcePos           uint64 `json:"source_pos"`
-	ValidHeight         uint64 `json:"valid_height"`
-	Change              bool   `json:"change"`
-	DeriveRule          uint8  `json:"derive_rule"`
+	Alias               string   `json:"account_alias"`
+	OutputID            string   `json:"id"`
+	AssetID             string   `json:"asset_id"`
+	AssetAlias          string   `json:"asset_alias"`
+	Amount              uint64   `json:"amount"`
+	AccountID           string   `json:"account_id"`
+	Address             string   `json:"address"`
+	ControlProgramIndex uint64   `json:"control_program_index"`
+	Program             string   `json:"program"`
+	SourceID            string   `json:"source_id"`
+	SourcePos           uint64   `json:"source_pos"`
+	ValidHeight         uint64   `json:"valid_height"`
+	Change              bool     `json:"change"`
+	DeriveRule          uint8    `json:"derive_rule"`
+	Labels              []string `json:"labels,omitempty"`
+	Note                string   `json:"note,omitempty"`
 }
@@ -0,0 +1,293 @@
+package signer
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	cfg "github.com/bytom/bytom/config"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/crypto/frost"
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/errors"
+)
+
+const (
+	logModule = "signer"
+
+	// frostSessionTimeout is how long a holder keeps the nonces of a
+	// commitment waiting for the sign request
+	frostSessionTimeout = 30 * time.Second
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrFrostSession = errors.New("threshold signing session not found or expired")
+	ErrFrostHolders = errors.New("not enough threshold signing holders available")
+)
+
+// FrostGroup is the public file of a threshold validator key, XPub is the
+// validator key made of the group key and a chain code
+type FrostGroup struct {
+	XPub    chainkd.XPub            `json:"xpub"`
+	Package *frost.PublicKeyPackage `json:"package"`
+}
+
+// FrostShareFile is the secret file of one threshold key share holder
+type FrostShareFile struct {
+	XPub  chainkd.XPub    `json:"xpub"`
+	Share *frost.KeyShare `json:"share"`
+}
+
+type frostCommitResponse struct {
+	Session    string            `json:"session"`
+	Commitment *frost.Commitment `json:"commitment"`
+}
+
+type frostSignRequest struct {
+	Session     string                    `json:"session"`
+	Message     chainjson.HexBytes        `json:"message"`
+	Request     *cfg.ValidatorSignRequest `json:"request"`
+	Commitments []*frost.Commitment       `json:"commitments"`
+}
+
+// LoadFrostGroup reads the public group file
+func LoadFrostGroup(file string) (*FrostGroup, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &FrostGroup{}
+	if err := json.Unmarshal(data, group); err != nil {
+		return nil, err
+	}
+
+	if group.Package == nil || string(group.XPub.PublicKey()) != string(group.Package.GroupKey[:]) {
+		return nil, errors.New("xpub of the threshold group does not match the group key")
+	}
+	return group, nil
+}
+
+// LoadFrostShare reads the secret share file of a holder
+func LoadFrostShare(file string) (*FrostShareFile, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	shareFile := &FrostShareFile{}
+	if err := json.Unmarshal(data, shareFile); err != nil {
+		return nil, err
+	}
+
+	if shareFile.Share == nil {
+		return nil, errors.New("no key share in the share file")
+	}
+	return shareFile, nil
+}
+
+type frostSession struct {
+	nonces *frost.Nonces
+	expiry time.Time
+}
+
+// FrostHolder serves the two signing rounds with one key share, every nonce
+// is used for at most one signature
+type FrostHolder struct {
+	share *frost.KeyShare
+	token string
+	mux   *http.ServeMux
+
+	mu       sync.Mutex
+	sessions map[string]*frostSession
+	guard    *signGuard
+}
+
+// NewFrostHolder returns the protocol handler of the key share holder,
+// requests must carry the token when it is not empty. The last signed block
+// and verification heights are kept in the state file.
+//
+//	POST /frost/commit  {}                                                     -> {"session", "commitment"}
+//	POST /frost/sign    {"session", "message": hex, "request", "commitments"}  -> {"id", "share"}
+func NewFrostHolder(share *frost.KeyShare, token, stateFile string) (*FrostHolder, error) {
+	guard, err := newSignGuard(stateFile)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &FrostHolder{share: share, token: token, mux: http.NewServeMux(), sessions: map[string]*frostSession{}, guard: guard}
+	h.mux.HandleFunc("/frost/commit", h.commit)
+	h.mux.HandleFunc("/frost/sign", h.sign)
+	return h, nil
+}
+
+// ServeHTTP authenticates the request the same way as the signing daemon
+func (h *FrostHolder) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if authorize(w, req, h.token) {
+		h.mux.ServeHTTP(w, req)
+	}
+}
+
+func (h *FrostHolder) commit(w http.ResponseWriter, req *http.Request) {
+	nonces, commitment, err := frost.Commit(h.share)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+
+	session := hex.EncodeToString(id[:])
+	now := time.Now()
+	h.mu.Lock()
+	for key, s := range h.sessions {
+		if now.After(s.expiry) {
+			delete(h.sessions, key)
+		}
+	}
+	h.sessions[session] = &frostSession{nonces: nonces, expiry: now.Add(frostSessionTimeout)}
+	h.mu.Unlock()
+
+	writeResult(w, &frostCommitResponse{Session: session, Commitment: commitment})
+}
+
+func (h *FrostHolder) sign(w http.ResponseWriter, req *http.Request) {
+	in := &frostSignRequest{}
+	if err := json.NewDecoder(req.Body).Decode(in); err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	// the session is dropped before signing so that the nonces can never
+	// sign a second message
+	h.mu.Lock()
+	session, ok := h.sessions[in.Session]
+	delete(h.sessions, in.Session)
+	h.mu.Unlock()
+	if !ok || time.Now().After(session.expiry) {
+		writeError(w, http.StatusNotFound, codeSession, ErrFrostSession.Error())
+		return
+	}
+
+	h.mu.Lock()
+	msg, signed, err := h.guard.requestMessage(in.Request)
+	if err == nil && !bytes.Equal(msg, in.Message) {
+		err = errors.WithDetail(ErrValidatorMessage, "message does not match the request")
+	}
+
+	if err != nil {
+		h.mu.Unlock()
+		writeError(w, http.StatusBadRequest, guardErrorCode(err), err.Error())
+		return
+	}
+
+	if err := h.guard.save(signed); err != nil {
+		h.mu.Unlock()
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+		return
+	}
+	h.mu.Unlock()
+
+	share, err := frost.Sign(h.share, session.nonces, msg, in.Commitments)
+	log.WithFields(log.Fields{"module": logModule, "client": clientName(req), "id": h.share.ID, "message": hex.EncodeToString(in.Message), "err": err}).Info("threshold signature share")
+	if err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+	writeResult(w, share)
+}
+
+// FrostCoordinator drives the holders of a threshold key to sign
+type FrostCoordinator struct {
+	group   *FrostGroup
+	holders []*RemoteSigner
+}
+
+// NewFrostCoordinator returns the coordinator of the holders listening on
+// the urls, they share the token and the http client
+func NewFrostCoordinator(group *FrostGroup, urls []string, token string, client *http.Client) *FrostCoordinator {
+	c := &FrostCoordinator{group: group}
+	for _, url := range urls {
+		c.holders = append(c.holders, NewRemoteSigner(strings.TrimSpace(url), token, client))
+	}
+	return c
+}
+
+// XPub returns the validator key of the threshold group
+func (c *FrostCoordinator) XPub() chainkd.XPub {
+	return c.group.XPub
+}
+
+// Sign collects the commitments of the first threshold reachable holders,
+// then their signature shares, and aggregates the ed25519 signature. The
+// holders check the message is the hash of the requested block or
+// verification.
+func (c *FrostCoordinator) Sign(msg []byte, req *cfg.ValidatorSignRequest) ([]byte, error) {
+	type committed struct {
+		holder  *RemoteSigner
+		session string
+	}
+
+	signers := []*committed{}
+	commitments := []*frost.Commitment{}
+	for _, holder := range c.holders {
+		if len(signers) == c.group.Package.Threshold {
+			break
+		}
+
+		resp := &frostCommitResponse{}
+		if err := holder.call("/frost/commit", struct{}{}, resp); err != nil {
+			log.WithFields(log.Fields{"module": logModule, "holder": holder.url, "err": err}).Warning("threshold holder commit failed")
+			continue
+		}
+
+		signers = append(signers, &committed{holder: holder, session: resp.Session})
+		commitments = append(commitments, resp.Commitment)
+	}
+
+	if len(signers) < c.group.Package.Threshold {
+		return nil, errors.WithDetailf(ErrFrostHolders, "got %d holders want %d", len(signers), c.group.Package.Threshold)
+	}
+
+	shares := make([]*frost.SignatureShare, len(signers))
+	errs := make([]error, len(signers))
+	var wg sync.WaitGroup
+	for i, s := range signers {
+		wg.Add(1)
+		go func(i int, s *committed) {
+			defer wg.Done()
+			shares[i] = &frost.SignatureShare{}
+			errs[i] = s.holder.call("/frost/sign", &frostSignRequest{Session: s.session, Message: msg, Request: req, Commitments: commitments}, shares[i])
+		}(i, s)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, errors.Wrapf(err, "threshold holder %s", signers[i].holder.url)
+		}
+	}
+
+	sig, err := frost.Aggregate(c.group.Package, msg, commitments, shares)
+	if err != nil {
+		return nil, err
+	}
+
+	if !c.group.XPub.Verify(msg, sig) {
+		return nil, errors.New("aggregated threshold signature does not verify")
+	}
+	return sig, nil
+}
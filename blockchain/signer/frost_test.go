@@ -0,0 +1,126 @@
+package signer
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	cfg "github.com/bytom/bytom/config"
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/crypto/ed25519/ecmath"
+	"github.com/bytom/bytom/crypto/frost"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/protocol/casper"
+)
+
+func TestFrostCoordinator(t *testing.T) {
+	xprv, err := chainkd.NewXPrv(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var secret ecmath.Scalar
+	copy(secret[:], xprv[:32])
+	pkg, shares, err := frost.SplitKey(&secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dirPath, err := ioutil.TempDir(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	group := &FrostGroup{XPub: xprv.XPub(), Package: pkg}
+	urls := []string{"http://127.0.0.1:1"}
+	holders := []*FrostHolder{}
+	for _, share := range shares {
+		holder, err := NewFrostHolder(share, "token", filepath.Join(dirPath, fmt.Sprintf("state-%d.json", share.ID)))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		server := httptest.NewServer(holder)
+		defer server.Close()
+
+		holders = append(holders, holder)
+		urls = append(urls, server.URL)
+	}
+
+	header := &types.BlockHeader{Version: 1, Height: 10, Timestamp: 1000}
+	hash := header.Hash()
+	msg := hash.Bytes()
+	req := &cfg.ValidatorSignRequest{BlockHeader: header}
+	sig, err := NewFrostCoordinator(group, urls, "token", nil).Sign(msg, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !xprv.XPub().Verify(msg, sig) {
+		t.Error("threshold signature does not verify with the validator xpub")
+	}
+
+	if _, err := NewFrostCoordinator(group, urls[:2], "token", nil).Sign(msg, req); errors.Root(err) != ErrFrostHolders {
+		t.Errorf("sign with one holder got error %v want %v", err, ErrFrostHolders)
+	}
+
+	if _, err := NewFrostCoordinator(group, urls, "bad", nil).Sign(msg, req); errors.Root(err) != ErrFrostHolders {
+		t.Errorf("sign with bad token got error %v want %v", err, ErrFrostHolders)
+	}
+
+	if _, err := NewFrostCoordinator(group, urls, "token", nil).Sign([]byte("bare hash"), req); errors.Root(err) != ErrValidatorMessage {
+		t.Errorf("sign a message not of the block got error %v want %v", err, ErrValidatorMessage)
+	}
+
+	forked := &types.BlockHeader{Version: 1, Height: 10, Timestamp: 2000}
+	forkedHash := forked.Hash()
+	if _, err := NewFrostCoordinator(group, urls, "token", nil).Sign(forkedHash.Bytes(), &cfg.ValidatorSignRequest{BlockHeader: forked}); errors.Root(err) != ErrDoubleSign {
+		t.Errorf("sign another block at the same height got error %v want %v", err, ErrDoubleSign)
+	}
+
+	restarted, err := NewFrostHolder(shares[0], "token", filepath.Join(dirPath, fmt.Sprintf("state-%d.json", shares[0].ID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := restarted.guard.requestMessage(&cfg.ValidatorSignRequest{BlockHeader: forked}); errors.Root(err) != ErrDoubleSign {
+		t.Errorf("restarted holder sign another block got error %v want %v", err, ErrDoubleSign)
+	}
+
+	verification := &cfg.Verification{SourceHash: bc.Hash{V0: 1}, TargetHash: bc.Hash{V0: 2}, SourceHeight: 0, TargetHeight: consensus.ActiveNetParams.BlocksOfEpoch}
+	verificationMsg, err := casper.VerificationMessage(verification.SourceHash, verification.TargetHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewFrostCoordinator(group, urls, "token", nil).Sign(verificationMsg, &cfg.ValidatorSignRequest{Verification: verification}); err != nil {
+		t.Errorf("sign verification got error %v", err)
+	}
+
+	remote := NewRemoteSigner(urls[1], "token", nil)
+	commit := &frostCommitResponse{}
+	if err := remote.call("/frost/commit", struct{}{}, commit); err != nil {
+		t.Fatal(err)
+	}
+
+	signReq := &frostSignRequest{Session: commit.Session, Message: msg, Request: req, Commitments: []*frost.Commitment{commit.Commitment}}
+	if err := remote.call("/frost/sign", signReq, &frost.SignatureShare{}); err == nil {
+		t.Error("sign below the threshold should fail")
+	}
+
+	if err := remote.call("/frost/sign", signReq, &frost.SignatureShare{}); errors.Root(err) != ErrFrostSession {
+		t.Errorf("reuse the session got error %v want %v", err, ErrFrostSession)
+	}
+
+	if _, ok := holders[0].sessions[commit.Session]; ok {
+		t.Error("holder kept the nonces of the used session")
+	}
+}
@@ -0,0 +1,118 @@
+package signer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	cfg "github.com/bytom/bytom/config"
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/casper"
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrValidatorMessage = errors.New("validator signing message is not a block or verification hash")
+	ErrDoubleSign       = errors.New("signer refuses to sign twice at the same height")
+)
+
+// signedState is the last block and casper verification signed by a
+// validator key
+type signedState struct {
+	BlockHeight  uint64  `json:"block_height"`
+	BlockHash    bc.Hash `json:"block_hash"`
+	TargetHeight uint64  `json:"target_height"`
+	SourceHash   bc.Hash `json:"source_hash"`
+	TargetHash   bc.Hash `json:"target_hash"`
+}
+
+// signGuard is the double sign guard of a validator key, the signed state is
+// persisted before the signature leaves the signer
+type signGuard struct {
+	file   string
+	signed *signedState
+}
+
+// newSignGuard loads the signed state kept in the file, a missing file is
+// the state of a key which never signed
+func newSignGuard(file string) (*signGuard, error) {
+	g := &signGuard{file: file, signed: &signedState{}}
+	data, err := ioutil.ReadFile(file)
+	if err == nil {
+		if err := json.Unmarshal(data, g.signed); err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return g, nil
+}
+
+// requestMessage rebuilds the message from the block header or the casper
+// verification, the returned state is the signed state after signing it
+func (g *signGuard) requestMessage(req *cfg.ValidatorSignRequest) ([]byte, *signedState, error) {
+	signed := *g.signed
+	switch {
+	case req == nil:
+		return nil, nil, errors.WithDetail(ErrValidatorMessage, "no block header or verification")
+
+	case req.BlockHeader != nil:
+		hash := req.BlockHeader.Hash()
+		if req.BlockHeader.Height < signed.BlockHeight || (req.BlockHeader.Height == signed.BlockHeight && hash != signed.BlockHash) {
+			return nil, nil, errors.WithDetailf(ErrDoubleSign, "block %d, last signed block %d", req.BlockHeader.Height, signed.BlockHeight)
+		}
+
+		signed.BlockHeight, signed.BlockHash = req.BlockHeader.Height, hash
+		return hash.Bytes(), &signed, nil
+
+	case req.Verification != nil:
+		v := req.Verification
+		blocksOfEpoch := consensus.ActiveNetParams.BlocksOfEpoch
+		if v.SourceHeight >= v.TargetHeight || v.SourceHeight%blocksOfEpoch != 0 || v.TargetHeight%blocksOfEpoch != 0 {
+			return nil, nil, errors.WithDetailf(ErrValidatorMessage, "verification from %d to %d", v.SourceHeight, v.TargetHeight)
+		}
+
+		if v.TargetHeight < signed.TargetHeight || (v.TargetHeight == signed.TargetHeight && (v.SourceHash != signed.SourceHash || v.TargetHash != signed.TargetHash)) {
+			return nil, nil, errors.WithDetailf(ErrDoubleSign, "verification target %d, last signed target %d", v.TargetHeight, signed.TargetHeight)
+		}
+
+		msg, err := casper.VerificationMessage(v.SourceHash, v.TargetHash)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		signed.TargetHeight, signed.SourceHash, signed.TargetHash = v.TargetHeight, v.SourceHash, v.TargetHash
+		return msg, &signed, nil
+	}
+	return nil, nil, errors.WithDetail(ErrValidatorMessage, "no block header or verification")
+}
+
+// save persists the signed state, a signer restarted later still refuses to
+// sign below it
+func (g *signGuard) save(signed *signedState) error {
+	data, err := json.Marshal(signed)
+	if err != nil {
+		return err
+	}
+
+	tmpFile := g.file + ".tmp"
+	if err := ioutil.WriteFile(tmpFile, data, 0600); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmpFile, g.file); err != nil {
+		return err
+	}
+
+	g.signed = signed
+	return nil
+}
+
+func guardErrorCode(err error) string {
+	if errors.Root(err) == ErrDoubleSign {
+		return codeDoubleSign
+	}
+	return codeMessage
+}
@@ -0,0 +1,313 @@
+package signer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytom/bytom/blockchain/pseudohsm"
+	"github.com/bytom/bytom/blockchain/signers"
+	cfg "github.com/bytom/bytom/config"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+const rateLimitWindow = time.Minute
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrPolicyFormat   = errors.New("invalid signer policy")
+	ErrKeyNotAllowed  = errors.New("key is not allowed to sign by signer policy")
+	ErrPathNotAllowed = errors.New("derivation path is not allowed by signer policy")
+	ErrRateLimit      = errors.New("signing rate limit exceeded")
+)
+
+// KeyRule is the signing policy of one key. An allowed path is written as
+// "m/<hex>/<hex>..." where "*" matches any single element, "m" alone allows
+// signing with the root key.
+type KeyRule struct {
+	XPub         chainkd.XPub `json:"xpub"`
+	AllowedPaths []string     `json:"allowed_paths"`
+	// RateLimit is the max number of signatures per minute, 0 means no limit
+	RateLimit int `json:"rate_limit"`
+	// StateFile keeps the last block and verification signed by the root
+	// key, it makes the key serve the validator signing requests
+	StateFile string `json:"state_file"`
+	// PasswordFile unlocks the key for the validator signing requests only,
+	// it makes block proposing unattended and needs the state file
+	PasswordFile string `json:"password_file"`
+}
+
+// PolicyConfig is the json policy file of the signing daemon
+type PolicyConfig struct {
+	Keys []*KeyRule `json:"keys"`
+}
+
+// LoadPolicyConfig reads the policy file
+func LoadPolicyConfig(file string) (*PolicyConfig, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	config := &PolicyConfig{}
+	if err := json.Unmarshal(data, config); err != nil {
+		return nil, errors.WithDetail(ErrPolicyFormat, err.Error())
+	}
+	return config, nil
+}
+
+type keyPolicy struct {
+	rule     *KeyRule
+	paths    [][]string
+	password string
+
+	// guardMu serializes the validator signing of the key, the guard is
+	// checked, signed and saved as one step
+	guardMu sync.Mutex
+	guard   *signGuard
+
+	windowStart time.Time
+	count       int
+}
+
+// PolicySigner only signs with the keys and paths allowed by the policy
+type PolicySigner struct {
+	mu       sync.Mutex
+	signer   Signer
+	policies map[chainkd.XPub]*keyPolicy
+	now      func() time.Time
+}
+
+func parsePathPattern(pattern string) ([]string, error) {
+	elems := strings.Split(pattern, "/")
+	if elems[0] != "m" {
+		return nil, errors.WithDetailf(ErrPolicyFormat, "path %s must start with m", pattern)
+	}
+
+	for _, elem := range elems[1:] {
+		if elem == "*" {
+			continue
+		}
+
+		if _, err := hex.DecodeString(elem); err != nil || elem == "" {
+			return nil, errors.WithDetailf(ErrPolicyFormat, "invalid path element %s", elem)
+		}
+	}
+	return elems[1:], nil
+}
+
+// NewPolicySigner wraps the signer with the key rules
+func NewPolicySigner(signer Signer, config *PolicyConfig) (*PolicySigner, error) {
+	s := &PolicySigner{signer: signer, policies: map[chainkd.XPub]*keyPolicy{}, now: time.Now}
+	for _, rule := range config.Keys {
+		policy := &keyPolicy{rule: rule}
+		for _, pattern := range rule.AllowedPaths {
+			path, err := parsePathPattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			policy.paths = append(policy.paths, path)
+		}
+
+		if rule.PasswordFile != "" && rule.StateFile == "" {
+			return nil, errors.WithDetailf(ErrPolicyFormat, "password_file of key %s needs state_file", rule.XPub.String())
+		}
+
+		if rule.StateFile != "" {
+			guard, err := newSignGuard(rule.StateFile)
+			if err != nil {
+				return nil, err
+			}
+
+			policy.guard = guard
+		}
+
+		if rule.PasswordFile != "" {
+			password, err := ioutil.ReadFile(rule.PasswordFile)
+			if err != nil {
+				return nil, err
+			}
+
+			policy.password = strings.TrimSpace(string(password))
+		}
+		s.policies[rule.XPub] = policy
+	}
+	return s, nil
+}
+
+// NewPathPolicySigner wraps the signer of the arbitrary path api, a pattern
+// reaching the key paths of the wallet is refused so that a signed message
+// can never be a transaction signature of an account or an asset
+func NewPathPolicySigner(signer Signer, config *PolicyConfig) (*PolicySigner, error) {
+	for _, rule := range config.Keys {
+		for _, pattern := range rule.AllowedPaths {
+			path, err := parsePathPattern(pattern)
+			if err != nil {
+				return nil, err
+			}
+
+			if overlapsWalletPath(path) {
+				return nil, errors.WithDetailf(ErrPolicyFormat, "path %s overlaps the wallet key paths", pattern)
+			}
+		}
+	}
+	return NewPolicySigner(signer, config)
+}
+
+// overlapsWalletPath reports whether the pattern matches a path the wallet
+// signs with, m/<key space><key index>/<index> of the bip0032 asset and
+// account keys or m/44/153/<account>/<change>/<index> of the bip0044 keys
+func overlapsWalletPath(pattern []string) bool {
+	matches := func(elem string, value []byte) bool {
+		return elem == "*" || strings.ToLower(elem) == hex.EncodeToString(value)
+	}
+
+	switch len(pattern) {
+	case 2:
+		if pattern[0] == "*" {
+			return true
+		}
+
+		signerPath, _ := hex.DecodeString(pattern[0])
+		return len(signerPath) == 9 && (signerPath[0] == byte(signers.AssetKeySpace) || signerPath[0] == byte(signers.AccountKeySpace))
+	case 5:
+		return matches(pattern[0], signers.BIP44Purpose) && matches(pattern[1], signers.BTMCoinType)
+	}
+	return false
+}
+
+func (p *keyPolicy) allowPath(path [][]byte) bool {
+	for _, pattern := range p.paths {
+		if len(pattern) != len(path) {
+			continue
+		}
+
+		match := true
+		for i, elem := range pattern {
+			if elem != "*" && elem != hex.EncodeToString(path[i]) {
+				match = false
+				break
+			}
+		}
+
+		if match {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *keyPolicy) take(now time.Time) bool {
+	if p.rule.RateLimit == 0 {
+		return true
+	}
+
+	if now.Sub(p.windowStart) >= rateLimitWindow {
+		p.windowStart, p.count = now, 0
+	}
+
+	if p.count >= p.rule.RateLimit {
+		return false
+	}
+
+	p.count++
+	return true
+}
+
+// ListKeys returns the keys having a rule in the policy
+func (s *PolicySigner) ListKeys() ([]pseudohsm.XPub, error) {
+	keys, err := s.signer.ListKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	allowed := []pseudohsm.XPub{}
+	for _, key := range keys {
+		if _, ok := s.policies[key.XPub]; ok {
+			allowed = append(allowed, key)
+		}
+	}
+	return allowed, nil
+}
+
+// XSign signs the msg when the key, the path and the rate are allowed
+func (s *PolicySigner) XSign(xpub chainkd.XPub, path [][]byte, msg []byte, auth string) ([]byte, error) {
+	s.mu.Lock()
+	policy, ok := s.policies[xpub]
+	if !ok {
+		s.mu.Unlock()
+		return nil, ErrKeyNotAllowed
+	}
+
+	if !policy.allowPath(path) {
+		s.mu.Unlock()
+		return nil, ErrPathNotAllowed
+	}
+
+	if !policy.take(s.now()) {
+		s.mu.Unlock()
+		return nil, ErrRateLimit
+	}
+	s.mu.Unlock()
+
+	return s.signer.XSign(xpub, path, msg, auth)
+}
+
+// ValidatorSign signs the block or the casper verification of the request
+// with the root key unlocked by the password file. The message is rebuilt
+// from the request, and the key never signs twice at the same height.
+func (s *PolicySigner) ValidatorSign(xpub chainkd.XPub, req *cfg.ValidatorSignRequest) ([]byte, error) {
+	s.mu.Lock()
+	policy, ok := s.policies[xpub]
+	if !ok || policy.guard == nil {
+		s.mu.Unlock()
+		return nil, ErrKeyNotAllowed
+	}
+
+	if !policy.allowPath(nil) {
+		s.mu.Unlock()
+		return nil, ErrPathNotAllowed
+	}
+
+	if !policy.take(s.now()) {
+		s.mu.Unlock()
+		return nil, ErrRateLimit
+	}
+	s.mu.Unlock()
+
+	policy.guardMu.Lock()
+	defer policy.guardMu.Unlock()
+
+	msg, signed, err := policy.guard.requestMessage(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sig, err := s.signer.XSign(xpub, nil, msg, policy.password)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := policy.guard.save(signed); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// DeriveXPub derives the xpub when the key and the path are allowed
+func (s *PolicySigner) DeriveXPub(xpub chainkd.XPub, path [][]byte) (chainkd.XPub, error) {
+	policy, ok := s.policies[xpub]
+	if !ok {
+		return chainkd.XPub{}, ErrKeyNotAllowed
+	}
+
+	if !policy.allowPath(path) {
+		return chainkd.XPub{}, ErrPathNotAllowed
+	}
+	return s.signer.DeriveXPub(xpub, path)
+}
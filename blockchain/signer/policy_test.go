@@ -0,0 +1,197 @@
+package signer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bytom/bytom/blockchain/pseudohsm"
+	cfg "github.com/bytom/bytom/config"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc/types"
+)
+
+func TestPolicySigner(t *testing.T) {
+	dirPath, err := ioutil.TempDir(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	hsm, err := pseudohsm.New(dirPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	validator, _, err := hsm.XCreate("validator", "password", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, _, err := hsm.XCreate("other", "password", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	passwordFile := filepath.Join(dirPath, "password")
+	if err := ioutil.WriteFile(passwordFile, []byte("password\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config := &PolicyConfig{Keys: []*KeyRule{{
+		XPub:         validator.XPub,
+		AllowedPaths: []string{"m", "m/2c000000/*"},
+		RateLimit:    3,
+		StateFile:    filepath.Join(dirPath, "state.json"),
+		PasswordFile: passwordFile,
+	}}}
+	policySigner, err := NewPolicySigner(NewLocalSigner(hsm), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	policySigner.now = func() time.Time { return now }
+
+	server := NewServer(policySigner, "token")
+	audit := &bytes.Buffer{}
+	server.SetAuditLog(audit)
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+
+	remote := NewRemoteSigner(httpServer.URL, "token", nil)
+	keys, err := remote.ListKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 1 || keys[0].XPub != validator.XPub {
+		t.Fatalf("list keys got %v want only the validator key", keys)
+	}
+
+	cases := []struct {
+		xpub pseudohsm.XPub
+		path [][]byte
+		err  error
+	}{
+		{xpub: *validator, path: nil},
+		{xpub: *validator, path: [][]byte{{0x2c, 0, 0, 0}, {0x01, 0, 0, 0}}},
+		{xpub: *validator, path: [][]byte{{0x2d, 0, 0, 0}, {0x01, 0, 0, 0}}, err: ErrPathNotAllowed},
+		{xpub: *validator, path: [][]byte{{0x2c, 0, 0, 0}}, err: ErrPathNotAllowed},
+		{xpub: *other, path: nil, err: ErrKeyNotAllowed},
+		{xpub: *validator, path: nil},
+		{xpub: *validator, path: nil, err: ErrRateLimit},
+	}
+
+	for i, c := range cases {
+		if _, err := remote.XSign(c.xpub.XPub, c.path, []byte("block"), "password"); errors.Root(err) != c.err {
+			t.Errorf("case %d: got error %v want %v", i, err, c.err)
+		}
+	}
+
+	now = now.Add(time.Minute)
+	if _, err := remote.XSign(validator.XPub, nil, []byte("block"), "password"); err != nil {
+		t.Errorf("sign in the next minute got error %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(audit.String()), "\n")
+	if len(lines) != len(cases)+1 {
+		t.Fatalf("audit log got %d lines want %d", len(lines), len(cases)+1)
+	}
+
+	record := &AuditRecord{}
+	if err := json.Unmarshal([]byte(lines[2]), record); err != nil {
+		t.Fatal(err)
+	}
+
+	if record.Action != "sign" || record.XPub != validator.XPub.String() || strings.Join(record.Path, "/") != "2d000000/01000000" || record.Error == "" || record.MessageHash == "" {
+		t.Errorf("audit record got %+v", record)
+	}
+
+	if _, err := remote.XSign(validator.XPub, nil, []byte("block"), ""); errors.Root(err) != pseudohsm.ErrLoadKey {
+		t.Errorf("sign without password got error %v want %v", err, pseudohsm.ErrLoadKey)
+	}
+
+	header := &types.BlockHeader{Version: 1, Height: 10, Timestamp: 1000}
+	hash := header.Hash()
+	sig, err := remote.ValidatorSign(validator.XPub, &cfg.ValidatorSignRequest{BlockHeader: header})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !validator.XPub.Verify(hash.Bytes(), sig) {
+		t.Error("validator signature does not verify with the block hash")
+	}
+
+	now = now.Add(time.Minute)
+	forked := &types.BlockHeader{Version: 1, Height: 10, Timestamp: 2000}
+	if _, err := remote.ValidatorSign(validator.XPub, &cfg.ValidatorSignRequest{BlockHeader: forked}); errors.Root(err) != ErrDoubleSign {
+		t.Errorf("validator sign another block at the same height got error %v want %v", err, ErrDoubleSign)
+	}
+
+	restarted, err := NewPolicySigner(NewLocalSigner(hsm), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := restarted.ValidatorSign(validator.XPub, &cfg.ValidatorSignRequest{BlockHeader: forked}); errors.Root(err) != ErrDoubleSign {
+		t.Errorf("restarted signer sign another block got error %v want %v", err, ErrDoubleSign)
+	}
+
+	if _, err := NewPolicySigner(NewLocalSigner(hsm), &PolicyConfig{Keys: []*KeyRule{{XPub: validator.XPub, PasswordFile: passwordFile}}}); errors.Root(err) != ErrPolicyFormat {
+		t.Errorf("password file without state file got error %v want %v", err, ErrPolicyFormat)
+	}
+
+	path := [][]byte{{0x2c, 0, 0, 0}, {0x02, 0, 0, 0}}
+	if derived, err := policySigner.DeriveXPub(validator.XPub, path); err != nil || derived != validator.XPub.Derive(path) {
+		t.Errorf("derive allowed path got %x %v", derived, err)
+	}
+
+	if _, err := policySigner.DeriveXPub(validator.XPub, [][]byte{{0x2d, 0, 0, 0}}); err != ErrPathNotAllowed {
+		t.Errorf("derive path not allowed got error %v want %v", err, ErrPathNotAllowed)
+	}
+
+	if _, err := NewPolicySigner(NewLocalSigner(hsm), &PolicyConfig{Keys: []*KeyRule{{XPub: validator.XPub, AllowedPaths: []string{"2c000000"}}}}); errors.Root(err) != ErrPolicyFormat {
+		t.Errorf("invalid path pattern got error %v want %v", err, ErrPolicyFormat)
+	}
+
+	denySigner, err := NewPolicySigner(NewLocalSigner(hsm), &PolicyConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := denySigner.XSign(validator.XPub, path, []byte("msg"), "password"); err != ErrKeyNotAllowed {
+		t.Errorf("sign without policy got error %v want %v", err, ErrKeyNotAllowed)
+	}
+}
+
+func TestNewPathPolicySigner(t *testing.T) {
+	cases := []struct {
+		pattern string
+		err     error
+	}{
+		{pattern: "m"},
+		{pattern: "m/2c000000/*"},
+		{pattern: "m/2c000000/01000000/*/*/*"},
+		{pattern: "m/020000000000000000/*"},
+		{pattern: "m/*/*", err: ErrPolicyFormat},
+		{pattern: "m/000100000000000000/*", err: ErrPolicyFormat},
+		{pattern: "m/010100000000000000/01000000", err: ErrPolicyFormat},
+		{pattern: "m/2c000000/99000000/*/*/*", err: ErrPolicyFormat},
+		{pattern: "m/2C000000/*/00000000/00000000/*", err: ErrPolicyFormat},
+		{pattern: "m/*/*/*/*/*", err: ErrPolicyFormat},
+	}
+
+	for i, c := range cases {
+		config := &PolicyConfig{Keys: []*KeyRule{{AllowedPaths: []string{c.pattern}}}}
+		if _, err := NewPathPolicySigner(nil, config); errors.Root(err) != c.err {
+			t.Errorf("case %d: got error %v want %v", i, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package signer
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bytom/bytom/blockchain/pseudohsm"
+	cfg "github.com/bytom/bytom/config"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/errors"
+)
+
+const defaultRemoteTimeout = 10 * time.Second
+
+// error codes of the remote signing protocol
+const (
+	codeUnauthorized = "unauthorized"
+	codeKeyNotFound  = "key_not_found"
+	codeLoadKey      = "load_key"
+	codeKeyDenied    = "key_not_allowed"
+	codePathDenied   = "path_not_allowed"
+	codeRateLimit    = "rate_limit"
+	codeSession      = "session_not_found"
+	codeMessage      = "message_not_allowed"
+	codeDoubleSign   = "double_sign"
+	codeBadRequest   = "bad_request"
+	codeInternal     = "internal"
+)
+
+var codeErrors = map[string]error{
+	codeUnauthorized: ErrUnauthorized,
+	codeKeyNotFound:  ErrKeyNotFound,
+	codeLoadKey:      pseudohsm.ErrLoadKey,
+	codeKeyDenied:    ErrKeyNotAllowed,
+	codePathDenied:   ErrPathNotAllowed,
+	codeRateLimit:    ErrRateLimit,
+	codeSession:      ErrFrostSession,
+	codeMessage:      ErrValidatorMessage,
+	codeDoubleSign:   ErrDoubleSign,
+}
+
+type signRequest struct {
+	XPub     chainkd.XPub         `json:"xpub"`
+	Path     []chainjson.HexBytes `json:"path"`
+	Message  chainjson.HexBytes   `json:"message"`
+	Password string               `json:"password"`
+}
+
+type signResponse struct {
+	Signature chainjson.HexBytes `json:"signature"`
+}
+
+type validatorSignRequest struct {
+	XPub    chainkd.XPub              `json:"xpub"`
+	Request *cfg.ValidatorSignRequest `json:"request"`
+}
+
+type deriveRequest struct {
+	XPub chainkd.XPub         `json:"xpub"`
+	Path []chainjson.HexBytes `json:"path"`
+}
+
+type deriveResponse struct {
+	XPub chainkd.XPub `json:"xpub"`
+}
+
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func toHexPath(path [][]byte) []chainjson.HexBytes {
+	hexPath := make([]chainjson.HexBytes, len(path))
+	for i, p := range path {
+		hexPath[i] = p
+	}
+	return hexPath
+}
+
+func fromHexPath(hexPath []chainjson.HexBytes) [][]byte {
+	path := make([][]byte, len(hexPath))
+	for i, p := range hexPath {
+		path[i] = p
+	}
+	return path
+}
+
+// RemoteSigner is the Signer talking to a remote signing daemon
+type RemoteSigner struct {
+	url    string
+	token  string
+	client *http.Client
+}
+
+// NewRemoteSigner returns the Signer of the daemon listening on url, a nil
+// client uses a plain http client with the default timeout
+func NewRemoteSigner(url, token string, client *http.Client) *RemoteSigner {
+	if client == nil {
+		client = &http.Client{Timeout: defaultRemoteTimeout}
+	}
+	return &RemoteSigner{url: strings.TrimRight(url, "/"), token: token, client: client}
+}
+
+func (s *RemoteSigner) call(path string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", s.url+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if s.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return errors.Wrap(ErrRemote, err.Error())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		errResp := &errorResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(errResp); err != nil {
+			return errors.WithDetailf(ErrRemote, "status %d", resp.StatusCode)
+		}
+
+		if rootErr, ok := codeErrors[errResp.Code]; ok {
+			return errors.WithDetail(rootErr, errResp.Message)
+		}
+		return errors.WithDetail(ErrRemote, errResp.Message)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(response)
+}
+
+// ListKeys returns the keys held by the daemon
+func (s *RemoteSigner) ListKeys() ([]pseudohsm.XPub, error) {
+	keys := []pseudohsm.XPub{}
+	return keys, s.call("/list-keys", struct{}{}, &keys)
+}
+
+// XSign asks the daemon to sign the msg
+func (s *RemoteSigner) XSign(xpub chainkd.XPub, path [][]byte, msg []byte, auth string) ([]byte, error) {
+	resp := &signResponse{}
+	req := &signRequest{XPub: xpub, Path: toHexPath(path), Message: msg, Password: auth}
+	if err := s.call("/sign", req, resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// ValidatorSign asks the daemon to sign the block or the verification of the
+// request with the validator key
+func (s *RemoteSigner) ValidatorSign(xpub chainkd.XPub, req *cfg.ValidatorSignRequest) ([]byte, error) {
+	resp := &signResponse{}
+	if err := s.call("/validator-sign", &validatorSignRequest{XPub: xpub, Request: req}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Signature, nil
+}
+
+// DeriveXPub asks the daemon to derive the xpub
+func (s *RemoteSigner) DeriveXPub(xpub chainkd.XPub, path [][]byte) (chainkd.XPub, error) {
+	resp := &deriveResponse{}
+	if err := s.call("/derive-xpub", &deriveRequest{XPub: xpub, Path: toHexPath(path)}, resp); err != nil {
+		return chainkd.XPub{}, err
+	}
+	return resp.XPub, nil
+}
@@ -0,0 +1,211 @@
+package signer
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/bytom/bytom/blockchain/pseudohsm"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/crypto/sha3pool"
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/errors"
+)
+
+// AuditRecord is one line of the json audit log
+type AuditRecord struct {
+	Time        time.Time `json:"time"`
+	Client      string    `json:"client"`
+	Action      string    `json:"action"`
+	XPub        string    `json:"xpub"`
+	Path        []string  `json:"path"`
+	MessageHash string    `json:"message_hash,omitempty"`
+	Error       string    `json:"error,omitempty"`
+}
+
+// Server serves the remote signing protocol with the keys of a Signer
+type Server struct {
+	signer Signer
+	token  string
+	mux    *http.ServeMux
+
+	auditMu sync.Mutex
+	audit   io.Writer
+}
+
+// NewServer returns the protocol handler of the signer, requests must carry
+// the token when it is not empty
+func NewServer(signer Signer, token string) *Server {
+	s := &Server{signer: signer, token: token, mux: http.NewServeMux()}
+	s.mux.HandleFunc("/list-keys", s.listKeys)
+	s.mux.HandleFunc("/sign", s.sign)
+	s.mux.HandleFunc("/derive-xpub", s.deriveXPub)
+	s.mux.HandleFunc("/validator-sign", s.validatorSign)
+	return s
+}
+
+// SetAuditLog makes the server append a json line for every sign and derive
+// request to w
+func (s *Server) SetAuditLog(w io.Writer) {
+	s.audit = w
+}
+
+// clientName is the common name of the client certificate, or the remote
+// address when the client has no certificate
+func clientName(req *http.Request) string {
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 {
+		return req.TLS.PeerCertificates[0].Subject.CommonName
+	}
+	return req.RemoteAddr
+}
+
+func (s *Server) auditLog(req *http.Request, action string, xpub chainkd.XPub, path []chainjson.HexBytes, msg []byte, err error) {
+	if s.audit == nil {
+		return
+	}
+
+	record := &AuditRecord{Time: time.Now().UTC(), Client: clientName(req), Action: action, XPub: xpub.String(), Path: []string{}}
+	for _, p := range path {
+		record.Path = append(record.Path, hex.EncodeToString(p))
+	}
+
+	if msg != nil {
+		var hash [32]byte
+		sha3pool.Sum256(hash[:], msg)
+		record.MessageHash = hex.EncodeToString(hash[:])
+	}
+
+	if err != nil {
+		record.Error = err.Error()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return
+	}
+
+	s.auditMu.Lock()
+	defer s.auditMu.Unlock()
+	s.audit.Write(append(line, '\n'))
+}
+
+// ServeHTTP authenticates the request and dispatches it by path
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if authorize(w, req, s.token) {
+		s.mux.ServeHTTP(w, req)
+	}
+}
+
+// authorize writes the error response and returns false when the request is
+// not a POST carrying the bearer token
+func authorize(w http.ResponseWriter, req *http.Request, token string) bool {
+	if req.Method != "POST" {
+		writeError(w, http.StatusMethodNotAllowed, codeBadRequest, "method not allowed")
+		return false
+	}
+
+	if token != "" && subtle.ConstantTimeCompare([]byte(req.Header.Get("Authorization")), []byte("Bearer "+token)) != 1 {
+		writeError(w, http.StatusUnauthorized, codeUnauthorized, ErrUnauthorized.Error())
+		return false
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&errorResponse{Code: code, Message: message})
+}
+
+func writeSignerError(w http.ResponseWriter, err error) {
+	switch errors.Root(err) {
+	case ErrKeyNotFound:
+		writeError(w, http.StatusNotFound, codeKeyNotFound, err.Error())
+	case pseudohsm.ErrLoadKey, pseudohsm.ErrDecrypt:
+		writeError(w, http.StatusForbidden, codeLoadKey, err.Error())
+	case ErrUnauthorized:
+		writeError(w, http.StatusForbidden, codeUnauthorized, err.Error())
+	case ErrKeyNotAllowed:
+		writeError(w, http.StatusForbidden, codeKeyDenied, err.Error())
+	case ErrPathNotAllowed:
+		writeError(w, http.StatusForbidden, codePathDenied, err.Error())
+	case ErrRateLimit:
+		writeError(w, http.StatusTooManyRequests, codeRateLimit, err.Error())
+	case ErrValidatorMessage, ErrDoubleSign:
+		writeError(w, http.StatusBadRequest, guardErrorCode(err), err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, codeInternal, err.Error())
+	}
+}
+
+func writeResult(w http.ResponseWriter, result interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (s *Server) listKeys(w http.ResponseWriter, req *http.Request) {
+	keys, err := s.signer.ListKeys()
+	if err != nil {
+		writeSignerError(w, err)
+		return
+	}
+	writeResult(w, keys)
+}
+
+func (s *Server) sign(w http.ResponseWriter, req *http.Request) {
+	in := &signRequest{}
+	if err := json.NewDecoder(req.Body).Decode(in); err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	sig, err := s.signer.XSign(in.XPub, fromHexPath(in.Path), in.Message, in.Password)
+	s.auditLog(req, "sign", in.XPub, in.Path, in.Message, err)
+	if err != nil {
+		writeSignerError(w, err)
+		return
+	}
+	writeResult(w, &signResponse{Signature: sig})
+}
+
+func (s *Server) deriveXPub(w http.ResponseWriter, req *http.Request) {
+	in := &deriveRequest{}
+	if err := json.NewDecoder(req.Body).Decode(in); err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	xpub, err := s.signer.DeriveXPub(in.XPub, fromHexPath(in.Path))
+	s.auditLog(req, "derive-xpub", in.XPub, in.Path, nil, err)
+	if err != nil {
+		writeSignerError(w, err)
+		return
+	}
+	writeResult(w, &deriveResponse{XPub: xpub})
+}
+
+func (s *Server) validatorSign(w http.ResponseWriter, req *http.Request) {
+	in := &validatorSignRequest{}
+	if err := json.NewDecoder(req.Body).Decode(in); err != nil {
+		writeError(w, http.StatusBadRequest, codeBadRequest, err.Error())
+		return
+	}
+
+	validatorSigner, ok := s.signer.(ValidatorSigner)
+	if !ok {
+		writeSignerError(w, ErrKeyNotAllowed)
+		return
+	}
+
+	sig, err := validatorSigner.ValidatorSign(in.XPub, in.Request)
+	s.auditLog(req, "validator-sign", in.XPub, nil, nil, err)
+	if err != nil {
+		writeSignerError(w, err)
+		return
+	}
+	writeResult(w, &signResponse{Signature: sig})
+}
@@ -0,0 +1,88 @@
+// Package signer abstracts the key holder used by the wallet to sign
+// transactions, so the private keys can live in the local pseudohsm keystore
+// or in a remote signing daemon.
+//
+// The remote signing protocol is JSON over HTTP. Every request is a POST with
+// a JSON body, authenticated by the "Authorization: Bearer <token>" header
+// when the daemon has a token:
+//
+//	POST /list-keys       {}                                         -> [{"alias", "xpub", "file"}]
+//	POST /sign            {"xpub", "path": [hex], "message": hex,
+//	                       "password"}                               -> {"signature": hex}
+//	POST /derive-xpub     {"xpub", "path": [hex]}                    -> {"xpub"}
+//	POST /validator-sign  {"xpub", "request"}                        -> {"signature": hex}
+//
+// Only /validator-sign uses the password file of the policy, the daemon
+// rebuilds the block or verification hash from the request and refuses to
+// sign twice at the same height.
+//
+// A successful call answers 200 with the result, a failed call answers a non
+// 200 status with {"code", "message"}, where code is one of the error codes
+// defined in this package.
+package signer
+
+import (
+	"github.com/bytom/bytom/blockchain/pseudohsm"
+	cfg "github.com/bytom/bytom/config"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrUnauthorized = errors.New("remote signer rejected the credential")
+	ErrRemote       = errors.New("remote signer failed")
+	ErrKeyNotFound  = errors.New("key not found in signer")
+)
+
+// Signer holds the private keys and signs with them, callers only ever see
+// the xpubs.
+type Signer interface {
+	// ListKeys returns the root xpubs held by the signer
+	ListKeys() ([]pseudohsm.XPub, error)
+
+	// XSign derives the key of xpub with the path and signs the msg, the
+	// auth unlocks the key in the signer
+	XSign(xpub chainkd.XPub, path [][]byte, msg []byte, auth string) ([]byte, error)
+
+	// DeriveXPub derives the public key of xpub with the path
+	DeriveXPub(xpub chainkd.XPub, path [][]byte) (chainkd.XPub, error)
+}
+
+// ValidatorSigner signs the blocks and the casper verifications with the
+// root key of a validator, the message is rebuilt from the request instead
+// of trusting a bare hash
+type ValidatorSigner interface {
+	ValidatorSign(xpub chainkd.XPub, req *cfg.ValidatorSignRequest) ([]byte, error)
+}
+
+// LocalSigner is the Signer backed by the local pseudohsm keystore
+type LocalSigner struct {
+	hsm *pseudohsm.HSM
+}
+
+// NewLocalSigner returns the Signer using the keys of the pseudohsm
+func NewLocalSigner(hsm *pseudohsm.HSM) *LocalSigner {
+	return &LocalSigner{hsm: hsm}
+}
+
+// ListKeys returns the keys in the keystore
+func (s *LocalSigner) ListKeys() ([]pseudohsm.XPub, error) {
+	return s.hsm.ListKeys(), nil
+}
+
+// XSign signs the msg with the key in the keystore
+func (s *LocalSigner) XSign(xpub chainkd.XPub, path [][]byte, msg []byte, auth string) ([]byte, error) {
+	return s.hsm.XSign(xpub, path, msg, auth)
+}
+
+// DeriveXPub derives the xpub when the keystore holds its private key
+func (s *LocalSigner) DeriveXPub(xpub chainkd.XPub, path [][]byte) (chainkd.XPub, error) {
+	keys := s.hsm.ListKeys()
+	for _, key := range keys {
+		if key.XPub == xpub {
+			return xpub.Derive(path), nil
+		}
+	}
+	return chainkd.XPub{}, ErrKeyNotFound
+}
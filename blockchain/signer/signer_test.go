@@ -0,0 +1,82 @@
+package signer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/bytom/bytom/blockchain/pseudohsm"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	dirPath, err := ioutil.TempDir(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	hsm, err := pseudohsm.New(dirPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xpub, _, err := hsm.XCreate("signer", "password", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := NewLocalSigner(hsm)
+	server := httptest.NewServer(NewServer(local, "token"))
+	defer server.Close()
+
+	remote := NewRemoteSigner(server.URL, "token", nil)
+	keys, err := remote.ListKeys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 1 || keys[0].XPub != xpub.XPub || keys[0].Alias != "signer" {
+		t.Fatalf("list keys got %v", keys)
+	}
+
+	path := [][]byte{{0x2c, 0, 0, 0}, {0x99, 0, 0, 0}}
+	msg := []byte("message")
+	want, err := local.XSign(xpub.XPub, path, msg, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := remote.XSign(xpub.XPub, path, msg, "password")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(got, want) {
+		t.Errorf("remote signature got %x want %x", got, want)
+	}
+
+	derived, err := remote.DeriveXPub(xpub.XPub, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if derived != xpub.XPub.Derive(path) || !derived.Verify(msg, got) {
+		t.Errorf("derived xpub %x does not match the signature", derived)
+	}
+
+	if _, err := remote.XSign(xpub.XPub, path, msg, "wrong"); errors.Root(err) != pseudohsm.ErrLoadKey {
+		t.Errorf("sign with wrong password got error %v want %v", err, pseudohsm.ErrLoadKey)
+	}
+
+	if _, err := remote.DeriveXPub(chainkd.XPub{}, path); errors.Root(err) != ErrKeyNotFound {
+		t.Errorf("derive unknown xpub got error %v want %v", err, ErrKeyNotFound)
+	}
+
+	if _, err := NewRemoteSigner(server.URL, "bad", nil).ListKeys(); errors.Root(err) != ErrUnauthorized {
+		t.Errorf("list keys with bad token got error %v want %v", err, ErrUnauthorized)
+	}
+}
@@ -2,9 +2,11 @@ package txbuilder
 
 import (
 	"context"
+	"crypto/ed25519"
 	stdjson "encoding/json"
 	"errors"
 
+	"github.com/bytom/bytom/blockchain/memo"
 	"github.com/bytom/bytom/common"
 	"github.com/bytom/bytom/consensus"
 	"github.com/bytom/bytom/encoding/json"
@@ -13,6 +15,24 @@ import (
 	"github.com/bytom/bytom/protocol/vm/vmutil"
 )
 
+// memoStateData encrypts the memo to the recipient key as the state data of
+// the output, no state data is attached without a memo
+func memoStateData(text string, recipient json.HexBytes) ([][]byte, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	if len(recipient) != ed25519.PublicKeySize {
+		return nil, MissingFieldsError("memo_public_key")
+	}
+
+	envelope, err := memo.Seal(ed25519.PublicKey(recipient), []byte(text))
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{envelope}, nil
+}
+
 // DecodeControlAddressAction convert input data to action struct
 func DecodeControlAddressAction(data []byte) (Action, error) {
 	a := new(controlAddressAction)
@@ -22,7 +42,9 @@ func DecodeControlAddressAction(data []byte) (Action, error) {
 
 type controlAddressAction struct {
 	bc.AssetAmount
-	Address string `json:"address"`
+	Address       string        `json:"address"`
+	Memo          string        `json:"memo"`
+	MemoPublicKey json.HexBytes `json:"memo_public_key"`
 }
 
 func (a *controlAddressAction) Build(ctx context.Context, b *TemplateBuilder) error {
@@ -59,7 +81,12 @@ func (a *controlAddressAction) Build(ctx context.Context, b *TemplateBuilder) er
 		return err
 	}
 
-	out := types.NewOriginalTxOutput(*a.AssetId, a.Amount, program, nil)
+	stateData, err := memoStateData(a.Memo, a.MemoPublicKey)
+	if err != nil {
+		return err
+	}
+
+	out := types.NewOriginalTxOutput(*a.AssetId, a.Amount, program, stateData)
 	return b.AddOutput(out)
 }
 
@@ -76,7 +103,9 @@ func DecodeControlProgramAction(data []byte) (Action, error) {
 
 type controlProgramAction struct {
 	bc.AssetAmount
-	Program json.HexBytes `json:"control_program"`
+	Program       json.HexBytes `json:"control_program"`
+	Memo          string        `json:"memo"`
+	MemoPublicKey json.HexBytes `json:"memo_public_key"`
 }
 
 func (a *controlProgramAction) Build(ctx context.Context, b *TemplateBuilder) error {
@@ -94,7 +123,12 @@ func (a *controlProgramAction) Build(ctx context.Context, b *TemplateBuilder) er
 		return MissingFieldsError(missing...)
 	}
 
-	out := types.NewOriginalTxOutput(*a.AssetId, a.Amount, a.Program, nil)
+	stateData, err := memoStateData(a.Memo, a.MemoPublicKey)
+	if err != nil {
+		return err
+	}
+
+	out := types.NewOriginalTxOutput(*a.AssetId, a.Amount, a.Program, stateData)
 	return b.AddOutput(out)
 }
 
@@ -22,6 +22,7 @@ type TemplateBuilder struct {
 	minTime             time.Time
 	maxTime             time.Time
 	timeRange           uint64
+	lockTime            uint64
 	rollbacks           []func()
 	callbacks           []func() error
 }
@@ -120,6 +121,10 @@ func (b *TemplateBuilder) Build() (*Template, *types.TxData, error) {
 		tx.TimeRange = b.timeRange
 	}
 
+	if b.lockTime != 0 {
+		tx.LockTime = b.lockTime
+	}
+
 	// Add all the built outputs.
 	tx.Outputs = append(tx.Outputs, b.outputs...)
 
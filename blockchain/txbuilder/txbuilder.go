@@ -44,11 +44,14 @@ var (
 // Build partners then satisfy and consume inputs and destinations.
 // The final party must ensure that the transaction is
 // balanced before calling finalize.
-func Build(ctx context.Context, tx *types.TxData, actions []Action, maxTime time.Time, timeRange uint64) (*Template, error) {
+// The transaction can't be packed before the lock time, which is a block
+// height or a block timestamp in milliseconds.
+func Build(ctx context.Context, tx *types.TxData, actions []Action, maxTime time.Time, timeRange, lockTime uint64) (*Template, error) {
 	builder := TemplateBuilder{
 		base:      tx,
 		maxTime:   maxTime,
 		timeRange: timeRange,
+		lockTime:  lockTime,
 	}
 
 	// Build all of the actions, updating the builder.
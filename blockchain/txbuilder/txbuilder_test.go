@@ -12,6 +12,7 @@ import (
 	"github.com/davecgh/go-spew/spew"
 	"golang.org/x/crypto/sha3"
 
+	"github.com/bytom/bytom/blockchain/memo"
 	"github.com/bytom/bytom/common"
 	"github.com/bytom/bytom/consensus"
 	"github.com/bytom/bytom/crypto"
@@ -60,7 +61,7 @@ func TestBuild(t *testing.T) {
 		testAction(bc.AssetAmount{AssetId: &assetID1, Amount: 5}),
 	}
 	expiryTime := time.Now().Add(time.Minute)
-	got, err := Build(ctx, nil, actions, expiryTime, 0)
+	got, err := Build(ctx, nil, actions, expiryTime, 0, 0)
 	if err != nil {
 		testutil.FatalErr(t, err)
 	}
@@ -495,3 +496,59 @@ func TestAddContractArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestControlAddressMemo(t *testing.T) {
+	xprv, err := chainkd.NewXPrv(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memoPrv, err := chainkd.NewXPrv(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pubKey := xprv.XPub().PublicKey()
+	address, err := common.NewAddressWitnessPubKeyHash(crypto.Ripemd160(pubKey), &consensus.ActiveNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assetID := bc.NewAssetID([32]byte{1})
+	action := &controlAddressAction{
+		AssetAmount:   bc.AssetAmount{AssetId: &assetID, Amount: 1},
+		Address:       address.EncodeAddress(),
+		Memo:          "deposit 10086",
+		MemoPublicKey: chainjson.HexBytes(memoPrv.XPub().PublicKey()),
+	}
+
+	b := NewBuilder(time.Now().Add(time.Minute))
+	if err := action.Build(context.Background(), b); err != nil {
+		t.Fatal(err)
+	}
+
+	envelope, ok := memo.Find(b.Outputs()[0].StateData)
+	if !ok {
+		t.Fatal("output has no memo")
+	}
+
+	plaintext, err := envelope.Open(memoPrv)
+	if err != nil || string(plaintext) != action.Memo {
+		t.Fatalf("open memo got %s %v want %s", plaintext, err, action.Memo)
+	}
+
+	if _, err := envelope.Open(xprv); err != memo.ErrDecrypt {
+		t.Fatalf("open memo with the address key got error %v want %v", err, memo.ErrDecrypt)
+	}
+
+	action.MemoPublicKey = nil
+	if err := action.Build(context.Background(), NewBuilder(time.Now().Add(time.Minute))); errors.Root(err) != ErrMissingFields {
+		t.Fatalf("memo without key got error %v want %v", err, ErrMissingFields)
+	}
+
+	action.Memo = ""
+	b = NewBuilder(time.Now().Add(time.Minute))
+	if err := action.Build(context.Background(), b); err != nil || len(b.Outputs()[0].StateData) != 0 {
+		t.Fatalf("output without memo got state data %v, error %v", b.Outputs()[0].StateData, err)
+	}
+}
@@ -38,6 +38,7 @@ type Action interface {
 type Receiver struct {
 	ControlProgram chainjson.HexBytes `json:"control_program,omitempty"`
 	Address        string             `json:"address,omitempty"`
+	MemoPublicKey  chainjson.HexBytes `json:"memo_public_key,omitempty"`
 }
 
 // ContractArgument for smart contract
@@ -2,6 +2,7 @@ package commands
 
 import (
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
@@ -24,6 +25,26 @@ func init() {
 	deleteAccountCmd.PersistentFlags().StringVar(&accountID, "id", "", "account ID")
 	deleteAccountCmd.PersistentFlags().StringVar(&accountAlias, "alias", "", "account alias")
 
+	exportDescriptorCmd.PersistentFlags().StringVar(&accountID, "id", "", "account ID")
+	exportDescriptorCmd.PersistentFlags().StringVar(&accountAlias, "alias", "", "account alias")
+
+	setAccountPolicyCmd.PersistentFlags().StringVar(&accountID, "id", "", "account ID")
+	setAccountPolicyCmd.PersistentFlags().StringVar(&accountAlias, "alias", "", "account alias")
+	setAccountPolicyCmd.PersistentFlags().StringSliceVar(&policyDailyLimits, "daily_limit", nil, "daily spend limit per asset, format: asset_id:amount")
+	setAccountPolicyCmd.PersistentFlags().StringSliceVar(&policyAddresses, "allowed_address", nil, "allowed destination address")
+	setAccountPolicyCmd.PersistentFlags().Uint64Var(&policyMaxFee, "max_fee", 0, "max transaction fee")
+	setAccountPolicyCmd.PersistentFlags().StringSliceVar(&policyTimeWindows, "time_window", nil, "allowed UTC time of day window, format: 08:00-20:00")
+	setAccountPolicyCmd.PersistentFlags().StringVarP(&policyPassphrase, "passphrase", "p", "", "policy passphrase")
+
+	getAccountPolicyCmd.PersistentFlags().StringVar(&accountID, "id", "", "account ID")
+	getAccountPolicyCmd.PersistentFlags().StringVar(&accountAlias, "alias", "", "account alias")
+
+	deleteAccountPolicyCmd.PersistentFlags().StringVar(&accountID, "id", "", "account ID")
+	deleteAccountPolicyCmd.PersistentFlags().StringVar(&accountAlias, "alias", "", "account alias")
+	deleteAccountPolicyCmd.PersistentFlags().StringVarP(&policyPassphrase, "passphrase", "p", "", "policy passphrase")
+
+	setAccountPolicyPassphraseCmd.PersistentFlags().StringVar(&policyOldPassphrase, "old", "", "current policy passphrase")
+
 	listAddressesCmd.PersistentFlags().StringVar(&accountID, "id", "", "account ID")
 	listAddressesCmd.PersistentFlags().StringVar(&accountAlias, "alias", "", "account alias")
 
@@ -48,6 +69,14 @@ var (
 	smartContract = false
 	from          = 0
 	count         = 0
+
+	policyDailyLimits []string
+	policyAddresses   []string
+	policyMaxFee      uint64
+	policyTimeWindows []string
+
+	policyPassphrase    = ""
+	policyOldPassphrase = ""
 )
 
 var createAccountCmd = &cobra.Command{
@@ -136,6 +165,154 @@ var deleteAccountCmd = &cobra.Command{
 	},
 }
 
+var importDescriptorCmd = &cobra.Command{
+	Use:   "import-descriptor <alias> <descriptor>",
+	Short: "Import an account from the account descriptor",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			Alias      string `json:"alias"`
+			Descriptor string `json:"descriptor"`
+		}{Alias: args[0], Descriptor: args[1]}
+
+		data, exitCode := util.ClientCall("/import-descriptor", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var exportDescriptorCmd = &cobra.Command{
+	Use:   "export-descriptor",
+	Short: "Export the descriptor of the existing account",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			AccountID    string `json:"account_id"`
+			AccountAlias string `json:"account_alias"`
+		}{AccountID: accountID, AccountAlias: accountAlias}
+
+		data, exitCode := util.ClientCall("/export-descriptor", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var setAccountPolicyCmd = &cobra.Command{
+	Use:   "set-account-policy",
+	Short: "Set the spending policy of the account",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		type timeWindow struct {
+			Start string `json:"start"`
+			End   string `json:"end"`
+		}
+
+		var ins = struct {
+			AccountID        string            `json:"account_id"`
+			AccountAlias     string            `json:"account_alias"`
+			DailyLimits      map[string]uint64 `json:"daily_limits"`
+			AllowedAddresses []string          `json:"allowed_addresses"`
+			MaxFee           uint64            `json:"max_fee"`
+			TimeWindows      []*timeWindow     `json:"time_windows"`
+			Passphrase       string            `json:"passphrase"`
+		}{AccountID: accountID, AccountAlias: accountAlias, AllowedAddresses: policyAddresses, MaxFee: policyMaxFee, Passphrase: policyPassphrase}
+
+		ins.DailyLimits = map[string]uint64{}
+		for _, limit := range policyDailyLimits {
+			fields := strings.Split(limit, ":")
+			if len(fields) != 2 {
+				jww.ERROR.Println("Invalid daily limit: " + limit)
+				os.Exit(util.ErrLocalExe)
+			}
+
+			amount, err := strconv.ParseUint(fields[1], 10, 64)
+			if err != nil {
+				jww.ERROR.Println(err)
+				os.Exit(util.ErrLocalExe)
+			}
+			ins.DailyLimits[fields[0]] = amount
+		}
+
+		for _, window := range policyTimeWindows {
+			fields := strings.Split(window, "-")
+			if len(fields) != 2 {
+				jww.ERROR.Println("Invalid time window: " + window)
+				os.Exit(util.ErrLocalExe)
+			}
+			ins.TimeWindows = append(ins.TimeWindows, &timeWindow{Start: fields[0], End: fields[1]})
+		}
+
+		data, exitCode := util.ClientCall("/set-account-policy", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var getAccountPolicyCmd = &cobra.Command{
+	Use:   "get-account-policy",
+	Short: "Get the spending policy of the account",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			AccountID    string `json:"account_id"`
+			AccountAlias string `json:"account_alias"`
+		}{AccountID: accountID, AccountAlias: accountAlias}
+
+		data, exitCode := util.ClientCall("/get-account-policy", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var deleteAccountPolicyCmd = &cobra.Command{
+	Use:   "delete-account-policy",
+	Short: "Delete the spending policy of the account",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			AccountID    string `json:"account_id"`
+			AccountAlias string `json:"account_alias"`
+			Passphrase   string `json:"passphrase"`
+		}{AccountID: accountID, AccountAlias: accountAlias, Passphrase: policyPassphrase}
+
+		if _, exitCode := util.ClientCall("/delete-account-policy", &ins); exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		jww.FEEDBACK.Println("Successfully delete account policy")
+	},
+}
+
+var setAccountPolicyPassphraseCmd = &cobra.Command{
+	Use:   "set-account-policy-passphrase <passphrase>",
+	Short: "Set the passphrase required to change the account policies",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			OldPassphrase string `json:"old_passphrase"`
+			Passphrase    string `json:"passphrase"`
+		}{OldPassphrase: policyOldPassphrase, Passphrase: args[0]}
+
+		if _, exitCode := util.ClientCall("/set-account-policy-passphrase", &ins); exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		jww.FEEDBACK.Println("Successfully set account policy passphrase")
+	},
+}
+
 var createAccountReceiverCmd = &cobra.Command{
 	Use:   "create-account-receiver <accountAlias> [accountID]",
 	Short: "Create an account receiver",
@@ -115,6 +115,12 @@ func AddCommands() {
 	BytomcliCmd.AddCommand(deleteAccountCmd)
 	BytomcliCmd.AddCommand(listAccountsCmd)
 	BytomcliCmd.AddCommand(updateAccountAliasCmd)
+	BytomcliCmd.AddCommand(importDescriptorCmd)
+	BytomcliCmd.AddCommand(exportDescriptorCmd)
+	BytomcliCmd.AddCommand(setAccountPolicyCmd)
+	BytomcliCmd.AddCommand(getAccountPolicyCmd)
+	BytomcliCmd.AddCommand(deleteAccountPolicyCmd)
+	BytomcliCmd.AddCommand(setAccountPolicyPassphraseCmd)
 	BytomcliCmd.AddCommand(createAccountReceiverCmd)
 	BytomcliCmd.AddCommand(listAddressesCmd)
 	BytomcliCmd.AddCommand(validateAddressCmd)
@@ -137,10 +143,15 @@ func AddCommands() {
 
 	BytomcliCmd.AddCommand(rescanWalletCmd)
 	BytomcliCmd.AddCommand(walletInfoCmd)
+	BytomcliCmd.AddCommand(exportHistoryCmd)
+	BytomcliCmd.AddCommand(generateReserveProofCmd)
+	BytomcliCmd.AddCommand(verifyReserveProofCmd)
 
 	BytomcliCmd.AddCommand(buildTransactionCmd)
 	BytomcliCmd.AddCommand(signTransactionCmd)
 	BytomcliCmd.AddCommand(submitTransactionCmd)
+	BytomcliCmd.AddCommand(simulateTransactionCmd)
+	BytomcliCmd.AddCommand(profileTransactionCmd)
 	BytomcliCmd.AddCommand(estimateTransactionGasCmd)
 
 	BytomcliCmd.AddCommand(getBlockCountCmd)
@@ -154,10 +165,23 @@ func AddCommands() {
 	BytomcliCmd.AddCommand(updateKeyAliasCmd)
 	BytomcliCmd.AddCommand(resetKeyPwdCmd)
 	BytomcliCmd.AddCommand(checkKeyPwdCmd)
+	BytomcliCmd.AddCommand(unlockKeyCmd)
+	BytomcliCmd.AddCommand(lockKeyCmd)
+	BytomcliCmd.AddCommand(listUnlockedKeysCmd)
+	BytomcliCmd.AddCommand(exportKeySharesCmd)
+	BytomcliCmd.AddCommand(importKeySharesCmd)
+	BytomcliCmd.AddCommand(checkKeyKDFsCmd)
+	BytomcliCmd.AddCommand(upgradeKeysCmd)
+	BytomcliCmd.AddCommand(deriveXPubCmd)
+	BytomcliCmd.AddCommand(signWithPathCmd)
 
 	BytomcliCmd.AddCommand(signMsgCmd)
 	BytomcliCmd.AddCommand(verifyMsgCmd)
+	BytomcliCmd.AddCommand(signMsgProofCmd)
+	BytomcliCmd.AddCommand(verifyMsgProofCmd)
 	BytomcliCmd.AddCommand(decodeProgCmd)
+	BytomcliCmd.AddCommand(debugProgramCmd)
+	BytomcliCmd.AddCommand(compileContractCmd)
 
 	BytomcliCmd.AddCommand(createTransactionFeedCmd)
 	BytomcliCmd.AddCommand(listTransactionFeedsCmd)
@@ -178,6 +202,12 @@ func AddTemplateFunc() {
 		listAccountsCmd.Name(),
 		deleteAccountCmd.Name(),
 		updateAccountAliasCmd.Name(),
+		importDescriptorCmd.Name(),
+		exportDescriptorCmd.Name(),
+		setAccountPolicyCmd.Name(),
+		getAccountPolicyCmd.Name(),
+		deleteAccountPolicyCmd.Name(),
+		setAccountPolicyPassphraseCmd.Name(),
 		createAccountReceiverCmd.Name(),
 		listAddressesCmd.Name(),
 		validateAddressCmd.Name(),
@@ -193,7 +223,17 @@ func AddTemplateFunc() {
 		listKeysCmd.Name(),
 		resetKeyPwdCmd.Name(),
 		checkKeyPwdCmd.Name(),
+		unlockKeyCmd.Name(),
+		lockKeyCmd.Name(),
+		listUnlockedKeysCmd.Name(),
+		exportKeySharesCmd.Name(),
+		importKeySharesCmd.Name(),
+		checkKeyKDFsCmd.Name(),
+		upgradeKeysCmd.Name(),
+		deriveXPubCmd.Name(),
+		signWithPathCmd.Name(),
 		signMsgCmd.Name(),
+		signMsgProofCmd.Name(),
 
 		buildTransactionCmd.Name(),
 		signTransactionCmd.Name(),
@@ -205,6 +245,8 @@ func AddTemplateFunc() {
 
 		rescanWalletCmd.Name(),
 		walletInfoCmd.Name(),
+		exportHistoryCmd.Name(),
+		generateReserveProofCmd.Name(),
 	}
 
 	cobra.AddTemplateFunc("WalletEnable", func(cmdName string) bool {
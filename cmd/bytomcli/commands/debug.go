@@ -0,0 +1,375 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
+
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/consensus/segwit"
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/protocol/vm"
+	"github.com/bytom/bytom/util"
+)
+
+var (
+	debugArgs      = []string{}
+	debugStateData = []string{}
+	debugContext   = ""
+	debugGas       = int64(0)
+)
+
+func init() {
+	debugProgramCmd.PersistentFlags().StringSliceVar(&debugArgs, "arg", nil, "hex argument pushed to the data stack, the last one is the top")
+	debugProgramCmd.PersistentFlags().StringSliceVar(&debugStateData, "state_data", nil, "hex state data pushed to the alt stack")
+	debugProgramCmd.PersistentFlags().StringVar(&debugContext, "context", "", "json file of the mock transaction context")
+	debugProgramCmd.PersistentFlags().Int64Var(&debugGas, "gas", int64(consensus.MaxBlockGas), "gas limit of the program")
+}
+
+// debugOutput is a transaction output checked by CHECKOUTPUT
+type debugOutput struct {
+	Amount         uint64               `json:"amount"`
+	AssetID        chainjson.HexBytes   `json:"asset_id"`
+	VMVersion      uint64               `json:"vm_version"`
+	ControlProgram chainjson.HexBytes   `json:"control_program"`
+	StateData      []chainjson.HexBytes `json:"state_data"`
+}
+
+// debugTxContext is the mock transaction context the program runs in, the
+// absent fields fail the opcodes reading them
+type debugTxContext struct {
+	VMVersion     *uint64             `json:"vm_version"`
+	TxVersion     *uint64             `json:"tx_version"`
+	BlockHeight   *uint64             `json:"block_height"`
+	LockTime      *uint64             `json:"lock_time"`
+	Sequence      *uint64             `json:"sequence"`
+	EntryID       chainjson.HexBytes  `json:"entry_id"`
+	TxSigHash     chainjson.HexBytes  `json:"tx_sig_hash"`
+	NumResults    *uint64             `json:"num_results"`
+	AssetID       *chainjson.HexBytes `json:"asset_id"`
+	Amount        *uint64             `json:"amount"`
+	DestPos       *uint64             `json:"dest_pos"`
+	SpentOutputID *chainjson.HexBytes `json:"spent_output_id"`
+	Outputs       []*debugOutput      `json:"outputs"`
+}
+
+// vmVersion returns the vm version the program runs in, it's 1 by default
+func (c *debugTxContext) vmVersion() uint64 {
+	if c.VMVersion == nil {
+		return 1
+	}
+	return *c.VMVersion
+}
+
+func (c *debugTxContext) vmContext(program []byte, args, stateData [][]byte) *vm.Context {
+	context := &vm.Context{
+		VMVersion:   c.vmVersion(),
+		Code:        program,
+		StateData:   stateData,
+		Arguments:   args,
+		EntryID:     c.EntryID,
+		TxVersion:   c.TxVersion,
+		BlockHeight: c.BlockHeight,
+		LockTime:    c.LockTime,
+		Sequence:    c.Sequence,
+		NumResults:  c.NumResults,
+		Amount:      c.Amount,
+		DestPos:     c.DestPos,
+	}
+
+	if c.AssetID != nil {
+		assetID := []byte(*c.AssetID)
+		context.AssetID = &assetID
+	}
+	if c.SpentOutputID != nil {
+		spentOutputID := []byte(*c.SpentOutputID)
+		context.SpentOutputID = &spentOutputID
+	}
+	if c.TxSigHash != nil {
+		context.TxSigHash = func() []byte { return c.TxSigHash }
+	}
+	if c.Outputs != nil {
+		context.CheckOutput = c.checkOutput
+		context.OutputStateData = c.outputStateData
+	}
+	return context
+}
+
+func (c *debugTxContext) checkOutput(index uint64, amount uint64, assetID []byte, vmVersion uint64, code []byte, state [][]byte, expansion bool) (bool, error) {
+	if index >= uint64(len(c.Outputs)) {
+		return false, vm.ErrBadValue
+	}
+
+	output := c.Outputs[index]
+	if output.Amount != amount || !bytes.Equal(output.AssetID, assetID) || output.VMVersion != vmVersion || !bytes.Equal(output.ControlProgram, code) || len(output.StateData) != len(state) {
+		return false, nil
+	}
+
+	for i, data := range output.StateData {
+		if !bytes.Equal(data, state[i]) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (c *debugTxContext) outputStateData(index uint64) ([][]byte, error) {
+	if index >= uint64(len(c.Outputs)) {
+		return nil, vm.ErrBadValue
+	}
+
+	stateData := [][]byte{}
+	for _, data := range c.Outputs[index].StateData {
+		stateData = append(stateData, data)
+	}
+	return stateData, nil
+}
+
+// decodeDebugProgram reads the program as hex or as assembly of the vm
+// version, the segwit programs are converted into the programs actually executed
+func decodeDebugProgram(str string, vmVersion uint64) ([]byte, error) {
+	program, err := hex.DecodeString(str)
+	if err != nil {
+		return vm.AssembleVersion(str, vmVersion)
+	}
+
+	if segwit.IsP2WPKHScript(program) {
+		return segwit.ConvertP2PKHSigProgram(program)
+	}
+	if segwit.IsP2WSHScript(program) {
+		return segwit.ConvertP2SHProgram(program)
+	}
+	return program, nil
+}
+
+func decodeHexList(strs []string) ([][]byte, error) {
+	result := [][]byte{}
+	for _, str := range strs {
+		data, err := hex.DecodeString(str)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, data)
+	}
+	return result, nil
+}
+
+var debugProgramCmd = &cobra.Command{
+	Use:   "debug-program <program>",
+	Short: "Debug the program step by step in the Bytom VM",
+	Long: `Debug the hex or assembly program step by step in a local Bytom VM.
+Commands of the debugger:
+  step (s) [n]          execute the next n instructions
+  continue (c)          execute until a breakpoint or the end
+  break (b) <pc|op>     stop before the instruction at pc or with the opcode
+  delete (d) <pc|op>    remove the breakpoint
+  breakpoints           list the breakpoints
+  print (p)             print the data stack and the alt stack
+  list (l)              disassemble the program
+  quit (q)              exit the debugger`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		arguments, err := decodeHexList(debugArgs)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		stateData, err := decodeHexList(debugStateData)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		txContext := &debugTxContext{}
+		if debugContext != "" {
+			data, err := ioutil.ReadFile(debugContext)
+			if err != nil {
+				jww.ERROR.Println(err)
+				os.Exit(util.ErrLocalExe)
+			}
+
+			if err := json.Unmarshal(data, txContext); err != nil {
+				jww.ERROR.Println(err)
+				os.Exit(util.ErrLocalExe)
+			}
+		}
+
+		program, err := decodeDebugProgram(args[0], txContext.vmVersion())
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		debugger, err := vm.NewDebugger(txContext.vmContext(program, arguments, stateData), debugGas)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		session := &debugSession{debugger: debugger, program: program, breakpoints: map[string]bool{}}
+		session.run(bufio.NewScanner(os.Stdin))
+	},
+}
+
+type debugSession struct {
+	debugger    *vm.Debugger
+	program     []byte
+	breakpoints map[string]bool
+}
+
+func formatInstruction(pc uint32, inst vm.Instruction) string {
+	if len(inst.Data) == 0 {
+		return fmt.Sprintf("%d: %s", pc, inst.Op)
+	}
+	return fmt.Sprintf("%d: %s %x", pc, inst.Op, inst.Data)
+}
+
+func (s *debugSession) printState() {
+	if s.debugger.Done() {
+		if err := s.debugger.Err(); err != nil {
+			fmt.Printf("program failed: %v\n", err)
+		} else {
+			fmt.Println("program succeeded")
+		}
+		return
+	}
+
+	inst, err := s.debugger.Next()
+	if err != nil {
+		fmt.Printf("pc %d: %v\n", s.debugger.PC(), err)
+		return
+	}
+	fmt.Printf("=> %s (gas left %d)\n", formatInstruction(s.debugger.PC(), inst), s.debugger.GasLeft())
+}
+
+func (s *debugSession) printStacks() {
+	for _, stack := range []struct {
+		name  string
+		items [][]byte
+	}{{"data stack", s.debugger.DataStack()}, {"alt stack", s.debugger.AltStack()}} {
+		fmt.Printf("%s:\n", stack.name)
+		for i := len(stack.items) - 1; i >= 0; i-- {
+			fmt.Printf("  %d: %x\n", len(stack.items)-1-i, stack.items[i])
+		}
+	}
+}
+
+func (s *debugSession) list() {
+	for pc := uint32(0); pc < uint32(len(s.program)); {
+		inst, err := vm.ParseOp(s.program, pc)
+		if err != nil {
+			fmt.Printf("   %d: %v\n", pc, err)
+			return
+		}
+
+		marker := "  "
+		if !s.debugger.Done() && pc == s.debugger.PC() {
+			marker = "=>"
+		}
+		fmt.Printf("%s %s\n", marker, formatInstruction(pc, inst))
+		pc += inst.Len
+	}
+}
+
+// atBreakpoint reports whether the next instruction is a breakpoint by its
+// pc or its opcode
+func (s *debugSession) atBreakpoint() bool {
+	inst, err := s.debugger.Next()
+	if err != nil {
+		return false
+	}
+	return s.breakpoints[strconv.FormatUint(uint64(s.debugger.PC()), 10)] || s.breakpoints[inst.Op.String()]
+}
+
+func (s *debugSession) step(n int) {
+	for i := 0; i < n && !s.debugger.Done(); i++ {
+		if err := s.debugger.Step(); err != nil {
+			break
+		}
+	}
+	s.printState()
+}
+
+func (s *debugSession) resume() {
+	for !s.debugger.Done() {
+		if err := s.debugger.Step(); err != nil || s.atBreakpoint() {
+			break
+		}
+	}
+	s.printState()
+}
+
+// exec runs one debugger command, false is returned to quit
+func (s *debugSession) exec(fields []string) bool {
+	switch fields[0] {
+	case "step", "s":
+		n := 1
+		if len(fields) > 1 {
+			var err error
+			if n, err = strconv.Atoi(fields[1]); err != nil {
+				fmt.Printf("invalid step count %s\n", fields[1])
+				return true
+			}
+		}
+		s.step(n)
+
+	case "continue", "c":
+		s.resume()
+
+	case "break", "b", "delete", "d":
+		if len(fields) != 2 {
+			fmt.Printf("%s needs a pc or an opcode\n", fields[0])
+			return true
+		}
+
+		breakpoint := strings.ToUpper(fields[1])
+		if fields[0] == "break" || fields[0] == "b" {
+			s.breakpoints[breakpoint] = true
+		} else {
+			delete(s.breakpoints, breakpoint)
+		}
+
+	case "breakpoints":
+		for breakpoint := range s.breakpoints {
+			fmt.Println(breakpoint)
+		}
+
+	case "print", "p":
+		s.printStacks()
+
+	case "list", "l":
+		s.list()
+
+	case "quit", "q":
+		return false
+
+	default:
+		fmt.Printf("unknown command %s, see bytomcli debug-program --help\n", fields[0])
+	}
+	return true
+}
+
+func (s *debugSession) run(scanner *bufio.Scanner) {
+	s.printState()
+	for fmt.Print("(bvm) "); scanner.Scan(); fmt.Print("(bvm) ") {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+
+		if !s.exec(fields) {
+			return
+		}
+	}
+	fmt.Println()
+}
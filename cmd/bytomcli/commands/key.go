@@ -2,16 +2,47 @@ package commands
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"os"
+	"strings"
 
 	"github.com/spf13/cobra"
 	jww "github.com/spf13/jwalterweatherman"
 
+	"github.com/bytom/bytom/blockchain/message"
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
 	chainjson "github.com/bytom/bytom/encoding/json"
 	"github.com/bytom/bytom/util"
 )
 
+var (
+	unlockTimeout uint64
+	unlockScopes  []string
+
+	shareThreshold int
+	shareCount     int
+	shareLanguage  string
+
+	upgradeKDF string
+
+	proofUTXOs []string
+)
+
+func init() {
+	unlockKeyCmd.PersistentFlags().Uint64Var(&unlockTimeout, "timeout", 300, "seconds the key stays unlocked")
+	unlockKeyCmd.PersistentFlags().StringSliceVar(&unlockScopes, "scope", []string{"transaction"}, "operations the unlocked key is used for: transaction, message")
+
+	exportKeySharesCmd.PersistentFlags().IntVar(&shareThreshold, "threshold", 2, "number of shares required to restore the key")
+	exportKeySharesCmd.PersistentFlags().IntVar(&shareCount, "count", 3, "number of shares to export, at most 16")
+	exportKeySharesCmd.PersistentFlags().StringVar(&shareLanguage, "language", "en", "language of the share mnemonics")
+	importKeySharesCmd.PersistentFlags().StringVar(&shareLanguage, "language", "en", "language of the share mnemonics")
+
+	upgradeKeysCmd.PersistentFlags().StringVar(&upgradeKDF, "kdf", "", "key derivation function to re-encrypt the keys: scrypt, argon2id, empty keeps the kdf of each key")
+
+	signMsgProofCmd.PersistentFlags().StringSliceVar(&proofUTXOs, "utxo", nil, "output id of the address signed over as proof of reserves")
+}
+
 var createKeyCmd = &cobra.Command{
 	Use:   "create-key <alias> <password>",
 	Short: "Create a key",
@@ -142,6 +173,244 @@ var checkKeyPwdCmd = &cobra.Command{
 	},
 }
 
+var unlockKeyCmd = &cobra.Command{
+	Use:   "unlock-key <xpub> <password>",
+	Short: "Unlock the key for signing without password until the timeout",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		xpub := new(chainkd.XPub)
+		if err := xpub.UnmarshalText([]byte(args[0])); err != nil {
+			jww.ERROR.Println("unlock-key args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		ins := struct {
+			XPub     chainkd.XPub `json:"xpub"`
+			Password string       `json:"password"`
+			Timeout  uint64       `json:"timeout"`
+			Scopes   []string     `json:"scopes"`
+		}{XPub: *xpub, Password: args[1], Timeout: unlockTimeout, Scopes: unlockScopes}
+
+		data, exitCode := util.ClientCall("/unlock-key", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var lockKeyCmd = &cobra.Command{
+	Use:   "lock-key <xpub>",
+	Short: "Lock the unlocked key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		xpub := new(chainkd.XPub)
+		if err := xpub.UnmarshalText([]byte(args[0])); err != nil {
+			jww.ERROR.Println("lock-key args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		ins := struct {
+			XPub chainkd.XPub `json:"xpub"`
+		}{XPub: *xpub}
+
+		if _, exitCode := util.ClientCall("/lock-key", &ins); exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+		jww.FEEDBACK.Println("Successfully lock key")
+	},
+}
+
+var listUnlockedKeysCmd = &cobra.Command{
+	Use:   "list-unlocked-keys",
+	Short: "List the unlocked keys",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, exitCode := util.ClientCall("/list-unlocked-keys")
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSONList(data)
+	},
+}
+
+var exportKeySharesCmd = &cobra.Command{
+	Use:   "export-key-shares <xpub> <password>",
+	Short: "Export the key as threshold-of-count share mnemonics",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		xpub := new(chainkd.XPub)
+		if err := xpub.UnmarshalText([]byte(args[0])); err != nil {
+			jww.ERROR.Println("export-key-shares args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		ins := struct {
+			XPub      chainkd.XPub `json:"xpub"`
+			Password  string       `json:"password"`
+			Threshold int          `json:"threshold"`
+			Count     int          `json:"count"`
+			Language  string       `json:"language"`
+		}{XPub: *xpub, Password: args[1], Threshold: shareThreshold, Count: shareCount, Language: shareLanguage}
+
+		data, exitCode := util.ClientCall("/export-key-shares", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var importKeySharesCmd = &cobra.Command{
+	Use:   "import-key-shares <alias> <password> <share>...",
+	Short: "Restore a key from share mnemonics, every share is quoted as one argument",
+	Args:  cobra.MinimumNArgs(4),
+	Run: func(cmd *cobra.Command, args []string) {
+		ins := struct {
+			Alias    string   `json:"alias"`
+			Password string   `json:"password"`
+			Shares   []string `json:"shares"`
+			Language string   `json:"language"`
+		}{Alias: args[0], Password: args[1], Shares: args[2:], Language: shareLanguage}
+
+		data, exitCode := util.ClientCall("/import-key-shares", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var checkKeyKDFsCmd = &cobra.Command{
+	Use:   "check-key-kdfs",
+	Short: "Report the key derivation function of every key and whether it is weak",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		data, exitCode := util.ClientCall("/check-key-kdfs")
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSONList(data)
+	},
+}
+
+var upgradeKeysCmd = &cobra.Command{
+	Use:   "upgrade-keys <password>",
+	Short: "Re-encrypt every key with the password by current key derivation parameters",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		ins := struct {
+			Password string `json:"password"`
+			KDF      string `json:"kdf"`
+		}{Password: args[0], KDF: upgradeKDF}
+
+		data, exitCode := util.ClientCall("/upgrade-keys", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSONList(data)
+	},
+}
+
+// parseDerivationPath parses the path written as "m/<hex>/<hex>...", "m"
+// alone is the root key
+func parseDerivationPath(str string) ([]chainjson.HexBytes, error) {
+	elems := strings.Split(str, "/")
+	if elems[0] != "m" {
+		return nil, errors.New("derivation path must start with m")
+	}
+
+	path := []chainjson.HexBytes{}
+	for _, elem := range elems[1:] {
+		b, err := hex.DecodeString(elem)
+		if err != nil || len(b) == 0 {
+			return nil, errors.New("invalid derivation path element " + elem)
+		}
+
+		path = append(path, b)
+	}
+	return path, nil
+}
+
+var deriveXPubCmd = &cobra.Command{
+	Use:   "derive-xpub <xpub> <path>",
+	Short: "Derive the xpub at the path like m/2c000000/99000000",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		xpub := new(chainkd.XPub)
+		if err := xpub.UnmarshalText([]byte(args[0])); err != nil {
+			jww.ERROR.Println("derive-xpub args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		path, err := parseDerivationPath(args[1])
+		if err != nil {
+			jww.ERROR.Println("derive-xpub args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		ins := struct {
+			XPub chainkd.XPub         `json:"xpub"`
+			Path []chainjson.HexBytes `json:"path"`
+		}{XPub: *xpub, Path: path}
+
+		data, exitCode := util.ClientCall("/derive-xpub", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var signWithPathCmd = &cobra.Command{
+	Use:   "sign-with-path <xpub> <path> <message> [password]",
+	Short: "Sign the hex message by the key derived at the path like m/2c000000/99000000",
+	Args:  cobra.RangeArgs(3, 4),
+	Run: func(cmd *cobra.Command, args []string) {
+		xpub := new(chainkd.XPub)
+		if err := xpub.UnmarshalText([]byte(args[0])); err != nil {
+			jww.ERROR.Println("sign-with-path args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		path, err := parseDerivationPath(args[1])
+		if err != nil {
+			jww.ERROR.Println("sign-with-path args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		message, err := hex.DecodeString(args[2])
+		if err != nil {
+			jww.ERROR.Println("sign-with-path args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		ins := struct {
+			XPub     chainkd.XPub         `json:"xpub"`
+			Path     []chainjson.HexBytes `json:"path"`
+			Message  chainjson.HexBytes   `json:"message"`
+			Password string               `json:"password"`
+		}{XPub: *xpub, Path: path, Message: message}
+		if len(args) == 4 {
+			ins.Password = args[3]
+		}
+
+		data, exitCode := util.ClientCall("/sign-with-path", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
 var signMsgCmd = &cobra.Command{
 	Use:   "sign-message <address> <message> <password>",
 	Short: "sign message to generate signature",
@@ -198,3 +467,48 @@ var verifyMsgCmd = &cobra.Command{
 		printJSON(data)
 	},
 }
+
+var signMsgProofCmd = &cobra.Command{
+	Use:   "sign-message-proof <address> <message> <password>",
+	Short: "sign versioned message proving the ownership of the address",
+	Args:  cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		message, err := hex.DecodeString(args[1])
+		if err != nil {
+			jww.ERROR.Println("sign-message-proof args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		var req = struct {
+			Address  string             `json:"address"`
+			Message  chainjson.HexBytes `json:"message"`
+			UTXOs    []string           `json:"utxos"`
+			Password string             `json:"password"`
+		}{Address: args[0], Message: message, UTXOs: proofUTXOs, Password: args[2]}
+
+		data, exitCode := util.ClientCall("/sign-message-proof", &req)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+		printJSON(data)
+	},
+}
+
+var verifyMsgProofCmd = &cobra.Command{
+	Use:   "verify-message-proof <proof json>",
+	Short: "verify versioned signed message against the network of the node",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var req message.SignedMessage
+		if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+			jww.ERROR.Println("verify-message-proof args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		data, exitCode := util.ClientCall("/verify-message-proof", &req)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+		printJSON(data)
+	},
+}
@@ -1,13 +1,23 @@
 package commands
 
 import (
+	"encoding/json"
+	"io/ioutil"
 	"os"
 
 	"github.com/spf13/cobra"
+	jww "github.com/spf13/jwalterweatherman"
 
+	"github.com/bytom/bytom/equity/compiler"
 	"github.com/bytom/bytom/util"
 )
 
+var contractArgs = ""
+
+func init() {
+	compileContractCmd.PersistentFlags().StringVar(&contractArgs, "args", "", `json list of the contract arguments, e.g. '[{"string":"a1b2"},{"integer":100},{"boolean":true}]'`)
+}
+
 var decodeProgCmd = &cobra.Command{
 	Use:   "decode-program <program>",
 	Short: "decode program to instruction and data",
@@ -24,3 +34,34 @@ var decodeProgCmd = &cobra.Command{
 		printJSON(data)
 	},
 }
+
+var compileContractCmd = &cobra.Command{
+	Use:   "compile-contract <file>",
+	Short: "Compile the Equity contract of the file, instantiated with the arguments if given",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		src, err := ioutil.ReadFile(args[0])
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		var req = struct {
+			Contract string                 `json:"contract"`
+			Args     []compiler.ContractArg `json:"args"`
+		}{Contract: string(src)}
+
+		if contractArgs != "" {
+			if err := json.Unmarshal([]byte(contractArgs), &req.Args); err != nil {
+				jww.ERROR.Println(err)
+				os.Exit(util.ErrLocalExe)
+			}
+		}
+
+		data, exitCode := util.ClientCall("/compile-contract", &req)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+		printJSON(data)
+	},
+}
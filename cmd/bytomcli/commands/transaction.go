@@ -29,13 +29,27 @@ func init() {
 	buildTransactionCmd.PersistentFlags().BoolVar(&pretty, "pretty", false, "pretty print json result")
 	buildTransactionCmd.PersistentFlags().BoolVar(&alias, "alias", false, "use alias build transaction")
 
-	signTransactionCmd.PersistentFlags().StringVarP(&password, "password", "p", "", "password of the account which sign these transaction(s)")
+	signTransactionCmd.PersistentFlags().StringVarP(&password, "password", "p", "", "password of the account which sign these transaction(s), sign with the unlocked keys when empty")
 	signTransactionCmd.PersistentFlags().BoolVar(&pretty, "pretty", false, "pretty print json result")
 
 	listTransactionsCmd.PersistentFlags().StringVar(&txID, "id", "", "transaction id")
 	listTransactionsCmd.PersistentFlags().StringVar(&account, "account_id", "", "account id")
 	listTransactionsCmd.PersistentFlags().BoolVar(&detail, "detail", false, "list transactions details")
 	listTransactionsCmd.PersistentFlags().BoolVar(&unconfirmed, "unconfirmed", false, "list unconfirmed transactions")
+	listTransactionsCmd.PersistentFlags().Uint64Var(&txStartHeight, "start_height", 0, "list transactions from the block height")
+	listTransactionsCmd.PersistentFlags().Uint64Var(&txEndHeight, "end_height", 0, "list transactions up to the block height")
+	listTransactionsCmd.PersistentFlags().Uint64Var(&txStartTime, "start_time", 0, "list transactions from the block time")
+	listTransactionsCmd.PersistentFlags().Uint64Var(&txEndTime, "end_time", 0, "list transactions up to the block time")
+	listTransactionsCmd.PersistentFlags().StringVar(&txAssetID, "asset_id", "", "list transactions moving the asset")
+	listTransactionsCmd.PersistentFlags().Uint64Var(&txMinAmount, "min_amount", 0, "list transactions moving at least the amount")
+	listTransactionsCmd.PersistentFlags().Uint64Var(&txMaxAmount, "max_amount", 0, "list transactions moving at most the amount")
+	listTransactionsCmd.PersistentFlags().StringVar(&txDirection, "direction", "", "list transactions by direction, in or out")
+	listTransactionsCmd.PersistentFlags().StringVar(&address, "address", "", "list transactions with the counterparty address")
+	listTransactionsCmd.PersistentFlags().StringSliceVar(&txTypes, "type", nil, "list transactions by type: issue, vote, veto, retire, contract, coinbase")
+	listTransactionsCmd.PersistentFlags().StringVar(&txLabel, "label", "", "list transactions by label or note")
+	listTransactionsCmd.PersistentFlags().StringVar(&txMemo, "memo", "", "list transactions by the decrypted memo, such as a deposit tag")
+	listTransactionsCmd.PersistentFlags().StringVar(&txCursor, "cursor", "", "list transactions after the transaction id")
+	listTransactionsCmd.PersistentFlags().IntVar(&count, "count", 0, "the longest count per page")
 }
 
 var (
@@ -53,6 +67,18 @@ var (
 	arbitrary       = ""
 	program         = ""
 	contractName    = ""
+	txStartHeight   = uint64(0)
+	txEndHeight     = uint64(0)
+	txStartTime     = uint64(0)
+	txEndTime       = uint64(0)
+	txAssetID       = ""
+	txMinAmount     = uint64(0)
+	txMaxAmount     = uint64(0)
+	txDirection     = ""
+	txTypes         = []string{}
+	txLabel         = ""
+	txMemo          = ""
+	txCursor        = ""
 )
 
 var buildIssueReqFmt = `
@@ -217,9 +243,6 @@ var signTransactionCmd = &cobra.Command{
 	Use:   "sign-transaction  <json templates>",
 	Short: "Sign transaction templates with account password",
 	Args:  cobra.ExactArgs(1),
-	PreRun: func(cmd *cobra.Command, args []string) {
-		cmd.MarkFlagRequired("password")
-	},
 	Run: func(cmd *cobra.Command, args []string) {
 		template := txbuilder.Template{}
 
@@ -284,6 +307,54 @@ var submitTransactionCmd = &cobra.Command{
 	},
 }
 
+var simulateTransactionCmd = &cobra.Command{
+	Use:   "simulate-transaction  <json raw_transaction or template>",
+	Short: "Validate the transaction without submitting it and trace the input programs",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			Tx types.Tx `json:"raw_transaction"`
+		}{}
+
+		err := json.Unmarshal([]byte(args[0]), &ins)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		data, exitCode := util.ClientCall("/simulate-transaction", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
+var profileTransactionCmd = &cobra.Command{
+	Use:   "profile-transaction  <json raw_transaction or template>",
+	Short: "Report the gas charged by every opcode and program of the transaction",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			Tx types.Tx `json:"raw_transaction"`
+		}{}
+
+		err := json.Unmarshal([]byte(args[0]), &ins)
+		if err != nil {
+			jww.ERROR.Println(err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		data, exitCode := util.ClientCall("/profile-transaction", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		printJSON(data)
+	},
+}
+
 var estimateTransactionGasCmd = &cobra.Command{
 	Use:   "estimate-transaction-gas  <json templates>",
 	Short: "estimate gas for build transaction",
@@ -358,11 +429,44 @@ var listTransactionsCmd = &cobra.Command{
 	Args:  cobra.NoArgs,
 	Run: func(cmd *cobra.Command, args []string) {
 		filter := struct {
-			ID          string `json:"id"`
-			AccountID   string `json:"account_id"`
-			Detail      bool   `json:"detail"`
-			Unconfirmed bool   `json:"unconfirmed"`
-		}{ID: txID, AccountID: account, Detail: detail, Unconfirmed: unconfirmed}
+			ID          string   `json:"id"`
+			AccountID   string   `json:"account_id"`
+			Detail      bool     `json:"detail"`
+			Unconfirmed bool     `json:"unconfirmed"`
+			StartHeight uint64   `json:"start_height"`
+			EndHeight   uint64   `json:"end_height"`
+			StartTime   uint64   `json:"start_time"`
+			EndTime     uint64   `json:"end_time"`
+			AssetID     string   `json:"asset_id"`
+			MinAmount   uint64   `json:"min_amount"`
+			MaxAmount   uint64   `json:"max_amount"`
+			Direction   string   `json:"direction"`
+			Address     string   `json:"address"`
+			TxTypes     []string `json:"tx_types"`
+			Label       string   `json:"label"`
+			Memo        string   `json:"memo"`
+			Cursor      string   `json:"cursor"`
+			Count       uint     `json:"count"`
+		}{
+			ID:          txID,
+			AccountID:   account,
+			Detail:      detail,
+			Unconfirmed: unconfirmed,
+			StartHeight: txStartHeight,
+			EndHeight:   txEndHeight,
+			StartTime:   txStartTime,
+			EndTime:     txEndTime,
+			AssetID:     txAssetID,
+			MinAmount:   txMinAmount,
+			MaxAmount:   txMaxAmount,
+			Direction:   txDirection,
+			Address:     address,
+			TxTypes:     txTypes,
+			Label:       txLabel,
+			Memo:        txMemo,
+			Cursor:      txCursor,
+			Count:       uint(count),
+		}
 
 		data, exitCode := util.ClientCall("/list-transactions", &filter)
 		if exitCode != util.Success {
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"os"
 
 	"github.com/spf13/cobra"
@@ -9,6 +10,28 @@ import (
 	"github.com/bytom/bytom/util"
 )
 
+func init() {
+	exportHistoryCmd.PersistentFlags().StringVar(&accountID, "account_id", "", "account ID")
+	exportHistoryCmd.PersistentFlags().StringVar(&accountAlias, "account_alias", "", "account alias")
+	exportHistoryCmd.PersistentFlags().StringVar(&exportFormat, "format", "json", "export format, json or csv")
+	exportHistoryCmd.PersistentFlags().Uint64Var(&txStartTime, "start_time", 0, "export entries from the block time")
+	exportHistoryCmd.PersistentFlags().Uint64Var(&txEndTime, "end_time", 0, "export entries up to the block time")
+	exportHistoryCmd.PersistentFlags().BoolVar(&costBasis, "cost_basis", false, "include FIFO cost-basis lots")
+
+	generateReserveProofCmd.PersistentFlags().StringSliceVar(&reserveAccountIDs, "account_id", nil, "account ID of the reserves")
+	generateReserveProofCmd.PersistentFlags().StringSliceVar(&reserveAccountAliases, "account_alias", nil, "account alias of the reserves")
+	generateReserveProofCmd.PersistentFlags().Uint64Var(&reserveBlockHeight, "block_height", 0, "snapshot block height, the best block when zero")
+}
+
+var (
+	exportFormat = "json"
+	costBasis    = false
+
+	reserveAccountIDs     []string
+	reserveAccountAliases []string
+	reserveBlockHeight    uint64
+)
+
 var walletInfoCmd = &cobra.Command{
 	Use:   "wallet-info",
 	Short: "Print the information of wallet",
@@ -34,3 +57,83 @@ var rescanWalletCmd = &cobra.Command{
 		jww.FEEDBACK.Println("Successfully trigger rescanning wallet")
 	},
 }
+
+var exportHistoryCmd = &cobra.Command{
+	Use:   "export-history",
+	Short: "Export the accounting ledger of the wallet accounts",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			AccountID    string `json:"account_id"`
+			AccountAlias string `json:"account_alias"`
+			Format       string `json:"format"`
+			StartTime    uint64 `json:"start_time"`
+			EndTime      uint64 `json:"end_time"`
+			CostBasis    bool   `json:"cost_basis"`
+		}{
+			AccountID:    accountID,
+			AccountAlias: accountAlias,
+			Format:       exportFormat,
+			StartTime:    txStartTime,
+			EndTime:      txEndTime,
+			CostBasis:    costBasis,
+		}
+
+		data, exitCode := util.ClientCall("/export-wallet-history", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+
+		if exportFormat == "csv" {
+			if dataMap, ok := data.(map[string]interface{}); ok {
+				jww.FEEDBACK.Print(dataMap["csv"])
+				return
+			}
+		}
+		printJSON(data)
+	},
+}
+
+var generateReserveProofCmd = &cobra.Command{
+	Use:   "generate-reserve-proof <password>",
+	Short: "Generate the proof of reserves of the accounts at the snapshot block",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var ins = struct {
+			AccountIDs     []string `json:"account_ids"`
+			AccountAliases []string `json:"account_aliases"`
+			BlockHeight    uint64   `json:"block_height"`
+			Password       string   `json:"password"`
+		}{
+			AccountIDs:     reserveAccountIDs,
+			AccountAliases: reserveAccountAliases,
+			BlockHeight:    reserveBlockHeight,
+			Password:       args[0],
+		}
+
+		data, exitCode := util.ClientCall("/generate-reserve-proof", &ins)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+		printJSON(data)
+	},
+}
+
+var verifyReserveProofCmd = &cobra.Command{
+	Use:   "verify-reserve-proof <proof json>",
+	Short: "Verify the proof of reserves against the main chain of the node",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var proof json.RawMessage
+		if err := json.Unmarshal([]byte(args[0]), &proof); err != nil {
+			jww.ERROR.Println("verify-reserve-proof args not valid:", err)
+			os.Exit(util.ErrLocalExe)
+		}
+
+		data, exitCode := util.ClientCall("/verify-reserve-proof", &proof)
+		if exitCode != util.Success {
+			os.Exit(exitCode)
+		}
+		printJSON(data)
+	},
+}
@@ -29,6 +29,7 @@ func init() {
 	runNodeCmd.Flags().Bool("vault_mode", config.VaultMode, "Run in the offline enviroment")
 	runNodeCmd.Flags().Bool("web.closed", config.Web.Closed, "Lanch web browser or not")
 	runNodeCmd.Flags().String("chain_id", config.ChainID, "Select network type")
+	runNodeCmd.Flags().String("gas_profile_dir", config.GasProfilePath, "Dump the gas profile of every connected block to the directory")
 
 	// log level
 	runNodeCmd.Flags().String("log_level", config.LogLevel, "Select log level(debug, info, warn, error or fatal)")
@@ -46,6 +47,17 @@ func init() {
 	runNodeCmd.Flags().String("p2p.proxy_password", config.P2P.ProxyPassword, "Password for proxy server")
 	runNodeCmd.Flags().String("p2p.keep_dial", config.P2P.KeepDial, "Peers addresses try keeping connecting to, separated by ',' (for example \"1.1.1.1:46657;2.2.2.2:46658\")")
 
+	// remote signer flags
+	runNodeCmd.Flags().String("signer.url", config.Signer.URL, "URL of the remote signing daemon holding the keys")
+	runNodeCmd.Flags().String("signer.token", config.Signer.Token, "Access token of the remote signing daemon")
+	runNodeCmd.Flags().String("signer.tls_cert", config.Signer.TLSCert, "Client certificate file for the remote signing daemon")
+	runNodeCmd.Flags().String("signer.tls_key", config.Signer.TLSKey, "Client key file for the remote signing daemon")
+	runNodeCmd.Flags().String("signer.tls_ca", config.Signer.TLSCA, "CA certificate file verifying the remote signing daemon")
+	runNodeCmd.Flags().String("signer.validator_xpub", config.Signer.ValidatorXPub, "Key of the remote signing daemon proposing blocks")
+	runNodeCmd.Flags().String("signer.path_policy", config.Signer.PathPolicy, "Signer policy file allowing the keys and paths of arbitrary path derivation and signing, denied when empty")
+	runNodeCmd.Flags().String("signer.frost_group", config.Signer.FrostGroup, "Group file of the threshold validator key proposing blocks")
+	runNodeCmd.Flags().String("signer.frost_holders", config.Signer.FrostHolders, "Comma separated urls of the threshold key share holders")
+
 	// log flags
 	runNodeCmd.Flags().String("log_file", config.LogFile, "Log output file")
 
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/bytom/bytom/blockchain/pseudohsm"
+	"github.com/bytom/bytom/blockchain/signer"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/crypto/ed25519/ecmath"
+	"github.com/bytom/bytom/crypto/frost"
+)
+
+var (
+	frostThreshold int
+	frostCount     int
+	frostOutDir    string
+	frostKeystore  string
+	frostXPub      string
+	frostPassword  string
+	frostShareFile string
+	frostStateFile string
+	frostAddr      string
+)
+
+var frostSplitCmd = &cobra.Command{
+	Use:   "frost-split",
+	Short: "Split a new or an existing validator key into t-of-n threshold key shares",
+	Args:  cobra.NoArgs,
+	RunE:  runFrostSplit,
+}
+
+var frostHolderCmd = &cobra.Command{
+	Use:   "frost-holder",
+	Short: "Serve one threshold key share to the block signing coordinator",
+	Args:  cobra.NoArgs,
+	RunE:  runFrostHolder,
+}
+
+func init() {
+	frostSplitCmd.Flags().IntVar(&frostThreshold, "threshold", 2, "Number of the share holders required to sign")
+	frostSplitCmd.Flags().IntVar(&frostCount, "count", 3, "Number of the key shares")
+	frostSplitCmd.Flags().StringVar(&frostOutDir, "out_dir", "frost", "Directory written with group.json and the share-<id>.json files")
+	frostSplitCmd.Flags().StringVar(&frostKeystore, "keystore", "keystore", "Directory of the encrypted key to split")
+	frostSplitCmd.Flags().StringVar(&frostXPub, "xpub", "", "Existing key to split, a new key is generated when empty")
+	frostSplitCmd.Flags().StringVar(&frostPassword, "password", "", "Password of the existing key")
+
+	frostHolderCmd.Flags().StringVar(&frostShareFile, "share", "share.json", "Key share file of the holder")
+	frostHolderCmd.Flags().StringVar(&frostStateFile, "state", "frost-state.json", "File keeping the last signed block and verification heights")
+	frostHolderCmd.Flags().StringVar(&frostAddr, "laddr", "127.0.0.1:9890", "Listen address of the holder")
+	frostHolderCmd.Flags().StringVar(&accessToken, "token", "", "Access token the coordinator must present")
+	frostHolderCmd.Flags().StringVar(&tlsCert, "tls_cert", "", "Server certificate file, plain http is only served on the loopback address when empty")
+	frostHolderCmd.Flags().StringVar(&tlsKey, "tls_key", "", "Server key file")
+	frostHolderCmd.Flags().StringVar(&tlsClientCA, "tls_client_ca", "", "CA certificate file verifying the client certificates, enables mutual TLS")
+
+	RootCmd.AddCommand(frostSplitCmd, frostHolderCmd)
+}
+
+// splitSecret returns the scalar and the chain code of the validator key
+func splitSecret() (*ecmath.Scalar, []byte, error) {
+	var xprv chainkd.XPrv
+	if frostXPub == "" {
+		var err error
+		if xprv, err = chainkd.NewXPrv(rand.Reader); err != nil {
+			return nil, nil, err
+		}
+	} else {
+		var xpub chainkd.XPub
+		if err := xpub.UnmarshalText([]byte(frostXPub)); err != nil {
+			return nil, nil, err
+		}
+
+		hsm, err := pseudohsm.New(frostKeystore)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if xprv, err = hsm.LoadChainKDKey(xpub, frostPassword); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var secret ecmath.Scalar
+	copy(secret[:], xprv[:32])
+	return &secret, xprv[32:], nil
+}
+
+func writeJSONFile(file string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(file, data, 0600)
+}
+
+func runFrostSplit(cmd *cobra.Command, args []string) error {
+	secret, chainCode, err := splitSecret()
+	if err != nil {
+		return err
+	}
+
+	pkg, shares, err := frost.SplitKey(secret, frostThreshold, frostCount)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(frostOutDir, 0700); err != nil {
+		return err
+	}
+
+	group := &signer.FrostGroup{Package: pkg}
+	copy(group.XPub[:32], pkg.GroupKey[:])
+	copy(group.XPub[32:], chainCode)
+	if err := writeJSONFile(filepath.Join(frostOutDir, "group.json"), group); err != nil {
+		return err
+	}
+
+	for _, share := range shares {
+		file := filepath.Join(frostOutDir, fmt.Sprintf("share-%d.json", share.ID))
+		if err := writeJSONFile(file, &signer.FrostShareFile{XPub: group.XPub, Share: share}); err != nil {
+			return err
+		}
+	}
+
+	log.WithFields(log.Fields{"module": logModule, "xpub": group.XPub.String(), "threshold": frostThreshold, "count": frostCount, "dir": frostOutDir}).Info("validator key split into threshold shares")
+	return nil
+}
+
+func runFrostHolder(cmd *cobra.Command, args []string) error {
+	if accessToken == "" && tlsClientCA == "" {
+		log.WithField("module", logModule).Fatal("either token or tls_client_ca is required to authenticate the coordinator")
+	}
+
+	if tlsClientCA != "" && tlsCert == "" {
+		log.WithField("module", logModule).Fatal("tls_client_ca requires tls_cert and tls_key")
+	}
+
+	if tlsCert == "" && !isLoopback(frostAddr) {
+		log.WithField("module", logModule).Fatal("tls_cert and tls_key are required unless listening on the loopback address")
+	}
+
+	shareFile, err := signer.LoadFrostShare(frostShareFile)
+	if err != nil {
+		return err
+	}
+
+	holder, err := signer.NewFrostHolder(shareFile.Share, accessToken, frostStateFile)
+	if err != nil {
+		return err
+	}
+
+	server := &http.Server{Addr: frostAddr, Handler: holder}
+	log.WithFields(log.Fields{"module": logModule, "address": frostAddr, "xpub": shareFile.XPub.String(), "id": shareFile.Share.ID}).Info("threshold key share holder started")
+	if tlsCert == "" {
+		return server.ListenAndServe()
+	}
+
+	if server.TLSConfig, err = signer.NewTLSServerConfig(tlsCert, tlsKey, tlsClientCA); err != nil {
+		return err
+	}
+	return server.ListenAndServeTLS("", "")
+}
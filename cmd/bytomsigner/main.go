@@ -0,0 +1,115 @@
+package main
+
+import (
+	"net"
+	"net/http"
+	"os"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/tendermint/tmlibs/cli"
+
+	"github.com/bytom/bytom/blockchain/pseudohsm"
+	"github.com/bytom/bytom/blockchain/signer"
+)
+
+const logModule = "bytomsigner"
+
+var (
+	keystorePath string
+	listenAddr   string
+	accessToken  string
+	tlsCert      string
+	tlsKey       string
+	tlsClientCA  string
+	policyFile   string
+	auditLogFile string
+)
+
+// RootCmd is the command of the remote signing daemon
+var RootCmd = &cobra.Command{
+	Use:   "bytomsigner",
+	Short: "Remote signing daemon holding the encrypted keys away from bytomd",
+	RunE:  runSigner,
+}
+
+func init() {
+	RootCmd.Flags().StringVar(&keystorePath, "keystore", "keystore", "Directory of the encrypted keys")
+	RootCmd.Flags().StringVar(&listenAddr, "laddr", "127.0.0.1:9889", "Listen address of the daemon")
+	RootCmd.Flags().StringVar(&accessToken, "token", "", "Access token the clients must present")
+	RootCmd.Flags().StringVar(&tlsCert, "tls_cert", "", "Server certificate file, plain http is only served on the loopback address when empty")
+	RootCmd.Flags().StringVar(&tlsKey, "tls_key", "", "Server key file")
+	RootCmd.Flags().StringVar(&tlsClientCA, "tls_client_ca", "", "CA certificate file verifying the client certificates, enables mutual TLS")
+	RootCmd.Flags().StringVar(&policyFile, "policy", "policy.json", "Policy file with the allowed keys, paths and rate limits")
+	RootCmd.Flags().StringVar(&auditLogFile, "audit_log", "audit.log", "File appended with a json line for every signing request")
+}
+
+func runSigner(cmd *cobra.Command, args []string) error {
+	if accessToken == "" && tlsClientCA == "" {
+		log.WithField("module", logModule).Fatal("either token or tls_client_ca is required to authenticate the clients")
+	}
+
+	if tlsClientCA != "" && tlsCert == "" {
+		log.WithField("module", logModule).Fatal("tls_client_ca requires tls_cert and tls_key")
+	}
+
+	if tlsCert == "" && !isLoopback(listenAddr) {
+		log.WithField("module", logModule).Fatal("tls_cert and tls_key are required unless listening on the loopback address")
+	}
+
+	hsm, err := pseudohsm.New(keystorePath)
+	if err != nil {
+		return err
+	}
+
+	policy, err := signer.LoadPolicyConfig(policyFile)
+	if err != nil {
+		return err
+	}
+
+	policySigner, err := signer.NewPolicySigner(signer.NewLocalSigner(hsm), policy)
+	if err != nil {
+		return err
+	}
+
+	auditLog, err := os.OpenFile(auditLogFile, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer auditLog.Close()
+
+	handler := signer.NewServer(policySigner, accessToken)
+	handler.SetAuditLog(auditLog)
+	server := &http.Server{Addr: listenAddr, Handler: handler}
+
+	log.WithFields(log.Fields{"module": logModule, "address": listenAddr, "keys": len(policy.Keys)}).Info("signing daemon started")
+	if tlsCert == "" {
+		return server.ListenAndServe()
+	}
+
+	if server.TLSConfig, err = signer.NewTLSServerConfig(tlsCert, tlsKey, tlsClientCA); err != nil {
+		return err
+	}
+	return server.ListenAndServeTLS("", "")
+}
+
+// isLoopback reports whether the listen address only accepts local
+// connections, plain http is refused on any other address
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+
+	if host == "localhost" {
+		return true
+	}
+
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func main() {
+	cmd := cli.PrepareBaseCmd(RootCmd, "SIGNER", "./")
+	cmd.Execute()
+}
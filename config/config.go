@@ -11,6 +11,8 @@ import (
 	log "github.com/sirupsen/logrus"
 
 	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
 )
 
 var (
@@ -27,6 +29,31 @@ type Config struct {
 	Auth      *RPCAuthConfig   `mapstructure:"auth"`
 	Web       *WebConfig       `mapstructure:"web"`
 	Websocket *WebsocketConfig `mapstructure:"ws"`
+	Signer    *SignerConfig    `mapstructure:"signer"`
+
+	validatorXPub   *chainkd.XPub
+	validatorSignFn ValidatorSignFunc
+}
+
+// ValidatorSignFunc signs the message with the validator key, the request
+// tells what the message is the hash of
+type ValidatorSignFunc func(msg []byte, req *ValidatorSignRequest) ([]byte, error)
+
+// ValidatorSignRequest is what the validator key signs, either a block header
+// or a casper verification, the signers holding the key away from the node
+// rebuild the message from it instead of signing a bare hash
+type ValidatorSignRequest struct {
+	BlockHeader  *types.BlockHeader `json:"block_header,omitempty"`
+	Verification *Verification      `json:"verification,omitempty"`
+}
+
+// Verification is the casper vote of the validator from the source
+// checkpoint to the target checkpoint
+type Verification struct {
+	SourceHash   bc.Hash `json:"source_hash"`
+	TargetHash   bc.Hash `json:"target_hash"`
+	SourceHeight uint64  `json:"source_height"`
+	TargetHeight uint64  `json:"target_height"`
 }
 
 // Default configurable parameters.
@@ -38,6 +65,7 @@ func DefaultConfig() *Config {
 		Auth:       DefaultRPCAuthConfig(),
 		Web:        DefaultWebConfig(),
 		Websocket:  DefaultWebsocketConfig(),
+		Signer:     DefaultSignerConfig(),
 	}
 }
 
@@ -78,6 +106,29 @@ func (cfg *Config) PrivateKey() *chainkd.XPrv {
 	return cfg.XPrv
 }
 
+// SetValidatorSigner makes the node sign the blocks and the casper
+// verifications by signFn with the key of xpub instead of the node key
+func (cfg *Config) SetValidatorSigner(xpub chainkd.XPub, signFn ValidatorSignFunc) {
+	cfg.validatorXPub = &xpub
+	cfg.validatorSignFn = signFn
+}
+
+// ValidatorXPub returns the xpub of the key signing the blocks
+func (cfg *Config) ValidatorXPub() chainkd.XPub {
+	if cfg.validatorXPub != nil {
+		return *cfg.validatorXPub
+	}
+	return cfg.PrivateKey().XPub()
+}
+
+// ValidatorSign signs the message with the validator key
+func (cfg *Config) ValidatorSign(msg []byte, req *ValidatorSignRequest) ([]byte, error) {
+	if cfg.validatorSignFn != nil {
+		return cfg.validatorSignFn(msg, req)
+	}
+	return cfg.PrivateKey().Sign(msg), nil
+}
+
 // -----------------------------------------------------------------------------
 // BaseConfig
 type BaseConfig struct {
@@ -118,6 +169,10 @@ type BaseConfig struct {
 	// log file name
 	LogFile string `mapstructure:"log_file"`
 
+	// Directory the gas profiles of the connected blocks are dumped to,
+	// empty disables the gas profiling
+	GasProfilePath string `mapstructure:"gas_profile_dir"`
+
 	PrivateKeyFile string `mapstructure:"private_key_file"`
 	XPrv           *chainkd.XPrv
 	XPub           *chainkd.XPub
@@ -142,6 +197,13 @@ func (b BaseConfig) DBDir() string {
 	return rootify(b.DBPath, b.RootDir)
 }
 
+func (b BaseConfig) GasProfileDir() string {
+	if b.GasProfilePath == "" {
+		return ""
+	}
+	return rootify(b.GasProfilePath, b.RootDir)
+}
+
 func (b BaseConfig) LogDir() string {
 	return rootify(b.LogFile, b.RootDir)
 }
@@ -188,6 +250,31 @@ type WalletConfig struct {
 	MaxTxFee uint64 `mapstructure:"max_tx_fee"`
 }
 
+// SignerConfig is the connection to the remote signing daemon, the local
+// keystore and node key are used when the url is empty
+type SignerConfig struct {
+	URL     string `mapstructure:"url"`
+	Token   string `mapstructure:"token"`
+	TLSCert string `mapstructure:"tls_cert"`
+	TLSKey  string `mapstructure:"tls_key"`
+	TLSCA   string `mapstructure:"tls_ca"`
+
+	// ValidatorXPub is the daemon key proposing blocks, the node key is
+	// used when it is empty
+	ValidatorXPub string `mapstructure:"validator_xpub"`
+
+	// PathPolicy is the signer policy file restricting the keys and paths of
+	// the derive-xpub and sign-with-path api, both are denied when it is
+	// empty, and the paths of the wallet accounts and assets are never allowed
+	PathPolicy string `mapstructure:"path_policy"`
+
+	// FrostGroup is the group file of a threshold validator key, the blocks
+	// are signed by the share holders listening on FrostHolders, a comma
+	// separated url list sharing the token and tls settings of the signer
+	FrostGroup   string `mapstructure:"frost_group"`
+	FrostHolders string `mapstructure:"frost_holders"`
+}
+
 type RPCAuthConfig struct {
 	Disable bool `mapstructure:"disable"`
 }
@@ -225,6 +312,11 @@ func DefaultWalletConfig() *WalletConfig {
 	}
 }
 
+// Default configurable remote signer parameters.
+func DefaultSignerConfig() *SignerConfig {
+	return &SignerConfig{}
+}
+
 func DefaultWebsocketConfig() *WebsocketConfig {
 	return &WebsocketConfig{
 		MaxNumWebsockets:     25,
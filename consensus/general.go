@@ -37,6 +37,10 @@ const (
 
 	BCRPRequiredBTMAmount = uint64(100000000)
 
+	// LockTimeThreshold splits the transaction lock time, a lock time below it
+	// is a block height, otherwise it's a block timestamp in milliseconds
+	LockTimeThreshold = uint64(500000000000)
+
 	BTMAlias = "BTM"
 	defaultVotePendingNum = 302400
 )
@@ -66,6 +70,13 @@ type VotePendingBlockNum struct {
 	Num        uint64
 }
 
+// VMVersionActivation is the height from which the programs of the VM version
+// are valid, VM version 1 is valid from the genesis block
+type VMVersionActivation struct {
+	Version uint64
+	Height  uint64
+}
+
 // BTMAssetID is BTM's asset id, the soul asset of Bytom
 var BTMAssetID = &bc.AssetID{
 	V0: binary.BigEndian.Uint64([]byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff}),
@@ -104,6 +115,13 @@ type Params struct {
 
 	// CasperConfig defines the casper consensus parameters
 	CasperConfig
+
+	// VMVersionActivations defines the activation heights of the VM versions above 1
+	VMVersionActivations []VMVersionActivation
+
+	// LockTimeActivationHeight is the height from which the transactions can
+	// carry lock time and input sequences, math.MaxUint64 means not scheduled
+	LockTimeActivationHeight uint64
 }
 
 // ActiveNetParams is ...
@@ -138,6 +156,7 @@ var MainNetParams = Params{
 			xpub("1313379b05c38ff2d171d512f23f199f0f068a67d77b9d5b6db040f2da1edc0c35c68a21b068956f448fed6441b9c27294f1ca6aaedc2c580de322f3f0260c1f"),
 		},
 	},
+	LockTimeActivationHeight: math.MaxUint64,
 }
 
 // TestNetParams is the config for test-net
@@ -159,6 +178,7 @@ var TestNetParams = Params{
 			xpub("b0584ecaefc02d3c367f280e128ec310c9f9198d44cd76b6726cd6c06c002770a1a7dc069ddd06f7a821a176931573d40e63b015ce88b6de01a61205d719567f"),
 		},
 	},
+	LockTimeActivationHeight: math.MaxUint64,
 }
 
 // SoloNetParams is the config for test-net
@@ -173,6 +193,8 @@ var SoloNetParams = Params{
 		VotePendingBlockNums:   []VotePendingBlockNum{{BeginBlock: 0, EndBlock: math.MaxUint64, Num: 10}},
 		FederationXpubs:        []chainkd.XPub{},
 	},
+	VMVersionActivations:     []VMVersionActivation{{Version: 2, Height: 0}},
+	LockTimeActivationHeight: 0,
 }
 
 func VotePendingBlockNums(height uint64) uint64 {
@@ -184,6 +206,36 @@ func VotePendingBlockNums(height uint64) uint64 {
 	return defaultVotePendingNum
 }
 
+// IsVMVersionActive returns whether the programs of the VM version are valid
+// in the block of the height
+func IsVMVersionActive(version uint64, height uint64) bool {
+	if version == 1 {
+		return true
+	}
+
+	for _, activation := range ActiveNetParams.VMVersionActivations {
+		if activation.Version == version {
+			return height >= activation.Height
+		}
+	}
+	return false
+}
+
+// IsLockTimeActive returns whether the transactions in the block of the height
+// can carry lock time and input sequences
+func IsLockTimeActive(height uint64) bool {
+	return height >= ActiveNetParams.LockTimeActivationHeight
+}
+
+// IsLockTimeReached returns whether the transaction lock time is reached by
+// the block of the height and the timestamp, a zero lock time never locks
+func IsLockTimeReached(lockTime, height, timestamp uint64) bool {
+	if lockTime < LockTimeThreshold {
+		return lockTime <= height
+	}
+	return lockTime <= timestamp
+}
+
 // InitActiveNetParams load the config by chain ID
 func InitActiveNetParams(chainID string) error {
 	var exist bool
@@ -0,0 +1,489 @@
+package contract
+
+import (
+	"encoding/json"
+	"sync"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/bytom/bytom/consensus/bcrp"
+	"github.com/bytom/bytom/crypto/sha3pool"
+	dbm "github.com/bytom/bytom/database/leveldb"
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+var (
+	registeredContractPrefix = []byte("RC:")
+	contractStatsPrefix      = []byte("RS:")
+	lockedUTXOPrefix         = []byte("RU:")
+	bcrpIndexStatusKey       = []byte("RI")
+)
+
+// ErrRegisteredContractNotFound is returned for a contract not registered on
+// chain
+var ErrRegisteredContractNotFound = errors.New("registered contract not found")
+
+func registeredContractKey(hash []byte) []byte {
+	return append(append([]byte{}, registeredContractPrefix...), hash...)
+}
+
+func contractStatsKey(hash []byte) []byte {
+	return append(append([]byte{}, contractStatsPrefix...), hash...)
+}
+
+func lockedUTXOKey(hash []byte, outputID bc.Hash) []byte {
+	return append(append(append([]byte{}, lockedUTXOPrefix...), hash...), outputID.Bytes()...)
+}
+
+// RegisteredContract is a contract registered on chain by BCRP, with the
+// registrant being the control program of the first input of the register
+// transaction
+type RegisteredContract struct {
+	Hash        chainjson.HexBytes `json:"id"`
+	Contract    chainjson.HexBytes `json:"contract"`
+	CallProgram chainjson.HexBytes `json:"call_program"`
+	TxID        bc.Hash            `json:"register_tx_id"`
+	BlockHeight uint64             `json:"register_block_height"`
+	BlockHash   bc.Hash            `json:"register_block_hash"`
+	Registrant  chainjson.HexBytes `json:"registrant"`
+	CallCount   uint64             `json:"call_count"`
+	UTXOCount   uint64             `json:"utxo_count"`
+}
+
+// contractStats is kept apart from the registration, the outputs locked
+// under a call program may come before the contract is registered
+type contractStats struct {
+	CallCount uint64 `json:"call_count"`
+	UTXOCount uint64 `json:"utxo_count"`
+}
+
+// LockedUTXO is an unspent output locked under the call program of a contract
+type LockedUTXO struct {
+	OutputID  bc.Hash              `json:"id"`
+	AssetID   bc.AssetID           `json:"asset_id"`
+	Amount    uint64               `json:"amount"`
+	StateData []chainjson.HexBytes `json:"state_data"`
+}
+
+// BCRPIndex indexes the contracts registered by BCRP from the genesis block,
+// with the calls of them and the outputs locked under their call programs
+type BCRPIndex struct {
+	sync.RWMutex
+	db     dbm.DB
+	chain  ChainService
+	status *ChainStatus
+}
+
+// NewBCRPIndex creates the index, it's brought up to date by Sync
+func NewBCRPIndex(db dbm.DB, chain ChainService) (*BCRPIndex, error) {
+	index := &BCRPIndex{db: db, chain: chain}
+	if data := db.Get(bcrpIndexStatusKey); data != nil {
+		index.status = &ChainStatus{}
+		return index, json.Unmarshal(data, index.status)
+	}
+
+	genesis, err := chain.GetBlockByHeight(0)
+	if err != nil {
+		return nil, err
+	}
+
+	index.status = &ChainStatus{BlockHeight: 0, BlockHash: genesis.Hash()}
+	return index, nil
+}
+
+// Sync follows the best chain, detaching the blocks of a fork
+func (b *BCRPIndex) Sync() {
+	for {
+		status := b.chainStatus()
+		block, _ := b.chain.GetBlockByHeight(status.BlockHeight + 1)
+		if block == nil {
+			<-b.chain.BlockWaiter(status.BlockHeight + 1)
+			continue
+		}
+
+		if block.PreviousBlockHash != status.BlockHash {
+			block, err := b.chain.GetBlockByHash(&status.BlockHash)
+			if err != nil {
+				log.WithFields(log.Fields{"module": logModule, "err": err, "block_hash": status.BlockHash.String()}).Error("bcrp index get block")
+				return
+			}
+
+			if err := b.DetachBlock(block); err != nil {
+				log.WithFields(log.Fields{"module": logModule, "err": err}).Error("bcrp index detach block")
+				return
+			}
+		} else if err := b.ApplyBlock(block); err != nil {
+			log.WithFields(log.Fields{"module": logModule, "err": err}).Error("bcrp index attach block")
+			return
+		}
+	}
+}
+
+func (b *BCRPIndex) chainStatus() ChainStatus {
+	b.RLock()
+	defer b.RUnlock()
+	return *b.status
+}
+
+// indexBatch caches the records changed by a block before they are written
+type indexBatch struct {
+	db        dbm.DB
+	batch     dbm.Batch
+	contracts map[string]*RegisteredContract
+	deleted   map[string]bool
+	stats     map[string]*contractStats
+}
+
+func (b *BCRPIndex) newBatch() *indexBatch {
+	return &indexBatch{
+		db:        b.db,
+		batch:     b.db.NewBatch(),
+		contracts: map[string]*RegisteredContract{},
+		deleted:   map[string]bool{},
+		stats:     map[string]*contractStats{},
+	}
+}
+
+func (ib *indexBatch) contract(hash []byte) (*RegisteredContract, error) {
+	if ib.deleted[string(hash)] {
+		return nil, nil
+	}
+
+	if contract, ok := ib.contracts[string(hash)]; ok {
+		return contract, nil
+	}
+
+	data := ib.db.Get(registeredContractKey(hash))
+	if data == nil {
+		return nil, nil
+	}
+
+	contract := &RegisteredContract{}
+	return contract, json.Unmarshal(data, contract)
+}
+
+func (ib *indexBatch) contractStats(hash []byte) (*contractStats, error) {
+	if stats, ok := ib.stats[string(hash)]; ok {
+		return stats, nil
+	}
+
+	stats := &contractStats{}
+	ib.stats[string(hash)] = stats
+	if data := ib.db.Get(contractStatsKey(hash)); data != nil {
+		return stats, json.Unmarshal(data, stats)
+	}
+	return stats, nil
+}
+
+func (ib *indexBatch) lockUTXO(hash []byte, utxo *LockedUTXO) error {
+	stats, err := ib.contractStats(hash)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(utxo)
+	if err != nil {
+		return err
+	}
+
+	stats.UTXOCount++
+	ib.batch.Set(lockedUTXOKey(hash, utxo.OutputID), data)
+	return nil
+}
+
+func (ib *indexBatch) unlockUTXO(hash []byte, outputID bc.Hash) error {
+	stats, err := ib.contractStats(hash)
+	if err != nil {
+		return err
+	}
+
+	stats.UTXOCount--
+	ib.batch.Delete(lockedUTXOKey(hash, outputID))
+	return nil
+}
+
+func (ib *indexBatch) write(status *ChainStatus) error {
+	for hash, contract := range ib.contracts {
+		data, err := json.Marshal(contract)
+		if err != nil {
+			return err
+		}
+		ib.batch.Set(registeredContractKey([]byte(hash)), data)
+	}
+
+	for hash := range ib.deleted {
+		ib.batch.Delete(registeredContractKey([]byte(hash)))
+	}
+
+	for hash, stats := range ib.stats {
+		data, err := json.Marshal(stats)
+		if err != nil {
+			return err
+		}
+		ib.batch.Set(contractStatsKey([]byte(hash)), data)
+	}
+
+	data, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+
+	ib.batch.Set(bcrpIndexStatusKey, data)
+	ib.batch.Write()
+	return nil
+}
+
+func stateDataJSON(stateData [][]byte) []chainjson.HexBytes {
+	result := []chainjson.HexBytes{}
+	for _, data := range stateData {
+		result = append(result, data)
+	}
+	return result
+}
+
+func callContractHash(program []byte) ([]byte, bool) {
+	if !bcrp.IsCallContractScript(program) {
+		return nil, false
+	}
+
+	hash, err := bcrp.ParseContractHash(program)
+	if err != nil {
+		return nil, false
+	}
+	return hash[:], true
+}
+
+// ApplyBlock indexes the registrations, the calls and the locked outputs of
+// the block, only the first registration of a contract counts as the chain
+// does
+func (b *BCRPIndex) ApplyBlock(block *types.Block) error {
+	b.Lock()
+	defer b.Unlock()
+
+	ib := b.newBatch()
+	blockHash := block.Hash()
+	for _, tx := range block.Transactions {
+		for _, input := range tx.Inputs {
+			spend, ok := input.TypedInput.(*types.SpendInput)
+			if !ok {
+				continue
+			}
+
+			hash, ok := callContractHash(spend.ControlProgram)
+			if !ok {
+				continue
+			}
+
+			outputID, err := input.SpentOutputID()
+			if err != nil {
+				return err
+			}
+
+			if err := ib.unlockUTXO(hash, outputID); err != nil {
+				return err
+			}
+
+			stats, err := ib.contractStats(hash)
+			if err != nil {
+				return err
+			}
+			stats.CallCount++
+		}
+
+		for i, output := range tx.Outputs {
+			if hash, ok := callContractHash(output.ControlProgram); ok {
+				utxo := &LockedUTXO{OutputID: *tx.OutputID(i), AssetID: *output.AssetId, Amount: output.Amount, StateData: stateDataJSON(output.StateData)}
+				if err := ib.lockUTXO(hash, utxo); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !bcrp.IsBCRPScript(output.ControlProgram) {
+				continue
+			}
+
+			program, err := bcrp.ParseContract(output.ControlProgram)
+			if err != nil {
+				return err
+			}
+
+			var hash [32]byte
+			sha3pool.Sum256(hash[:], program)
+			if contract, err := ib.contract(hash[:]); err != nil || contract != nil {
+				continue
+			}
+
+			callProgram, err := vmutil.CallContractProgram(hash[:])
+			if err != nil {
+				return err
+			}
+
+			contract := &RegisteredContract{Hash: hash[:], Contract: program, CallProgram: callProgram, TxID: tx.ID, BlockHeight: block.Height, BlockHash: blockHash}
+			if len(tx.Inputs) > 0 {
+				contract.Registrant = tx.Inputs[0].ControlProgram()
+			}
+
+			delete(ib.deleted, string(hash[:]))
+			ib.contracts[string(hash[:])] = contract
+		}
+	}
+
+	status := &ChainStatus{BlockHeight: block.Height, BlockHash: blockHash}
+	if err := ib.write(status); err != nil {
+		return err
+	}
+
+	b.status = status
+	return nil
+}
+
+// DetachBlock reverts the block indexed by ApplyBlock
+func (b *BCRPIndex) DetachBlock(block *types.Block) error {
+	b.Lock()
+	defer b.Unlock()
+
+	ib := b.newBatch()
+	for i := len(block.Transactions) - 1; i >= 0; i-- {
+		tx := block.Transactions[i]
+		for j, output := range tx.Outputs {
+			if hash, ok := callContractHash(output.ControlProgram); ok {
+				if err := ib.unlockUTXO(hash, *tx.OutputID(j)); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if !bcrp.IsBCRPScript(output.ControlProgram) {
+				continue
+			}
+
+			program, err := bcrp.ParseContract(output.ControlProgram)
+			if err != nil {
+				return err
+			}
+
+			var hash [32]byte
+			sha3pool.Sum256(hash[:], program)
+			contract, err := ib.contract(hash[:])
+			if err != nil {
+				return err
+			}
+
+			// the contract registered again by a later transaction is kept
+			if contract != nil && contract.TxID == tx.ID {
+				delete(ib.contracts, string(hash[:]))
+				ib.deleted[string(hash[:])] = true
+			}
+		}
+
+		for _, input := range tx.Inputs {
+			spend, ok := input.TypedInput.(*types.SpendInput)
+			if !ok {
+				continue
+			}
+
+			hash, ok := callContractHash(spend.ControlProgram)
+			if !ok {
+				continue
+			}
+
+			outputID, err := input.SpentOutputID()
+			if err != nil {
+				return err
+			}
+
+			utxo := &LockedUTXO{OutputID: outputID, AssetID: *spend.AssetId, Amount: spend.Amount, StateData: stateDataJSON(spend.StateData)}
+			if err := ib.lockUTXO(hash, utxo); err != nil {
+				return err
+			}
+
+			stats, err := ib.contractStats(hash)
+			if err != nil {
+				return err
+			}
+			stats.CallCount--
+		}
+	}
+
+	status := &ChainStatus{BlockHeight: block.Height - 1, BlockHash: block.PreviousBlockHash}
+	if err := ib.write(status); err != nil {
+		return err
+	}
+
+	b.status = status
+	return nil
+}
+
+func (b *BCRPIndex) withStats(contract *RegisteredContract) error {
+	if data := b.db.Get(contractStatsKey(contract.Hash)); data != nil {
+		stats := &contractStats{}
+		if err := json.Unmarshal(data, stats); err != nil {
+			return err
+		}
+
+		contract.CallCount, contract.UTXOCount = stats.CallCount, stats.UTXOCount
+	}
+	return nil
+}
+
+// ListContracts returns the registered contracts with their call statistics
+func (b *BCRPIndex) ListContracts() ([]*RegisteredContract, error) {
+	b.RLock()
+	defer b.RUnlock()
+
+	contracts := []*RegisteredContract{}
+	iter := b.db.IteratorPrefix(registeredContractPrefix)
+	defer iter.Release()
+
+	for iter.Next() {
+		contract := &RegisteredContract{}
+		if err := json.Unmarshal(iter.Value(), contract); err != nil {
+			return nil, err
+		}
+
+		if err := b.withStats(contract); err != nil {
+			return nil, err
+		}
+		contracts = append(contracts, contract)
+	}
+	return contracts, nil
+}
+
+// GetContract returns the registered contract and the outputs locked under
+// its call program
+func (b *BCRPIndex) GetContract(hash []byte) (*RegisteredContract, []*LockedUTXO, error) {
+	b.RLock()
+	defer b.RUnlock()
+
+	data := b.db.Get(registeredContractKey(hash))
+	if data == nil {
+		return nil, nil, ErrRegisteredContractNotFound
+	}
+
+	contract := &RegisteredContract{}
+	if err := json.Unmarshal(data, contract); err != nil {
+		return nil, nil, err
+	}
+
+	if err := b.withStats(contract); err != nil {
+		return nil, nil, err
+	}
+
+	utxos := []*LockedUTXO{}
+	iter := b.db.IteratorPrefix(append(append([]byte{}, lockedUTXOPrefix...), hash...))
+	defer iter.Release()
+
+	for iter.Next() {
+		utxo := &LockedUTXO{}
+		if err := json.Unmarshal(iter.Value(), utxo); err != nil {
+			return nil, nil, err
+		}
+		utxos = append(utxos, utxo)
+	}
+	return contract, utxos, nil
+}
@@ -0,0 +1,131 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/crypto/sha3pool"
+	dbm "github.com/bytom/bytom/database/leveldb"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+type mockChain struct {
+	blocks []*types.Block
+}
+
+func (m *mockChain) BestChain() (uint64, bc.Hash) {
+	block := m.blocks[len(m.blocks)-1]
+	return block.Height, block.Hash()
+}
+
+func (m *mockChain) FinalizedHeight() uint64 {
+	return 0
+}
+
+func (m *mockChain) GetBlockByHash(hash *bc.Hash) (*types.Block, error) {
+	for _, block := range m.blocks {
+		if block.Hash() == *hash {
+			return block, nil
+		}
+	}
+	return nil, ErrContractNotFound
+}
+
+func (m *mockChain) GetBlockByHeight(height uint64) (*types.Block, error) {
+	return m.blocks[height], nil
+}
+
+func (m *mockChain) BlockWaiter(height uint64) <-chan struct{} {
+	return nil
+}
+
+func TestBCRPIndex(t *testing.T) {
+	contract := []byte{0x51}
+	var hash [32]byte
+	sha3pool.Sum256(hash[:], contract)
+
+	registerProgram, err := vmutil.RegisterProgram(contract)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callProgram, err := vmutil.CallContractProgram(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	registrant := []byte{0x00, 0x14, 0x01}
+	registerTx := types.NewTx(types.TxData{
+		Inputs:  []*types.TxInput{types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 200000000, 0, registrant, nil)},
+		Outputs: []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 100000000, registerProgram, nil), types.NewOriginalTxOutput(*consensus.BTMAssetID, 500, callProgram, [][]byte{{0x01}})},
+	})
+	locked, err := registerTx.OriginalOutput(*registerTx.OutputID(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callTx := types.NewTx(types.TxData{
+		Inputs:  []*types.TxInput{types.NewSpendInput(nil, *locked.Source.Ref, *consensus.BTMAssetID, 500, locked.Source.Position, callProgram, [][]byte{{0x01}})},
+		Outputs: []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 500, registrant, nil)},
+	})
+
+	genesis := &types.Block{}
+	block1 := &types.Block{BlockHeader: types.BlockHeader{Height: 1, PreviousBlockHash: genesis.Hash()}, Transactions: []*types.Tx{registerTx}}
+	block2 := &types.Block{BlockHeader: types.BlockHeader{Height: 2, PreviousBlockHash: block1.Hash()}, Transactions: []*types.Tx{callTx}}
+
+	index, err := NewBCRPIndex(dbm.NewMemDB(), &mockChain{blocks: []*types.Block{genesis}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := index.ApplyBlock(block1); err != nil {
+		t.Fatal(err)
+	}
+
+	got, utxos, err := index.GetContract(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.TxID != registerTx.ID || got.BlockHeight != 1 || string(got.Registrant) != string(registrant) || string(got.CallProgram) != string(callProgram) {
+		t.Fatalf("got registered contract %+v", got)
+	}
+
+	if got.CallCount != 0 || got.UTXOCount != 1 || len(utxos) != 1 || utxos[0].OutputID != *registerTx.OutputID(1) || utxos[0].Amount != 500 {
+		t.Fatalf("got contract %+v with utxos %+v after the register block", got, utxos)
+	}
+
+	if err := index.ApplyBlock(block2); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, utxos, err = index.GetContract(hash[:]); err != nil || got.CallCount != 1 || got.UTXOCount != 0 || len(utxos) != 0 {
+		t.Fatalf("got contract %+v with utxos %+v after the call block, err %v", got, utxos, err)
+	}
+
+	if err := index.DetachBlock(block2); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, utxos, err = index.GetContract(hash[:]); err != nil || got.CallCount != 0 || got.UTXOCount != 1 || len(utxos) != 1 {
+		t.Fatalf("got contract %+v with utxos %+v after detaching the call block, err %v", got, utxos, err)
+	}
+
+	if err := index.DetachBlock(block1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := index.GetContract(hash[:]); err != ErrRegisteredContractNotFound {
+		t.Fatalf("got error %v want %v", err, ErrRegisteredContractNotFound)
+	}
+
+	if contracts, err := index.ListContracts(); err != nil || len(contracts) != 0 {
+		t.Fatalf("got contracts %v, err %v", contracts, err)
+	}
+
+	if status := index.chainStatus(); status.BlockHeight != 0 || status.BlockHash != genesis.Hash() {
+		t.Fatalf("got status %+v", status)
+	}
+}
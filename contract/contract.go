@@ -6,6 +6,7 @@ import (
 
 	dbm "github.com/bytom/bytom/database/leveldb"
 	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/equity/compiler"
 	"github.com/bytom/bytom/errors"
 )
 
@@ -17,6 +18,7 @@ var (
 var (
 	ErrContractDuplicated = errors.New("contract is duplicated")
 	ErrContractNotFound   = errors.New("contract not found")
+	ErrContractABI        = errors.New("contract abi mismatched")
 )
 
 // userContractKey return user contract key
@@ -44,6 +46,7 @@ type Contract struct {
 	Contract        chainjson.HexBytes `json:"contract"`
 	CallProgram     chainjson.HexBytes `json:"call_program"`
 	RegisterProgram chainjson.HexBytes `json:"register_program"`
+	ABI             *compiler.Contract `json:"abi,omitempty"`
 }
 
 // SaveContract save user contract
@@ -1,6 +1,9 @@
 package contract
 
 import (
+	"bytes"
+
+	"github.com/bytom/bytom/event"
 	"github.com/bytom/bytom/protocol/bc"
 	"github.com/bytom/bytom/protocol/bc/types"
 )
@@ -8,10 +11,11 @@ import (
 type Infrastructure struct {
 	Chain      ChainService
 	Repository Repository
+	Dispatcher *event.Dispatcher
 }
 
-func NewInfrastructure(chain ChainService, repository Repository) *Infrastructure {
-	return &Infrastructure{Chain: chain, Repository: repository}
+func NewInfrastructure(chain ChainService, repository Repository, dispatcher *event.Dispatcher) *Infrastructure {
+	return &Infrastructure{Chain: chain, Repository: repository, Dispatcher: dispatcher}
 }
 
 type ChainService interface {
@@ -27,9 +31,39 @@ type ChainStatus struct {
 	BlockHash   bc.Hash `json:"block_hash"`
 }
 
+// InstanceFilter selects the instances by the program and assets of their
+// utxos, the status and the height range of the last transfer, the zero
+// values match any instance
+type InstanceFilter struct {
+	Program     []byte
+	AssetID     *bc.AssetID
+	Status      Status
+	StartHeight uint64
+	EndHeight   uint64
+}
+
+func (f *InstanceFilter) match(inst *Instance) bool {
+	if f.Status != 0 && inst.Status != f.Status {
+		return false
+	}
+
+	if inst.TransferHeight < f.StartHeight || (f.EndHeight != 0 && inst.TransferHeight > f.EndHeight) {
+		return false
+	}
+
+	programMatched, assetMatched := f.Program == nil, f.AssetID == nil
+	for _, utxo := range inst.UTXOs {
+		programMatched = programMatched || bytes.Equal(utxo.Program, f.Program)
+		assetMatched = assetMatched || utxo.AssetID == *f.AssetID
+	}
+	return programMatched && assetMatched
+}
+
 type Repository interface {
 	GetInstance(traceID string) (*Instance, error)
 	LoadInstances() ([]*Instance, error)
+	ListInstances(filter *InstanceFilter, from, count uint) ([]*Instance, error)
+	GetTransferRecord(traceID string, index uint64) (*TransferRecord, error)
 	SaveInstances(instances []*Instance) error
 	SaveInstancesWithStatus(instances []*Instance, blockHeight uint64, blockHash bc.Hash) error
 	RemoveInstance(traceID string)
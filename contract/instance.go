@@ -19,60 +19,112 @@ type TreeNode struct {
 	TxHash   bc.Hash     `json:"tx_hash"`
 	UTXOs    []*UTXO     `json:"utxos"`
 	Children []*TreeNode `json:"children"`
+
+	// traceID is the instance the tree of the node belongs to
+	traceID string
+}
+
+// TransferRecord is a confirmed tx moving the instance, with the utxos it
+// moved the instance to (the spent ones when the tx ends the instance)
+type TransferRecord struct {
+	TxHash      bc.Hash `json:"tx_hash"`
+	BlockHeight uint64  `json:"block_height"`
+	UTXOs       []*UTXO `json:"utxos"`
 }
 
 type Instance struct {
-	TraceID       string   `json:"trace_id"`
-	UTXOs         []*UTXO  `json:"utxos"`
-	TxHash        *bc.Hash `json:"tx_hash"`
-	Status        Status   `json:"status"`
-	EndedHeight   uint64   `json:"ended_height"`
-	ScannedHash   bc.Hash  `json:"scanned_hash"`
-	ScannedHeight uint64   `json:"scanned_height"`
-	Unconfirmed   []*TreeNode
+	TraceID        string   `json:"trace_id"`
+	UTXOs          []*UTXO  `json:"utxos"`
+	TxHash         *bc.Hash `json:"tx_hash"`
+	Status         Status   `json:"status"`
+	EndedHeight    uint64   `json:"ended_height"`
+	TransferHeight uint64   `json:"transfer_height"`
+	HistorySize    uint64   `json:"history_size"`
+	ScannedHash    bc.Hash  `json:"scanned_hash"`
+	ScannedHeight  uint64   `json:"scanned_height"`
+	Unconfirmed    []*TreeNode
+
+	// History is read from the trace store only when the instance is queried,
+	// the transfer records are saved under their own keys
+	History []*TransferRecord `json:"-"`
+
+	// newRecords are the last transfer records of the history not saved yet
+	newRecords []*TransferRecord
 }
 
 func newInstance(t *transfer, block *types.Block) *Instance {
 	inst := &Instance{
-		TraceID:       uuid.New().String(),
-		TxHash:        &t.txHash,
-		UTXOs:         t.outUTXOs,
-		Status:        Lagging,
-		ScannedHeight: block.Height,
-		ScannedHash:   block.Hash(),
+		TraceID:        uuid.New().String(),
+		TxHash:         &t.txHash,
+		UTXOs:          t.outUTXOs,
+		Status:         Lagging,
+		TransferHeight: block.Height,
+		ScannedHeight:  block.Height,
+		ScannedHash:    block.Hash(),
 	}
 	if len(t.outUTXOs) == 0 {
 		inst.Status = Ended
 		inst.UTXOs = t.inUTXOs
 	}
+	inst.addRecord(&TransferRecord{TxHash: t.txHash, BlockHeight: block.Height, UTXOs: inst.UTXOs})
 	return inst
 }
 
 func (i *Instance) transferTo(t *transfer, blockHeight uint64) *Instance {
 	inst := &Instance{
-		TraceID:     i.TraceID,
-		Status:      i.Status,
-		Unconfirmed: i.Unconfirmed,
-		UTXOs:       t.outUTXOs,
-		TxHash:      &t.txHash,
+		TraceID:        i.TraceID,
+		Status:         i.Status,
+		Unconfirmed:    i.Unconfirmed,
+		UTXOs:          t.outUTXOs,
+		TxHash:         &t.txHash,
+		TransferHeight: blockHeight,
+		HistorySize:    i.HistorySize,
+		newRecords:     append([]*TransferRecord{}, i.newRecords...),
 	}
 	if len(t.outUTXOs) == 0 {
 		inst.Status = Ended
 		inst.EndedHeight = blockHeight
 		inst.UTXOs = t.inUTXOs
 	}
+	inst.addRecord(&TransferRecord{TxHash: t.txHash, BlockHeight: blockHeight, UTXOs: inst.UTXOs})
 	inst.confirmTx(t.txHash)
 	return inst
 }
 
+// rollbackTo undoes the transfer by dropping the last record of the history,
+// the tx and the height of the previous transfer are set by the tracer
 func (i *Instance) rollbackTo(t *transfer) *Instance {
-	return &Instance{
+	inst := &Instance{
 		TraceID:     i.TraceID,
 		Status:      InSync,
 		UTXOs:       t.inUTXOs,
 		TxHash:      nil,
 		Unconfirmed: nil,
+		HistorySize: i.HistorySize,
+		newRecords:  i.newRecords,
+	}
+
+	if i.TxHash != nil && *i.TxHash == t.txHash && i.HistorySize > 0 {
+		inst.HistorySize--
+		if n := len(i.newRecords); n > 0 {
+			inst.newRecords = i.newRecords[:n-1]
+		}
+	}
+	return inst
+}
+
+func (i *Instance) addRecord(record *TransferRecord) {
+	i.newRecords = append(i.newRecords, record)
+	i.HistorySize++
+}
+
+// newRecord returns the unsaved record at the index of the history
+func (i *Instance) newRecord(index uint64) (*TransferRecord, bool) {
+	savedSize := i.HistorySize - uint64(len(i.newRecords))
+	if index < savedSize || index >= i.HistorySize {
+		return nil, false
 	}
+	return i.newRecords[index-savedSize], true
 }
 
 func (i *Instance) confirmTx(txHash bc.Hash) {
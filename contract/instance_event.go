@@ -0,0 +1,63 @@
+package contract
+
+import (
+	log "github.com/sirupsen/logrus"
+
+	"github.com/bytom/bytom/protocol/bc"
+)
+
+// InstanceEventType tells how the state of a traced contract instance changed
+type InstanceEventType string
+
+const (
+	// InstanceNewUTXO means an unconfirmed tx moved the instance to new utxos
+	InstanceNewUTXO InstanceEventType = "new_utxo"
+	// InstanceTransfer means a tx moving the instance is confirmed by a block
+	InstanceTransfer InstanceEventType = "transfer"
+	// InstanceEnded means a confirmed tx spent the instance without new utxos
+	InstanceEnded InstanceEventType = "ended"
+	// InstanceRollback means the block moving the instance is detached, or
+	// the unconfirmed tx moving it is removed from the tx pool
+	InstanceRollback InstanceEventType = "rollback"
+)
+
+// InstanceEvent is posted to the event dispatcher when the state of an in
+// sync contract instance changes, the utxos are the ones the instance holds
+// after the change (the spent ones when it's ended)
+type InstanceEvent struct {
+	Type        InstanceEventType `json:"type"`
+	TraceID     string            `json:"trace_id"`
+	TxHash      *bc.Hash          `json:"tx_hash,omitempty"`
+	UTXOs       []*UTXO           `json:"utxos"`
+	Status      Status            `json:"status"`
+	Confirmed   bool              `json:"confirmed"`
+	BlockHeight uint64            `json:"block_height"`
+	BlockHash   bc.Hash           `json:"block_hash"`
+}
+
+func (t *TraceService) postInstanceEvents(typ InstanceEventType, confirmed bool, instances []*Instance) {
+	if t.infra.Dispatcher == nil {
+		return
+	}
+
+	for _, inst := range instances {
+		eventType := typ
+		if typ == InstanceTransfer && inst.Status == Ended {
+			eventType = InstanceEnded
+		}
+
+		event := InstanceEvent{
+			Type:        eventType,
+			TraceID:     inst.TraceID,
+			TxHash:      inst.TxHash,
+			UTXOs:       inst.UTXOs,
+			Status:      inst.Status,
+			Confirmed:   confirmed,
+			BlockHeight: t.bestHeight,
+			BlockHash:   t.bestHash,
+		}
+		if err := t.infra.Dispatcher.Post(event); err != nil {
+			log.WithFields(log.Fields{"module": logModule, "err": err, "trace_id": inst.TraceID}).Error("post contract instance event")
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/bytom/bytom/event"
+	"github.com/bytom/bytom/protocol/bc"
+)
+
+func TestPostInstanceEvents(t *testing.T) {
+	dispatcher := event.NewDispatcher()
+	sub, err := dispatcher.Subscribe(InstanceEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := &TraceService{infra: &Infrastructure{Dispatcher: dispatcher}, bestHeight: 10, bestHash: bc.Hash{V0: 10}}
+	txHash := bc.Hash{V0: 1}
+	service.postInstanceEvents(InstanceTransfer, true, []*Instance{
+		{TraceID: "a", TxHash: &txHash, Status: InSync},
+		{TraceID: "b", TxHash: &txHash, Status: Ended},
+	})
+	service.postInstanceEvents(InstanceNewUTXO, false, []*Instance{{TraceID: "c", TxHash: &txHash, Status: InSync}})
+	service.postInstanceEvents(InstanceRollback, true, []*Instance{{TraceID: "a", Status: InSync}})
+	service.postInstanceEvents(InstanceRollback, false, []*Instance{{TraceID: "c", Status: InSync}})
+
+	want := []struct {
+		traceID   string
+		typ       InstanceEventType
+		confirmed bool
+	}{
+		{traceID: "a", typ: InstanceTransfer, confirmed: true},
+		{traceID: "b", typ: InstanceEnded, confirmed: true},
+		{traceID: "c", typ: InstanceNewUTXO, confirmed: false},
+		{traceID: "a", typ: InstanceRollback, confirmed: true},
+		{traceID: "c", typ: InstanceRollback, confirmed: false},
+	}
+	for i, w := range want {
+		got := (<-sub.Chan()).Data.(InstanceEvent)
+		if got.TraceID != w.traceID || got.Type != w.typ || got.Confirmed != w.confirmed || got.BlockHeight != 10 || got.BlockHash != service.bestHash {
+			t.Errorf("case %d: got event %+v", i, got)
+		}
+	}
+}
@@ -56,7 +56,7 @@ func (t *traceScheduler) processLoop() {
 		if beginHeight > t.tracerService.BestHeight() {
 			continue
 		}
-		t.tracer = newTracer(jobs[beginHash])
+		t.tracer = newTracer(jobs[beginHash], t.infra.Repository)
 
 		for t.currentHeight, t.currentHash = beginHeight, beginHash;; {
 			if t.currentHeight == t.tracerService.BestHeight() {
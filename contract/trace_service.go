@@ -24,6 +24,7 @@ type TraceService struct {
 	infra            *Infrastructure
 	scheduler        *traceScheduler
 	unconfirmedIndex map[bc.Hash]*TreeNode
+	removedTxs       map[bc.Hash]*types.Tx
 	endedInstances   map[string]bool
 	bestHeight       uint64
 	bestHash         bc.Hash
@@ -41,9 +42,10 @@ func NewTraceService(infra *Infrastructure) *TraceService {
 
 	service := &TraceService{
 		infra:            infra,
-		tracer:           newTracer(inSyncInstances),
+		tracer:           newTracer(inSyncInstances, infra.Repository),
 		scheduler:        scheduler,
 		unconfirmedIndex: make(map[bc.Hash]*TreeNode),
+		removedTxs:       make(map[bc.Hash]*types.Tx),
 		endedInstances:   make(map[string]bool),
 		bestHeight:       chainStatus.BlockHeight,
 		bestHash:         chainStatus.BlockHash,
@@ -102,7 +104,16 @@ func (t *TraceService) ApplyBlock(block *types.Block) error {
 	t.processEndedInstances(newInstances)
 	t.bestHeight++
 	t.bestHash = block.Hash()
-	return t.infra.Repository.SaveInstancesWithStatus(newInstances, t.bestHeight, t.bestHash)
+	if err := t.infra.Repository.SaveInstancesWithStatus(newInstances, t.bestHeight, t.bestHash); err != nil {
+		return err
+	}
+
+	t.postInstanceEvents(InstanceTransfer, true, newInstances)
+	for _, tx := range block.Transactions {
+		delete(t.removedTxs, tx.ID)
+	}
+	t.pruneRemovedTxs()
+	return nil
 }
 
 func (t *TraceService) DetachBlock(block *types.Block) error {
@@ -113,31 +124,91 @@ func (t *TraceService) DetachBlock(block *types.Block) error {
 	t.processEndedInstances(nil)
 	t.bestHeight--
 	t.bestHash = block.PreviousBlockHash
-	return t.infra.Repository.SaveInstancesWithStatus(newInstances, t.bestHeight, t.bestHash)
+	if err := t.infra.Repository.SaveInstancesWithStatus(newInstances, t.bestHeight, t.bestHash); err != nil {
+		return err
+	}
+
+	t.postInstanceEvents(InstanceRollback, true, newInstances)
+	return nil
 }
 
 func (t *TraceService) AddUnconfirmedTx(tx *types.Tx) {
+	t.Lock()
+	defer t.Unlock()
+
 	transfers := parseTransfers(tx)
 	for _, transfer := range transfers {
 		inUTXOs, outUTXOs := transfer.inUTXOs, transfer.outUTXOs
 		if len(inUTXOs) == 0 || len(outUTXOs) == 0 {
-			return
+			continue
 		}
 
-		treeNode := &TreeNode{TxHash: tx.ID, UTXOs: outUTXOs}
 		if inst := t.tracer.index.getByUTXO(inUTXOs[0].Hash); inst != nil {
+			treeNode := &TreeNode{TxHash: tx.ID, UTXOs: outUTXOs, traceID: inst.TraceID}
 			inst.Unconfirmed = append(inst.Unconfirmed, treeNode)
 			t.addToUnconfirmedIndex(treeNode, outUTXOs)
-			return
+			t.postInstanceEvents(InstanceNewUTXO, false, []*Instance{{TraceID: inst.TraceID, TxHash: &treeNode.TxHash, UTXOs: outUTXOs, Status: inst.Status}})
+			continue
 		}
 
 		if parent, ok := t.unconfirmedIndex[inUTXOs[0].Hash]; ok {
+			treeNode := &TreeNode{TxHash: tx.ID, UTXOs: outUTXOs, traceID: parent.traceID}
 			parent.Children = append(parent.Children, treeNode)
 			t.addToUnconfirmedIndex(treeNode, outUTXOs)
 		}
 	}
 }
 
+// RemoveUnconfirmedTx prunes the tx removed from the tx pool from the
+// unconfirmed trees. The pool removes the txs confirmed by blocks as well, so
+// the tx is kept until the service catches up with the chain, and is skipped
+// if one of the applied blocks confirms it
+func (t *TraceService) RemoveUnconfirmedTx(tx *types.Tx) {
+	t.Lock()
+	defer t.Unlock()
+
+	t.removedTxs[tx.ID] = tx
+	t.pruneRemovedTxs()
+}
+
+func (t *TraceService) pruneRemovedTxs() {
+	if _, bestHash := t.infra.Chain.BestChain(); bestHash != t.bestHash {
+		return
+	}
+
+	var instances []*Instance
+	for txHash, tx := range t.removedTxs {
+		delete(t.removedTxs, txHash)
+		for _, transfer := range parseTransfers(tx) {
+			if inst := t.removeUnconfirmedNode(transfer); inst != nil {
+				instances = append(instances, inst)
+			}
+		}
+	}
+	t.postInstanceEvents(InstanceRollback, false, instances)
+}
+
+// removeUnconfirmedNode removes the tree node of the transfer with all its
+// descendants, and returns the instance the tree belongs to
+func (t *TraceService) removeUnconfirmedNode(transfer *transfer) *Instance {
+	if len(transfer.inUTXOs) == 0 || len(transfer.outUTXOs) == 0 {
+		return nil
+	}
+
+	treeNode, ok := t.unconfirmedIndex[transfer.outUTXOs[0].Hash]
+	if !ok || treeNode.TxHash != transfer.txHash {
+		return nil
+	}
+
+	t.removeFromUnconfirmedIndex(treeNode)
+	if inst := t.tracer.index.getByUTXO(transfer.inUTXOs[0].Hash); inst != nil {
+		inst.Unconfirmed = removeTreeNode(inst.Unconfirmed, treeNode)
+	} else if parent, ok := t.unconfirmedIndex[transfer.inUTXOs[0].Hash]; ok {
+		parent.Children = removeTreeNode(parent.Children, treeNode)
+	}
+	return t.tracer.getInstance(treeNode.traceID)
+}
+
 func (t *TraceService) CreateInstance(txHash, blockHash bc.Hash) ([]string, error) {
 	block, err := t.infra.Chain.GetBlockByHash(&blockHash)
 	if err != nil {
@@ -182,6 +253,11 @@ func (t *TraceService) GetInstance(traceID string) (*Instance, error) {
 	return t.infra.Repository.GetInstance(traceID)
 }
 
+// ListInstances returns the page of the traced instances selected by the filter
+func (t *TraceService) ListInstances(filter *InstanceFilter, from, count uint) ([]*Instance, error) {
+	return t.infra.Repository.ListInstances(filter, from, count)
+}
+
 func (t *TraceService) takeOverInstances(instances []*Instance, blockHash bc.Hash) bool {
 	t.Lock()
 	defer t.Unlock()
@@ -244,6 +320,28 @@ func (t *TraceService) addToUnconfirmedIndex(treeNode *TreeNode, utxos []*UTXO)
 	}
 }
 
+func (t *TraceService) removeFromUnconfirmedIndex(treeNode *TreeNode) {
+	for _, utxo := range treeNode.UTXOs {
+		if t.unconfirmedIndex[utxo.Hash] == treeNode {
+			delete(t.unconfirmedIndex, utxo.Hash)
+		}
+	}
+
+	for _, child := range treeNode.Children {
+		t.removeFromUnconfirmedIndex(child)
+	}
+}
+
+func removeTreeNode(treeNodes []*TreeNode, treeNode *TreeNode) []*TreeNode {
+	var result []*TreeNode
+	for _, node := range treeNodes {
+		if node != treeNode {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
 func findTx(block *types.Block, txHash bc.Hash) *types.Tx {
 	for _, tx := range block.Transactions {
 		if tx.ID == txHash {
@@ -0,0 +1,65 @@
+package contract
+
+import (
+	"testing"
+
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/event"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+)
+
+func TestRemoveUnconfirmedTx(t *testing.T) {
+	program := []byte{0x51, 0x51, 0x9a}
+	tx1 := types.NewTx(types.TxData{
+		Inputs:  []*types.TxInput{types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 100, 0, program, nil)},
+		Outputs: []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 100, program, nil)},
+	})
+	utxo := outputToUTXO(tx1, 0)
+	tx2 := types.NewTx(types.TxData{
+		Inputs:  []*types.TxInput{types.NewSpendInput(nil, utxo.SourceID, utxo.AssetID, utxo.Amount, utxo.SourcePos, utxo.Program, nil)},
+		Outputs: []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 100, program, nil)},
+	})
+
+	dispatcher := event.NewDispatcher()
+	sub, err := dispatcher.Subscribe(InstanceEvent{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chain := &mockChain{blocks: []*types.Block{{BlockHeader: types.BlockHeader{Height: 0}}}}
+	inst := &Instance{TraceID: "a", Status: InSync, UTXOs: []*UTXO{inputToUTXO(tx1, 0)}}
+	service := &TraceService{
+		infra:            &Infrastructure{Chain: chain, Dispatcher: dispatcher},
+		tracer:           newTracer([]*Instance{inst}, nil),
+		unconfirmedIndex: make(map[bc.Hash]*TreeNode),
+		removedTxs:       make(map[bc.Hash]*types.Tx),
+		endedInstances:   make(map[string]bool),
+	}
+
+	service.AddUnconfirmedTx(tx1)
+	service.AddUnconfirmedTx(tx2)
+	if len(inst.Unconfirmed) != 1 || len(inst.Unconfirmed[0].Children) != 1 || len(service.unconfirmedIndex) != 2 {
+		t.Fatalf("got unconfirmed tree %v, index size %d", inst.Unconfirmed, len(service.unconfirmedIndex))
+	}
+
+	if got := (<-sub.Chan()).Data.(InstanceEvent); got.Type != InstanceNewUTXO || got.TraceID != "a" {
+		t.Fatalf("got event %+v, want new utxo event", got)
+	}
+
+	// the service is behind the chain, the removed tx may be confirmed
+	service.RemoveUnconfirmedTx(tx2)
+	if len(inst.Unconfirmed[0].Children) != 1 || len(service.removedTxs) != 1 {
+		t.Fatalf("removed tx is pruned before the service catches up with the chain")
+	}
+
+	_, service.bestHash = chain.BestChain()
+	service.RemoveUnconfirmedTx(tx1)
+	if len(inst.Unconfirmed) != 0 || len(service.unconfirmedIndex) != 0 || len(service.removedTxs) != 0 {
+		t.Fatalf("got unconfirmed tree %v, index size %d, removed txs %d", inst.Unconfirmed, len(service.unconfirmedIndex), len(service.removedTxs))
+	}
+
+	if got := (<-sub.Chan()).Data.(InstanceEvent); got.Type != InstanceRollback || got.TraceID != "a" || got.Confirmed {
+		t.Fatalf("got event %+v, want unconfirmed rollback event", got)
+	}
+}
@@ -1,10 +1,13 @@
 package contract
 
 import (
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/bytom/bytom/crypto/sha3pool"
 	dbm "github.com/bytom/bytom/database/leveldb"
 	"github.com/bytom/bytom/protocol/bc"
 )
@@ -14,32 +17,168 @@ const (
 
 	instance byte = iota + 1
 	chainStatus
+	instanceProgram
+	instanceAsset
+	instanceStatus
+	instanceHeight
+	instanceIndexVersion
+	instanceHistory
 )
 
+var errTransferRecordNotFound = errors.New("transfer record of the instance not found")
+
+// currentIndexVersion is bumped when the instance indexes change, so they
+// are rebuilt from the saved instances
+const currentIndexVersion = 1
+
 var (
-	instancePrefixKey    = []byte{instance, colon}
-	chainStatusPrefixKey = []byte{chainStatus, colon}
+	instancePrefixKey        = []byte{instance, colon}
+	chainStatusPrefixKey     = []byte{chainStatus, colon}
+	instanceProgramPrefixKey = []byte{instanceProgram, colon}
+	instanceAssetPrefixKey   = []byte{instanceAsset, colon}
+	instanceStatusPrefixKey  = []byte{instanceStatus, colon}
+	instanceHeightPrefixKey  = []byte{instanceHeight, colon}
+	indexVersionPrefixKey    = []byte{instanceIndexVersion, colon}
+	historyPrefixKey         = []byte{instanceHistory, colon}
 )
 
 func instanceKey(traceID string) []byte {
 	return append(instancePrefixKey, []byte(traceID)...)
 }
 
+func historyPrefix(traceID string) []byte {
+	return append(append(append([]byte{}, historyPrefixKey...), traceID...), colon)
+}
+
+func historyKey(traceID string, index uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], index)
+	return append(historyPrefix(traceID), buf[:]...)
+}
+
 func chainStatusKey() []byte {
 	return chainStatusPrefixKey
 }
 
+func programIndexPrefix(program []byte) []byte {
+	var hash [32]byte
+	sha3pool.Sum256(hash[:], program)
+	return append(append([]byte{}, instanceProgramPrefixKey...), hash[:]...)
+}
+
+func assetIndexPrefix(assetID *bc.AssetID) []byte {
+	return append(append([]byte{}, instanceAssetPrefixKey...), assetID.Bytes()...)
+}
+
+func statusIndexPrefix(status Status) []byte {
+	return append(append([]byte{}, instanceStatusPrefixKey...), byte(status))
+}
+
+func heightIndexPrefix(height uint64) []byte {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], height)
+	return append(append([]byte{}, instanceHeightPrefixKey...), buf[:]...)
+}
+
+// instanceIndexKeys returns the search index keys of the instance, each one
+// ends with the trace id
+func instanceIndexKeys(inst *Instance) [][]byte {
+	keys := [][]byte{
+		append(statusIndexPrefix(inst.Status), inst.TraceID...),
+		append(heightIndexPrefix(inst.TransferHeight), inst.TraceID...),
+	}
+
+	programs, assets := map[string]bool{}, map[bc.AssetID]bool{}
+	for _, utxo := range inst.UTXOs {
+		if !programs[string(utxo.Program)] {
+			programs[string(utxo.Program)] = true
+			keys = append(keys, append(programIndexPrefix(utxo.Program), inst.TraceID...))
+		}
+
+		if !assets[utxo.AssetID] {
+			assets[utxo.AssetID] = true
+			keys = append(keys, append(assetIndexPrefix(&utxo.AssetID), inst.TraceID...))
+		}
+	}
+	return keys
+}
+
 
 type TraceStore struct {
 	db dbm.DB
 }
 
 func NewTraceStore(db dbm.DB) *TraceStore {
-	return &TraceStore{db: db}
+	store := &TraceStore{db: db}
+	if err := store.checkIndexVersion(); err != nil {
+		log.WithFields(log.Fields{"module": logModule, "err": err}).Fatal("rebuild instance indexes of trace store")
+	}
+	return store
 }
 
-// GetInstance return instance by given trace id
+// checkIndexVersion rebuilds the search indexes of the instances saved before
+// the current index version
+func (t *TraceStore) checkIndexVersion() error {
+	if data := t.db.Get(indexVersionPrefixKey); data != nil && binary.BigEndian.Uint64(data) == currentIndexVersion {
+		return nil
+	}
+
+	batch := t.db.NewBatch()
+	for _, prefix := range [][]byte{instanceProgramPrefixKey, instanceAssetPrefixKey, instanceStatusPrefixKey, instanceHeightPrefixKey} {
+		iter := t.db.IteratorPrefix(prefix)
+		for iter.Next() {
+			batch.Delete(append([]byte{}, iter.Key()...))
+		}
+		iter.Release()
+	}
+
+	instances, err := t.LoadInstances()
+	if err != nil {
+		return err
+	}
+
+	for _, inst := range instances {
+		for _, key := range instanceIndexKeys(inst) {
+			batch.Set(key, nil)
+		}
+	}
+
+	var version [8]byte
+	binary.BigEndian.PutUint64(version[:], currentIndexVersion)
+	batch.Set(indexVersionPrefixKey, version[:])
+	batch.Write()
+	return nil
+}
+
+// GetInstance return instance by given trace id, with the history of it
 func (t *TraceStore) GetInstance(traceID string) (*Instance, error) {
+	instance, err := t.getInstance(traceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := t.loadHistory(instance); err != nil {
+		return nil, err
+	}
+	return instance, nil
+}
+
+// GetTransferRecord return the record at the index of the instance history
+func (t *TraceStore) GetTransferRecord(traceID string, index uint64) (*TransferRecord, error) {
+	data := t.db.Get(historyKey(traceID, index))
+	if data == nil {
+		return nil, errTransferRecordNotFound
+	}
+
+	record := &TransferRecord{}
+	if err := json.Unmarshal(data, record); err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+func (t *TraceStore) getInstance(traceID string) (*Instance, error) {
 	key := instanceKey(traceID)
 	data := t.db.Get(key)
 	instance := &Instance{}
@@ -50,6 +189,22 @@ func (t *TraceStore) GetInstance(traceID string) (*Instance, error) {
 	return instance, nil
 }
 
+func (t *TraceStore) loadHistory(instance *Instance) error {
+	iter := t.db.IteratorPrefix(historyPrefix(instance.TraceID))
+	defer iter.Release()
+
+	instance.History = []*TransferRecord{}
+	for iter.Next() {
+		record := &TransferRecord{}
+		if err := json.Unmarshal(iter.Value(), record); err != nil {
+			return err
+		}
+
+		instance.History = append(instance.History, record)
+	}
+	return nil
+}
+
 // LoadInstances used to load all instances in db
 func (t *TraceStore) LoadInstances() ([]*Instance, error) {
 	iter := t.db.IteratorPrefix(instancePrefixKey)
@@ -67,6 +222,68 @@ func (t *TraceStore) LoadInstances() ([]*Instance, error) {
 	return instances, nil
 }
 
+// ListInstances returns the page of the instances selected by the filter, the
+// candidates are iterated from the most selective index the filter sets, and
+// the iteration stops when the page is full. A zero count means no limit
+func (t *TraceStore) ListInstances(filter *InstanceFilter, from, count uint) ([]*Instance, error) {
+	prefix, byHeight := instanceHeightPrefixKey, false
+	switch {
+	case filter.Program != nil:
+		prefix = programIndexPrefix(filter.Program)
+	case filter.AssetID != nil:
+		prefix = assetIndexPrefix(filter.AssetID)
+	case filter.Status != 0:
+		prefix = statusIndexPrefix(filter.Status)
+	default:
+		byHeight = true
+	}
+
+	iter := t.db.IteratorPrefix(prefix)
+	defer iter.Release()
+
+	instances := []*Instance{}
+	for skipped := uint(0); count == 0 || uint(len(instances)) < count; {
+		if !iter.Next() {
+			break
+		}
+
+		key := iter.Key()
+		traceID := string(key[len(prefix):])
+		if byHeight {
+			// the height index is sorted by the big endian height of the last transfer
+			height := binary.BigEndian.Uint64(key[len(prefix):])
+			if height < filter.StartHeight {
+				continue
+			}
+
+			if filter.EndHeight != 0 && height > filter.EndHeight {
+				break
+			}
+			traceID = string(key[len(prefix)+8:])
+		}
+
+		inst, err := t.getInstance(traceID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !filter.match(inst) {
+			continue
+		}
+
+		if skipped < from {
+			skipped++
+			continue
+		}
+
+		if err := t.loadHistory(inst); err != nil {
+			return nil, err
+		}
+		instances = append(instances, inst)
+	}
+	return instances, nil
+}
+
 // SaveInstances used to batch save multiple instances
 func (t *TraceStore) SaveInstances(instances []*Instance) error {
 	batch := t.db.NewBatch()
@@ -80,8 +297,21 @@ func (t *TraceStore) SaveInstances(instances []*Instance) error {
 
 // RemoveInstance delete a instance by given trace id
 func (t *TraceStore) RemoveInstance(traceID string) {
-	key := instanceKey(traceID)
-	t.db.Delete(key)
+	batch := t.db.NewBatch()
+	if inst := t.savedInstance(traceID); inst != nil {
+		for _, key := range instanceIndexKeys(inst) {
+			batch.Delete(key)
+		}
+	}
+
+	iter := t.db.IteratorPrefix(historyPrefix(traceID))
+	for iter.Next() {
+		batch.Delete(append([]byte{}, iter.Key()...))
+	}
+	iter.Release()
+
+	batch.Delete(instanceKey(traceID))
+	batch.Write()
 }
 
 // SaveInstancesWithStatus batch save the instances and chain status
@@ -128,7 +358,34 @@ func (t *TraceStore) SaveChainStatus(chainStatus *ChainStatus) error {
 }
 
 func (t *TraceStore) saveInstances(instances []*Instance, batch dbm.Batch) error {
+	// an instance may be saved more than once in the batch, only the index
+	// keys and the history records of the last version stay
+	saved := make(map[string]*Instance)
 	for _, inst := range instances {
+		prev, ok := saved[inst.TraceID]
+		if !ok {
+			prev = t.savedInstance(inst.TraceID)
+		}
+
+		if prev != nil {
+			for _, key := range instanceIndexKeys(prev) {
+				batch.Delete(key)
+			}
+
+			for index := inst.HistorySize; index < prev.HistorySize; index++ {
+				batch.Delete(historyKey(inst.TraceID, index))
+			}
+		}
+
+		for i, record := range inst.newRecords {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return err
+			}
+
+			batch.Set(historyKey(inst.TraceID, inst.HistorySize-uint64(len(inst.newRecords)-i)), data)
+		}
+
 		key := instanceKey(inst.TraceID)
 		data, err := json.Marshal(inst)
 		if err != nil {
@@ -136,6 +393,29 @@ func (t *TraceStore) saveInstances(instances []*Instance, batch dbm.Batch) error
 		}
 
 		batch.Set(key, data)
+		for _, key := range instanceIndexKeys(inst) {
+			batch.Set(key, nil)
+		}
+		saved[inst.TraceID] = inst
+	}
+
+	for _, inst := range instances {
+		inst.newRecords = nil
 	}
 	return nil
 }
+
+// savedInstance returns the saved version of the instance
+func (t *TraceStore) savedInstance(traceID string) *Instance {
+	data := t.db.Get(instanceKey(traceID))
+	if data == nil {
+		return nil
+	}
+
+	inst := &Instance{}
+	if err := json.Unmarshal(data, inst); err != nil {
+		log.WithFields(log.Fields{"module": logModule, "err": err, "trace_id": traceID}).Error("unmarshal saved instance")
+		return nil
+	}
+	return inst
+}
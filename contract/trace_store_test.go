@@ -0,0 +1,139 @@
+package contract
+
+import (
+	"testing"
+
+	dbm "github.com/bytom/bytom/database/leveldb"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/testutil"
+)
+
+func traceIDs(instances []*Instance) []string {
+	ids := []string{}
+	for _, inst := range instances {
+		ids = append(ids, inst.TraceID)
+	}
+	return ids
+}
+
+func TestListInstances(t *testing.T) {
+	store := NewTraceStore(dbm.NewMemDB())
+	assetA, assetB := bc.AssetID{V0: 1}, bc.AssetID{V0: 2}
+	instances := []*Instance{
+		{TraceID: "a", Status: InSync, TransferHeight: 5, UTXOs: []*UTXO{{Program: []byte{0x51}, AssetID: assetA}}},
+		{TraceID: "b", Status: Ended, TransferHeight: 8, UTXOs: []*UTXO{{Program: []byte{0x52}, AssetID: assetA}, {Program: []byte{0x52}, AssetID: assetB}}},
+		{TraceID: "c", Status: InSync, TransferHeight: 12, UTXOs: []*UTXO{{Program: []byte{0x51}, AssetID: assetB}}},
+	}
+	if err := store.SaveInstances(instances); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		filter *InstanceFilter
+		want   []string
+	}{
+		{filter: &InstanceFilter{}, want: []string{"a", "b", "c"}},
+		{filter: &InstanceFilter{Program: []byte{0x51}}, want: []string{"a", "c"}},
+		{filter: &InstanceFilter{AssetID: &assetB}, want: []string{"b", "c"}},
+		{filter: &InstanceFilter{Status: InSync, AssetID: &assetA}, want: []string{"a"}},
+		{filter: &InstanceFilter{Status: Ended}, want: []string{"b"}},
+		{filter: &InstanceFilter{StartHeight: 6, EndHeight: 12}, want: []string{"b", "c"}},
+		{filter: &InstanceFilter{Program: []byte{0x53}}, want: []string{}},
+	}
+	for i, c := range cases {
+		got, err := store.ListInstances(c.filter, 0, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ids := traceIDs(got); !testutil.DeepEqual(ids, c.want) {
+			t.Errorf("case %d: got instances %v want %v", i, ids, c.want)
+		}
+	}
+
+	pages := []struct {
+		filter      *InstanceFilter
+		from, count uint
+		want        []string
+	}{
+		{filter: &InstanceFilter{}, from: 1, count: 1, want: []string{"b"}},
+		{filter: &InstanceFilter{}, from: 1, count: 0, want: []string{"b", "c"}},
+		{filter: &InstanceFilter{AssetID: &assetB}, from: 1, count: 5, want: []string{"c"}},
+		{filter: &InstanceFilter{Status: InSync}, from: 2, count: 1, want: []string{}},
+	}
+	for i, p := range pages {
+		got, err := store.ListInstances(p.filter, p.from, p.count)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ids := traceIDs(got); !testutil.DeepEqual(ids, p.want) {
+			t.Errorf("page %d: got instances %v want %v", i, ids, p.want)
+		}
+	}
+
+	// moving the instance must drop its stale index keys
+	instances[0].UTXOs = []*UTXO{{Program: []byte{0x53}, AssetID: assetB}}
+	instances[0].Status = Ended
+	if err := store.SaveInstances(instances[:1]); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := store.ListInstances(&InstanceFilter{Program: []byte{0x51}}, 0, 0); len(got) != 1 || got[0].TraceID != "c" {
+		t.Errorf("got instances %v after moving the instance", traceIDs(got))
+	}
+
+	store.RemoveInstance("b")
+	if got, _ := store.ListInstances(&InstanceFilter{Status: Ended}, 0, 0); len(got) != 1 || got[0].TraceID != "a" {
+		t.Errorf("got instances %v after removing the instance", traceIDs(got))
+	}
+}
+
+func TestInstanceHistory(t *testing.T) {
+	store := NewTraceStore(dbm.NewMemDB())
+	utxo1, utxo2, utxo3 := &UTXO{Hash: bc.Hash{V0: 1}}, &UTXO{Hash: bc.Hash{V0: 2}}, &UTXO{Hash: bc.Hash{V0: 3}}
+	inst := newInstance(&transfer{txHash: bc.Hash{V0: 10}, outUTXOs: []*UTXO{utxo1}}, &types.Block{BlockHeader: types.BlockHeader{Height: 3}})
+	if err := store.SaveInstances([]*Instance{inst}); err != nil {
+		t.Fatal(err)
+	}
+
+	moved := inst.transferTo(&transfer{txHash: bc.Hash{V0: 11}, inUTXOs: []*UTXO{utxo1}, outUTXOs: []*UTXO{utxo2}}, 5)
+	moved = moved.transferTo(&transfer{txHash: bc.Hash{V0: 12}, inUTXOs: []*UTXO{utxo2}, outUTXOs: []*UTXO{utxo3}}, 6)
+	if moved.HistorySize != 3 || len(moved.newRecords) != 2 || moved.TransferHeight != 6 {
+		t.Fatalf("got transferred instance %+v", moved)
+	}
+
+	if err := store.SaveInstances([]*Instance{moved}); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.GetInstance(inst.TraceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got.History) != 3 || got.History[0].TxHash != (bc.Hash{V0: 10}) || got.History[2].TxHash != (bc.Hash{V0: 12}) {
+		t.Fatalf("got instance history %+v", got.History)
+	}
+
+	tracer := newTracer([]*Instance{moved}, store)
+	rollback := moved.rollbackTo(&transfer{txHash: bc.Hash{V0: 12}, inUTXOs: []*UTXO{utxo2}, outUTXOs: []*UTXO{utxo3}})
+	tracer.loadLastTransfer(rollback)
+	if rollback.HistorySize != 2 || rollback.TransferHeight != 5 || *rollback.TxHash != (bc.Hash{V0: 11}) || rollback.UTXOs[0] != utxo2 {
+		t.Fatalf("got rollback instance %+v", rollback)
+	}
+
+	if err := store.SaveInstances([]*Instance{rollback}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, _ := store.GetInstance(inst.TraceID); len(got.History) != 2 {
+		t.Fatalf("got instance history %+v after rollback", got.History)
+	}
+
+	store.RemoveInstance(inst.TraceID)
+	if _, err := store.GetTransferRecord(inst.TraceID, 0); err != errTransferRecordNotFound {
+		t.Fatalf("got err %v after removing the instance", err)
+	}
+}
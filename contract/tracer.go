@@ -3,6 +3,8 @@ package contract
 import (
 	"encoding/hex"
 
+	log "github.com/sirupsen/logrus"
+
 	"github.com/bytom/bytom/consensus/segwit"
 	"github.com/bytom/bytom/protocol/bc"
 	"github.com/bytom/bytom/protocol/bc/types"
@@ -10,15 +12,16 @@ import (
 )
 
 type tracer struct {
-	index *instanceIndex
+	index      *instanceIndex
+	repository Repository
 }
 
-func newTracer(instances []*Instance) *tracer {
+func newTracer(instances []*Instance, repository Repository) *tracer {
 	index := newInstanceIndex()
 	for _, inst := range instances {
 		index.save(inst)
 	}
-	return &tracer{index: index}
+	return &tracer{index: index, repository: repository}
 }
 
 func (t *tracer) getInstance(traceID string) *Instance {
@@ -67,6 +70,7 @@ func (t *tracer) detachBlock(block *types.Block) []*Instance {
 			utxos := append(transfer.outUTXOs, transfer.inUTXOs...)
 			if inst := t.index.getByUTXO(utxos[0].Hash); inst != nil {
 				newInst := inst.rollbackTo(transfer)
+				t.loadLastTransfer(newInst)
 				newInstances = append(newInstances, newInst)
 			}
 		}
@@ -75,6 +79,26 @@ func (t *tracer) detachBlock(block *types.Block) []*Instance {
 	return newInstances
 }
 
+// loadLastTransfer sets the tx and the height of the last transfer in the
+// history of the rolled back instance
+func (t *tracer) loadLastTransfer(inst *Instance) {
+	if inst.HistorySize == 0 {
+		return
+	}
+
+	record, ok := inst.newRecord(inst.HistorySize - 1)
+	if !ok {
+		var err error
+		if record, err = t.repository.GetTransferRecord(inst.TraceID, inst.HistorySize-1); err != nil {
+			log.WithFields(log.Fields{"module": logModule, "err": err, "trace_id": inst.TraceID}).Error("load last transfer of rolled back instance")
+			return
+		}
+	}
+
+	inst.TxHash = &record.TxHash
+	inst.TransferHeight = record.BlockHeight
+}
+
 type transfer struct {
 	txHash   bc.Hash
 	inUTXOs  []*UTXO
@@ -5,11 +5,15 @@ import (
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha512"
+	"errors"
 	"io"
 
 	"github.com/bytom/bytom/crypto/ed25519/ecmath"
 )
 
+// ErrInvalidPublicKey the public key is not a valid curve point
+var ErrInvalidPublicKey = errors.New("invalid public key")
+
 type (
 	//XPrv external private key
 	XPrv [64]byte
@@ -230,6 +234,34 @@ func (xpub XPub) Verify(msg []byte, sig []byte) bool {
 	return ed25519.Verify(xpub.PublicKey(), msg, sig)
 }
 
+// ECDH multiplies the public key by the scalar of the xprv, the scalars of
+// both sides commute so they agree on the same shared point
+func (xprv XPrv) ECDH(pub ed25519.PublicKey) ([]byte, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, ErrInvalidPublicKey
+	}
+
+	var encoded [32]byte
+	copy(encoded[:], pub)
+
+	var P ecmath.Point
+	if _, ok := P.Decode(encoded); !ok {
+		return nil, ErrInvalidPublicKey
+	}
+
+	var scalar ecmath.Scalar
+	copy(scalar[:], xprv[:32])
+
+	var shared ecmath.Point
+	shared.ScMul(&P, &scalar)
+	if shared.ConstTimeEqual(&ecmath.ZeroPoint) {
+		return nil, ErrInvalidPublicKey
+	}
+
+	buf := shared.Encode()
+	return buf[:], nil
+}
+
 // ExpandedPrivateKey generates a 64-byte key where
 // the first half is the scalar copied from xprv,
 // and the second half is the `prefix` is generated via PRF
@@ -277,3 +277,32 @@ func TestBits(t *testing.T) {
 		}
 	}
 }
+
+func TestECDH(t *testing.T) {
+	alice := RootXPrv([]byte{0x01, 0x02, 0x03})
+	bob := RootXPrv([]byte{0x04, 0x05, 0x06}).Derive([][]byte{{0x2c, 0, 0, 0}, {0x99, 0, 0, 0}})
+
+	ab, err := alice.ECDH(bob.XPub().PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ba, err := bob.ECDH(alice.XPub().PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(ab, ba) {
+		t.Errorf("shared points differ: %x and %x", ab, ba)
+	}
+
+	if _, err := alice.ECDH(make([]byte, 31)); err != ErrInvalidPublicKey {
+		t.Errorf("ecdh with short key got error %v want %v", err, ErrInvalidPublicKey)
+	}
+
+	identity := make([]byte, 32)
+	identity[0] = 1
+	if _, err := alice.ECDH(identity); err != ErrInvalidPublicKey {
+		t.Errorf("ecdh with identity got error %v want %v", err, ErrInvalidPublicKey)
+	}
+}
@@ -0,0 +1,397 @@
+// Package frost implements FROST threshold signing over ed25519 with the
+// FROST(Ed25519, SHA-512) ciphersuite of RFC 9591. The t-of-n share holders
+// jointly produce a signature that verifies as a plain ed25519 signature of
+// the group public key, no holder ever learns the group private key.
+//
+// Signing takes two rounds driven by a coordinator:
+//
+//  1. every chosen holder runs Commit and sends the Commitment, keeping the
+//     Nonces secret and using them for one signature only
+//  2. every chosen holder runs Sign with the message and all commitments,
+//     the coordinator Aggregates the signature shares to the signature
+package frost
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/hex"
+	"sort"
+
+	"github.com/bytom/bytom/crypto/ed25519/ecmath"
+	"github.com/bytom/bytom/errors"
+)
+
+const contextString = "FROST-ED25519-SHA512-v1"
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrThreshold   = errors.New("threshold must be at least 2 and not greater than count")
+	ErrIdentifier  = errors.New("invalid share identifier")
+	ErrCommitments = errors.New("invalid signing commitments")
+	ErrElement     = errors.New("invalid scalar or point encoding")
+	ErrShare       = errors.New("invalid signature share")
+)
+
+// Element is the 32 bytes encoding of a scalar or a curve point
+type Element [32]byte
+
+// MarshalText encodes the element as hex
+func (e Element) MarshalText() ([]byte, error) {
+	return []byte(hex.EncodeToString(e[:])), nil
+}
+
+// UnmarshalText decodes the hex element
+func (e *Element) UnmarshalText(text []byte) error {
+	b, err := hex.DecodeString(string(text))
+	if err != nil || len(b) != len(e) {
+		return ErrElement
+	}
+
+	copy(e[:], b)
+	return nil
+}
+
+// KeyShare is the secret share of one holder
+type KeyShare struct {
+	ID        uint16  `json:"id"`
+	Threshold int     `json:"threshold"`
+	Secret    Element `json:"secret"`
+	GroupKey  Element `json:"group_key"`
+}
+
+// PublicKeyPackage is the public information of the group, the coordinator
+// uses it to verify the signature shares
+type PublicKeyPackage struct {
+	Threshold     int                `json:"threshold"`
+	GroupKey      Element            `json:"group_key"`
+	VerifyingKeys map[uint16]Element `json:"verifying_keys"`
+}
+
+// Nonces is the secret of one signing commitment, it must be used for one
+// signature only
+type Nonces struct {
+	ID      uint16
+	Hiding  ecmath.Scalar
+	Binding ecmath.Scalar
+}
+
+// Commitment is the public commitment of a holder for one signature
+type Commitment struct {
+	ID      uint16  `json:"id"`
+	Hiding  Element `json:"hiding"`
+	Binding Element `json:"binding"`
+}
+
+// SignatureShare is the signature share of a holder
+type SignatureShare struct {
+	ID    uint16  `json:"id"`
+	Share Element `json:"share"`
+}
+
+func hashToScalar(tag string, data ...[]byte) *ecmath.Scalar {
+	h := sha512.New()
+	if tag != "" {
+		h.Write([]byte(contextString + tag))
+	}
+
+	for _, d := range data {
+		h.Write(d)
+	}
+
+	var digest [64]byte
+	h.Sum(digest[:0])
+	return new(ecmath.Scalar).Reduce(&digest)
+}
+
+func hashBytes(tag string, data []byte) []byte {
+	h := sha512.New()
+	h.Write([]byte(contextString + tag))
+	h.Write(data)
+	return h.Sum(nil)
+}
+
+func randomScalar() (*ecmath.Scalar, error) {
+	var b [64]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return nil, err
+	}
+	return new(ecmath.Scalar).Reduce(&b), nil
+}
+
+func identifier(id uint16) *ecmath.Scalar {
+	return &ecmath.Scalar{byte(id), byte(id >> 8)}
+}
+
+func mul(x, y *ecmath.Scalar) *ecmath.Scalar {
+	return new(ecmath.Scalar).MulAdd(x, y, &ecmath.Zero)
+}
+
+// invert computes x^(L-2) which is the inverse of x mod L
+func invert(x *ecmath.Scalar) *ecmath.Scalar {
+	exp := ecmath.L
+	exp[0] -= 2
+
+	result := ecmath.One
+	for i := 255; i >= 0; i-- {
+		result = *mul(&result, &result)
+		if exp[i/8]>>(uint(i)%8)&1 == 1 {
+			result = *mul(&result, x)
+		}
+	}
+	return &result
+}
+
+func decodePoint(e Element) (*ecmath.Point, error) {
+	p, ok := new(ecmath.Point).Decode(e)
+	if !ok || e == ecmath.ZeroPoint.Encode() {
+		return nil, ErrElement
+	}
+	return p, nil
+}
+
+// lagrange is the coefficient of id to interpolate at 0 over the ids
+func lagrange(id uint16, ids []uint16) *ecmath.Scalar {
+	num, den := ecmath.One, ecmath.One
+	for _, other := range ids {
+		if other == id {
+			continue
+		}
+
+		num = *mul(&num, identifier(other))
+		den = *mul(&den, new(ecmath.Scalar).Sub(identifier(other), identifier(id)))
+	}
+	return mul(&num, invert(&den))
+}
+
+// SplitKey shares the secret scalar to count holders with the threshold by a
+// trusted dealer, the secret can be an existing ed25519 private scalar so that
+// the group key stays the same.
+func SplitKey(secret *ecmath.Scalar, threshold, count int) (*PublicKeyPackage, []*KeyShare, error) {
+	if threshold < 2 || threshold > count || count > 0xffff {
+		return nil, nil, ErrThreshold
+	}
+
+	reduced := new(ecmath.Scalar).Add(secret, &ecmath.Zero)
+	coefficients := []*ecmath.Scalar{reduced}
+	for i := 1; i < threshold; i++ {
+		coefficient, err := randomScalar()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		coefficients = append(coefficients, coefficient)
+	}
+
+	groupKey := Element(new(ecmath.Point).ScMulBase(reduced).Encode())
+	pkg := &PublicKeyPackage{Threshold: threshold, GroupKey: groupKey, VerifyingKeys: map[uint16]Element{}}
+	shares := []*KeyShare{}
+	for i := 1; i <= count; i++ {
+		id := uint16(i)
+		x := identifier(id)
+		value := ecmath.Zero
+		for j := len(coefficients) - 1; j >= 0; j-- {
+			value.MulAdd(&value, x, coefficients[j])
+		}
+
+		shares = append(shares, &KeyShare{ID: id, Threshold: threshold, Secret: Element(value), GroupKey: groupKey})
+		pkg.VerifyingKeys[id] = Element(new(ecmath.Point).ScMulBase(&value).Encode())
+	}
+	return pkg, shares, nil
+}
+
+// GenerateKey creates a random group key and shares it to count holders
+func GenerateKey(threshold, count int) (*PublicKeyPackage, []*KeyShare, error) {
+	secret, err := randomScalar()
+	if err != nil {
+		return nil, nil, err
+	}
+	return SplitKey(secret, threshold, count)
+}
+
+func (s *KeyShare) nonce() (*ecmath.Scalar, error) {
+	var random [32]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return nil, err
+	}
+	return hashToScalar("nonce", random[:], s.Secret[:]), nil
+}
+
+// Commit creates the nonces and the commitment of the holder for one signature
+func Commit(share *KeyShare) (*Nonces, *Commitment, error) {
+	hiding, err := share.nonce()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	binding, err := share.nonce()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	nonces := &Nonces{ID: share.ID, Hiding: *hiding, Binding: *binding}
+	commitment := &Commitment{
+		ID:      share.ID,
+		Hiding:  Element(new(ecmath.Point).ScMulBase(hiding).Encode()),
+		Binding: Element(new(ecmath.Point).ScMulBase(binding).Encode()),
+	}
+	return nonces, commitment, nil
+}
+
+// signingPackage is the state shared by the holders and the coordinator for
+// one signature
+type signingPackage struct {
+	ids            []uint16
+	bindingFactors map[uint16]*ecmath.Scalar
+	groupR         *ecmath.Point
+	challenge      *ecmath.Scalar
+}
+
+func newSigningPackage(groupKey Element, msg []byte, commitments []*Commitment, threshold int) (*signingPackage, error) {
+	if len(commitments) < threshold {
+		return nil, errors.WithDetailf(ErrCommitments, "got %d commitments want %d", len(commitments), threshold)
+	}
+
+	sorted := append([]*Commitment{}, commitments...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	encoded := []byte{}
+	for i, c := range sorted {
+		if c.ID == 0 || (i > 0 && c.ID == sorted[i-1].ID) {
+			return nil, errors.WithDetailf(ErrCommitments, "duplicated or zero id %d", c.ID)
+		}
+
+		id := identifier(c.ID)
+		encoded = append(encoded, id[:]...)
+		encoded = append(encoded, c.Hiding[:]...)
+		encoded = append(encoded, c.Binding[:]...)
+	}
+
+	prefix := append([]byte{}, groupKey[:]...)
+	prefix = append(prefix, hashBytes("msg", msg)...)
+	prefix = append(prefix, hashBytes("com", encoded)...)
+
+	pkg := &signingPackage{bindingFactors: map[uint16]*ecmath.Scalar{}, groupR: new(ecmath.Point)}
+	*pkg.groupR = ecmath.ZeroPoint
+	for _, c := range sorted {
+		id := identifier(c.ID)
+		rho := hashToScalar("rho", prefix, id[:])
+		hiding, err := decodePoint(c.Hiding)
+		if err != nil {
+			return nil, err
+		}
+
+		binding, err := decodePoint(c.Binding)
+		if err != nil {
+			return nil, err
+		}
+
+		pkg.ids = append(pkg.ids, c.ID)
+		pkg.bindingFactors[c.ID] = rho
+		pkg.groupR.Add(pkg.groupR, hiding)
+		pkg.groupR.Add(pkg.groupR, new(ecmath.Point).ScMul(binding, rho))
+	}
+
+	r := pkg.groupR.Encode()
+	pkg.challenge = hashToScalar("", r[:], groupKey[:], msg)
+	return pkg, nil
+}
+
+// Sign creates the signature share of the holder, the commitments must
+// contain the commitment of the nonces.
+func Sign(share *KeyShare, nonces *Nonces, msg []byte, commitments []*Commitment) (*SignatureShare, error) {
+	if nonces.ID != share.ID {
+		return nil, ErrIdentifier
+	}
+
+	found := false
+	for _, c := range commitments {
+		if c.ID == share.ID {
+			found = c.Hiding == Element(new(ecmath.Point).ScMulBase(&nonces.Hiding).Encode()) && c.Binding == Element(new(ecmath.Point).ScMulBase(&nonces.Binding).Encode())
+		}
+	}
+	if !found {
+		return nil, errors.WithDetail(ErrCommitments, "missing the commitment of the holder")
+	}
+
+	pkg, err := newSigningPackage(share.GroupKey, msg, commitments, share.Threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	secret := ecmath.Scalar(share.Secret)
+	lambda := lagrange(share.ID, pkg.ids)
+	z := new(ecmath.Scalar).MulAdd(&nonces.Binding, pkg.bindingFactors[share.ID], &nonces.Hiding)
+	z.MulAdd(mul(lambda, &secret), pkg.challenge, z)
+	return &SignatureShare{ID: share.ID, Share: Element(*z)}, nil
+}
+
+// Aggregate verifies the signature shares and combines them to the ed25519
+// signature of the group key
+func Aggregate(group *PublicKeyPackage, msg []byte, commitments []*Commitment, shares []*SignatureShare) ([]byte, error) {
+	pkg, err := newSigningPackage(group.GroupKey, msg, commitments, group.Threshold)
+	if err != nil {
+		return nil, err
+	}
+
+	commitmentByID := map[uint16]*Commitment{}
+	for _, c := range commitments {
+		commitmentByID[c.ID] = c
+	}
+
+	if len(shares) != len(commitments) {
+		return nil, errors.WithDetailf(ErrShare, "got %d shares want %d", len(shares), len(commitments))
+	}
+
+	z := ecmath.Zero
+	seen := map[uint16]bool{}
+	for _, share := range shares {
+		c, ok := commitmentByID[share.ID]
+		if !ok || seen[share.ID] {
+			return nil, errors.WithDetailf(ErrShare, "unexpected share of %d", share.ID)
+		}
+		seen[share.ID] = true
+
+		if err := verifyShare(group, pkg, c, share); err != nil {
+			return nil, err
+		}
+
+		shareScalar := ecmath.Scalar(share.Share)
+		z.Add(&z, &shareScalar)
+	}
+
+	r := pkg.groupR.Encode()
+	return append(r[:], z[:]...), nil
+}
+
+// verifyShare checks z*B == D + rho*E + c*lambda*Y of the holder
+func verifyShare(group *PublicKeyPackage, pkg *signingPackage, c *Commitment, share *SignatureShare) error {
+	verifyingKey, ok := group.VerifyingKeys[share.ID]
+	if !ok {
+		return errors.WithDetailf(ErrIdentifier, "unknown holder %d", share.ID)
+	}
+
+	publicShare, err := decodePoint(verifyingKey)
+	if err != nil {
+		return err
+	}
+
+	hiding, err := decodePoint(c.Hiding)
+	if err != nil {
+		return err
+	}
+
+	binding, err := decodePoint(c.Binding)
+	if err != nil {
+		return err
+	}
+
+	z := ecmath.Scalar(share.Share)
+	left := new(ecmath.Point).ScMulBase(&z)
+	right := new(ecmath.Point).ScMul(binding, pkg.bindingFactors[share.ID])
+	right.Add(right, hiding)
+	right.Add(right, new(ecmath.Point).ScMul(publicShare, mul(pkg.challenge, lagrange(share.ID, pkg.ids))))
+	if !left.ConstTimeEqual(right) {
+		return errors.WithDetailf(ErrShare, "share of %d does not verify", share.ID)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package frost
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"testing"
+
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/crypto/ed25519/ecmath"
+	"github.com/bytom/bytom/errors"
+)
+
+func thresholdSign(t *testing.T, group *PublicKeyPackage, shares []*KeyShare, msg []byte) ([]byte, error) {
+	nonces := []*Nonces{}
+	commitments := []*Commitment{}
+	for _, share := range shares {
+		n, c, err := Commit(share)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		nonces = append(nonces, n)
+		commitments = append(commitments, c)
+	}
+
+	sigShares := []*SignatureShare{}
+	for i, share := range shares {
+		sigShare, err := Sign(share, nonces[i], msg, commitments)
+		if err != nil {
+			return nil, err
+		}
+
+		sigShares = append(sigShares, sigShare)
+	}
+	return Aggregate(group, msg, commitments, sigShares)
+}
+
+func TestThresholdSign(t *testing.T) {
+	group, shares, err := GenerateKey(3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("block header hash")
+	subsets := [][]int{{0, 1, 2}, {4, 2, 0}, {1, 2, 3, 4}, {0, 1, 2, 3, 4}}
+	for _, subset := range subsets {
+		signers := []*KeyShare{}
+		for _, i := range subset {
+			signers = append(signers, shares[i])
+		}
+
+		sig, err := thresholdSign(t, group, signers, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !ed25519.Verify(group.GroupKey[:], msg, sig) {
+			t.Errorf("signature of holders %v does not verify", subset)
+		}
+	}
+
+	if _, err := thresholdSign(t, group, shares[:2], msg); errors.Root(err) != ErrCommitments {
+		t.Errorf("sign below threshold got error %v want %v", err, ErrCommitments)
+	}
+}
+
+func TestAggregateBadShare(t *testing.T) {
+	group, shares, err := GenerateKey(2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("casper verification")
+	n1, c1, _ := Commit(shares[0])
+	n2, c2, _ := Commit(shares[1])
+	commitments := []*Commitment{c1, c2}
+
+	s1, err := Sign(shares[0], n1, msg, commitments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s2, err := Sign(shares[1], n2, []byte("other message"), commitments)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Aggregate(group, msg, commitments, []*SignatureShare{s1, s2}); errors.Root(err) != ErrShare {
+		t.Errorf("aggregate bad share got error %v want %v", err, ErrShare)
+	}
+
+	if _, err := Sign(shares[0], n2, msg, commitments); err != ErrIdentifier {
+		t.Errorf("sign with other nonces got error %v want %v", err, ErrIdentifier)
+	}
+}
+
+func TestSplitExistingKey(t *testing.T) {
+	xprv, xpub, err := chainkd.NewXKeys(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var secret ecmath.Scalar
+	copy(secret[:], xprv[:32])
+	group, shares, err := SplitKey(&secret, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(group.GroupKey[:]) != string(xpub.PublicKey()) {
+		t.Fatalf("group key got %x want %x", group.GroupKey, xpub.PublicKey())
+	}
+
+	rawShare, err := json.Marshal(shares[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	share := &KeyShare{}
+	if err := json.Unmarshal(rawShare, share); err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("block header hash")
+	sig, err := thresholdSign(t, group, []*KeyShare{shares[0], share}, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !xpub.Verify(msg, sig) {
+		t.Error("threshold signature does not verify by the validator xpub")
+	}
+}
+
+func TestInvert(t *testing.T) {
+	x := ecmath.Scalar{7, 1, 2, 3}
+	if got := mul(&x, invert(&x)); *got != ecmath.One {
+		t.Errorf("x * x^-1 got %x want 1", got)
+	}
+}
@@ -0,0 +1,137 @@
+// Package shamir implements Shamir's secret sharing over GF(256), every byte
+// of the secret is shared by its own random polynomial.
+package shamir
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+// MaxShares is the max number of shares of one secret
+const MaxShares = 16
+
+var (
+	// ErrThreshold is returned when the threshold is out of [1, count]
+	ErrThreshold = errors.New("threshold must be in [1, count] and count must not exceed 16")
+	// ErrShares is returned when the shares are too few, duplicated or have
+	// different lengths
+	ErrShares = errors.New("invalid shares to combine")
+)
+
+// Share is the point (X, Y) of the secret polynomials, Y holds one byte per
+// secret byte
+type Share struct {
+	X byte
+	Y []byte
+}
+
+var expTable, logTable [256]byte
+
+func init() {
+	// 0x03 is a generator of GF(256) with the AES polynomial x^8+x^4+x^3+x+1
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		expTable[i] = x
+		logTable[x] = byte(i)
+		x = mulNoTable(x, 0x03)
+	}
+	expTable[255] = expTable[0]
+}
+
+func mulNoTable(a, b byte) byte {
+	var p byte
+	for b > 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+
+		carry := a & 0x80
+		a <<= 1
+		if carry != 0 {
+			a ^= 0x1b
+		}
+		b >>= 1
+	}
+	return p
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])+int(logTable[b]))%255]
+}
+
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])-int(logTable[b])+255)%255]
+}
+
+// Split shares the secret to count shares, any threshold of them can combine
+// the secret while fewer reveal nothing about it
+func Split(secret []byte, threshold, count int) ([]*Share, error) {
+	if threshold < 1 || threshold > count || count > MaxShares {
+		return nil, ErrThreshold
+	}
+
+	coefficients := make([]byte, len(secret)*(threshold-1))
+	if _, err := rand.Read(coefficients); err != nil {
+		return nil, err
+	}
+
+	shares := make([]*Share, count)
+	for i := range shares {
+		shares[i] = &Share{X: byte(i + 1), Y: make([]byte, len(secret))}
+	}
+
+	for j, s := range secret {
+		poly := coefficients[j*(threshold-1) : (j+1)*(threshold-1)]
+		for _, share := range shares {
+			// Horner's method from the highest degree coefficient
+			y := byte(0)
+			for k := len(poly) - 1; k >= 0; k-- {
+				y = mul(y, share.X) ^ poly[k]
+			}
+			share.Y[j] = mul(y, share.X) ^ s
+		}
+	}
+
+	for i := range coefficients {
+		coefficients[i] = 0
+	}
+	return shares, nil
+}
+
+// Combine recovers the secret from the shares by Lagrange interpolation at 0,
+// the result is only right when there are at least threshold shares
+func Combine(shares []*Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrShares
+	}
+
+	seen := map[byte]bool{}
+	for _, share := range shares {
+		if share.X == 0 || seen[share.X] || len(share.Y) != len(shares[0].Y) {
+			return nil, ErrShares
+		}
+		seen[share.X] = true
+	}
+
+	secret := make([]byte, len(shares[0].Y))
+	for i, si := range shares {
+		// basis polynomial of share i at x = 0
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = mul(basis, div(sj.X, si.X^sj.X))
+			}
+		}
+
+		for k := range secret {
+			secret[k] ^= mul(si.Y[k], basis)
+		}
+	}
+	return secret, nil
+}
@@ -0,0 +1,45 @@
+package shamir
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitCombine(t *testing.T) {
+	secret := []byte("bytom shamir secret sharing test")
+	shares, err := Split(secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subsets := [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3, 4}}
+	for _, subset := range subsets {
+		picked := []*Share{}
+		for _, i := range subset {
+			picked = append(picked, shares[i])
+		}
+
+		got, err := Combine(picked)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(got, secret) {
+			t.Errorf("combine shares %v got %x want %x", subset, got, secret)
+		}
+	}
+
+	if got, err := Combine(shares[:2]); err != nil || bytes.Equal(got, secret) {
+		t.Errorf("combine below threshold got %x %v", got, err)
+	}
+
+	if _, err := Combine([]*Share{shares[0], shares[0], shares[1]}); err != ErrShares {
+		t.Errorf("combine duplicated shares got error %v want %v", err, ErrShares)
+	}
+
+	for _, c := range [][2]int{{0, 3}, {4, 3}, {2, 17}} {
+		if _, err := Split(secret, c[0], c[1]); err != ErrThreshold {
+			t.Errorf("split %d of %d got error %v want %v", c[0], c[1], err, ErrThreshold)
+		}
+	}
+}
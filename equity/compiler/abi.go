@@ -0,0 +1,90 @@
+package compiler
+
+import (
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/vm"
+)
+
+// LockedValue is an output required by a clause, resolved from the arguments
+type LockedValue struct {
+	Index   uint64
+	Amount  uint64
+	AssetID []byte
+	Program []byte
+}
+
+// FindClause returns the clause ABI by the name
+func (c *Contract) FindClause(name string) (*Clause, error) {
+	for _, clause := range c.Clauses {
+		if clause.Name == name {
+			return clause, nil
+		}
+	}
+	return nil, errors.WithDetailf(ErrContractArgs, "contract %s has no clause %s", c.Name, name)
+}
+
+// ClauseArguments builds the witness arguments calling the clause, the
+// arguments in the parameter order followed by the selector when the
+// contract has several clauses
+func (c *Contract) ClauseArguments(clause *Clause, args []ContractArg) ([][]byte, error) {
+	items, err := encodeArgs(clause.Params, args)
+	if err != nil {
+		return nil, errors.WithDetailf(err, "clause %s", clause.Name)
+	}
+
+	if len(c.Clauses) > 1 {
+		items = append(items, vm.Uint64Bytes(clause.Selector))
+	}
+	return items, nil
+}
+
+// LockedValues resolves the values locked by the clause. The contract
+// arguments are the state data of the spent output, the clause arguments are
+// built by ClauseArguments, the amount and the asset are of the spent output.
+func (c *Contract) LockedValues(clause *Clause, contractArgs, clauseArgs [][]byte, amount uint64, assetID []byte) ([]*LockedValue, error) {
+	if len(contractArgs) != len(c.Params) {
+		return nil, errors.WithDetailf(ErrContractArgs, "got %d contract arguments want %d", len(contractArgs), len(c.Params))
+	}
+
+	items := map[string][]byte{c.ValueAmount: vm.Uint64Bytes(amount), c.ValueAsset: assetID}
+	for i, param := range c.Params {
+		items[param.Name] = contractArgs[i]
+	}
+	for i, param := range clause.Params {
+		items[param.Name] = clauseArgs[i]
+	}
+
+	resolve := func(name string) ([]byte, error) {
+		item, ok := items[name]
+		if !ok {
+			return nil, errors.WithDetailf(ErrContractArgs, "value locked by clause %s is not a parameter", clause.Name)
+		}
+		return item, nil
+	}
+
+	values := []*LockedValue{}
+	for _, value := range clause.Values {
+		amountItem, err := resolve(value.Amount)
+		if err != nil {
+			return nil, err
+		}
+
+		assetID, err := resolve(value.Asset)
+		if err != nil {
+			return nil, err
+		}
+
+		program, err := resolve(value.Program)
+		if err != nil {
+			return nil, err
+		}
+
+		amount, err := vm.AsBigInt(amountItem)
+		if err != nil || !amount.IsUint64() {
+			return nil, errors.WithDetailf(ErrContractArgs, "invalid amount %x locked by clause %s", amountItem, clause.Name)
+		}
+
+		values = append(values, &LockedValue{Index: value.Index, Amount: amount.Uint64(), AssetID: assetID, Program: program})
+	}
+	return values, nil
+}
@@ -0,0 +1,137 @@
+package compiler
+
+// types of the contract parameters and the expressions
+const (
+	amountType    = "Amount"
+	assetType     = "Asset"
+	booleanType   = "Boolean"
+	hashType      = "Hash"
+	integerType   = "Integer"
+	programType   = "Program"
+	publicKeyType = "PublicKey"
+	signatureType = "Signature"
+	stringType    = "String"
+
+	// listType is the type of the list literals, only accepted by
+	// checkTxMultiSig
+	listType = "List"
+)
+
+var paramTypes = map[string]bool{
+	amountType:    true,
+	assetType:     true,
+	booleanType:   true,
+	hashType:      true,
+	integerType:   true,
+	programType:   true,
+	publicKeyType: true,
+	signatureType: true,
+	stringType:    true,
+}
+
+func isNumeric(typ string) bool {
+	return typ == integerType || typ == amountType
+}
+
+func isBytes(typ string) bool {
+	return typ != booleanType && typ != listType && !isNumeric(typ)
+}
+
+// compatible reports whether a value of the type src can be used as the type
+// dst, a String is accepted by any byte string type
+func compatible(dst, src string) bool {
+	switch {
+	case dst == src:
+		return true
+	case isNumeric(dst) && isNumeric(src):
+		return true
+	case isBytes(dst) && src == stringType:
+		return true
+	}
+	return false
+}
+
+type contractNode struct {
+	name        string
+	params      []*Param
+	valueAmount string
+	valueAsset  string
+	clauses     []*clauseNode
+}
+
+type clauseNode struct {
+	name   string
+	params []*Param
+	body   []statement
+}
+
+type statement interface{}
+
+type verifyStatement struct {
+	expr expression
+}
+
+type lockStatement struct {
+	amount  expression
+	asset   expression
+	program expression
+}
+
+type unlockStatement struct {
+	amount expression
+	asset  expression
+}
+
+type defineStatement struct {
+	name string
+	typ  string
+	expr expression
+}
+
+type assignStatement struct {
+	name string
+	expr expression
+}
+
+type ifStatement struct {
+	cond     expression
+	body     []statement
+	elseBody []statement
+}
+
+type expression interface{}
+
+type identExpr struct {
+	name string
+}
+
+type integerLiteral struct {
+	value uint64
+}
+
+type bytesLiteral struct {
+	value []byte
+}
+
+type booleanLiteral struct {
+	value bool
+}
+
+type listExpr struct {
+	items []expression
+}
+
+type unaryExpr struct {
+	op   string
+	expr expression
+}
+
+type binaryExpr struct {
+	op          string
+	left, right expression
+}
+
+type callExpr struct {
+	name string
+	args []expression
+}
@@ -0,0 +1,758 @@
+// Package compiler compiles Equity contracts into Bytom VM programs.
+//
+// The compiled program expects the clause arguments on the data stack, in
+// the declared order, followed by the clause selector when the contract has
+// more than one clause. The contract parameters are pushed above them, the
+// first parameter on the top, either by the instantiated program or, for a
+// contract registered by BCRP, from the state data of the output.
+package compiler
+
+import (
+	"fmt"
+
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/vm"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+// pre-define errors for supporting bytom errorFormatter
+var (
+	ErrCompile      = errors.New("equity compile error")
+	ErrContractArgs = errors.New("invalid contract arguments")
+)
+
+// Param is a parameter of the contract or of a clause
+type Param struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// ClauseValue is a value locked unconditionally by a clause, named by the
+// parameters or the contract value, an empty name is an expression
+type ClauseValue struct {
+	Index   uint64 `json:"index"`
+	Amount  string `json:"amount"`
+	Asset   string `json:"asset"`
+	Program string `json:"program"`
+}
+
+// Clause is the ABI of a contract clause, the spender pushes the parameters
+// in order and then the selector when the contract has several clauses
+type Clause struct {
+	Name     string         `json:"name"`
+	Selector uint64         `json:"selector"`
+	Params   []*Param       `json:"params"`
+	Values   []*ClauseValue `json:"values"`
+}
+
+// Contract is a compiled contract with its ABI
+type Contract struct {
+	Name        string    `json:"name"`
+	Params      []*Param  `json:"params"`
+	ValueAmount string    `json:"value_amount"`
+	ValueAsset  string    `json:"value_asset"`
+	Clauses     []*Clause `json:"clauses"`
+
+	// Program is instantiated by pushing the contract arguments before it,
+	// the jump addresses of it are relative to the end of the arguments
+	Program chainjson.HexBytes `json:"program"`
+	// Contract is the code registered by BCRP, reading the contract
+	// arguments from the state data of the output
+	Contract chainjson.HexBytes `json:"contract"`
+	Opcodes  string             `json:"opcodes"`
+}
+
+// Compile compiles the Equity source of a contract
+func Compile(src string) (*Contract, error) {
+	node, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkContract(node); err != nil {
+		return nil, err
+	}
+
+	program, err := compileContract(node, false)
+	if err != nil {
+		return nil, err
+	}
+
+	registered, err := compileContract(node, true)
+	if err != nil {
+		return nil, err
+	}
+
+	opcodes, err := vm.Disassemble(program)
+	if err != nil {
+		return nil, err
+	}
+
+	contract := &Contract{
+		Name:        node.name,
+		Params:      node.params,
+		ValueAmount: node.valueAmount,
+		ValueAsset:  node.valueAsset,
+		Clauses:     []*Clause{},
+		Program:     program,
+		Contract:    registered,
+		Opcodes:     opcodes,
+	}
+	for i, clause := range node.clauses {
+		contract.Clauses = append(contract.Clauses, &Clause{Name: clause.name, Selector: uint64(i), Params: clause.params, Values: clauseValues(clause.body)})
+	}
+	return contract, nil
+}
+
+func identName(expr expression) string {
+	if ident, ok := expr.(*identExpr); ok {
+		return ident.name
+	}
+	return ""
+}
+
+// countLocks returns the number of the lock statements, each of them checks
+// the output of the next index
+func countLocks(stmts []statement) uint64 {
+	count := uint64(0)
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *lockStatement:
+			count++
+		case *ifStatement:
+			count += countLocks(stmt.body) + countLocks(stmt.elseBody)
+		}
+	}
+	return count
+}
+
+// assignedNames collects the variables assigned by the statements
+func assignedNames(stmts []statement, names map[string]bool) {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *assignStatement:
+			names[stmt.name] = true
+		case *ifStatement:
+			assignedNames(stmt.body, names)
+			assignedNames(stmt.elseBody, names)
+		}
+	}
+}
+
+// clauseValues lists the values locked by the top level statements with the
+// output indexes they are checked against, the assigned variables are
+// recorded as expressions since their values are only known at run time
+func clauseValues(stmts []statement) []*ClauseValue {
+	assigned := map[string]bool{}
+	assignedNames(stmts, assigned)
+	name := func(expr expression) string {
+		if ident := identName(expr); !assigned[ident] {
+			return ident
+		}
+		return ""
+	}
+
+	values := []*ClauseValue{}
+	index := uint64(0)
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *lockStatement:
+			values = append(values, &ClauseValue{Index: index, Amount: name(stmt.amount), Asset: name(stmt.asset), Program: name(stmt.program)})
+			index++
+		case *ifStatement:
+			index += countLocks(stmt.body) + countLocks(stmt.elseBody)
+		}
+	}
+	return values
+}
+
+func checkParams(params []*Param, names map[string]bool) error {
+	for _, param := range params {
+		if !paramTypes[param.Type] {
+			return errors.WithDetailf(ErrCompile, "parameter %s has unknown type %s", param.Name, param.Type)
+		}
+
+		if names[param.Name] {
+			return errors.WithDetailf(ErrCompile, "name %s is declared twice", param.Name)
+		}
+		names[param.Name] = true
+	}
+	return nil
+}
+
+// disposesValue reports whether the statements lock or unlock the value
+func disposesValue(stmts []statement) bool {
+	for _, stmt := range stmts {
+		switch stmt := stmt.(type) {
+		case *lockStatement, *unlockStatement:
+			return true
+		case *ifStatement:
+			if disposesValue(stmt.body) || disposesValue(stmt.elseBody) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func checkContract(node *contractNode) error {
+	if len(node.clauses) == 0 {
+		return errors.WithDetailf(ErrCompile, "contract %s has no clause", node.name)
+	}
+
+	names := map[string]bool{node.valueAmount: true, node.valueAsset: true}
+	if node.valueAmount == node.valueAsset {
+		return errors.WithDetailf(ErrCompile, "name %s is declared twice", node.valueAmount)
+	}
+
+	if err := checkParams(node.params, names); err != nil {
+		return err
+	}
+
+	clauseNames := map[string]bool{}
+	for _, clause := range node.clauses {
+		if clauseNames[clause.name] {
+			return errors.WithDetailf(ErrCompile, "clause %s is declared twice", clause.name)
+		}
+		clauseNames[clause.name] = true
+
+		clauseParams := map[string]bool{}
+		for name := range names {
+			clauseParams[name] = true
+		}
+
+		if err := checkParams(clause.params, clauseParams); err != nil {
+			return err
+		}
+
+		if !disposesValue(clause.body) {
+			return errors.WithDetailf(ErrCompile, "clause %s neither locks nor unlocks the value", clause.name)
+		}
+	}
+	return nil
+}
+
+// compiler generates the code of one clause, tracking the names of the data
+// stack items to address the variables
+type compiler struct {
+	b         *vmutil.Builder
+	contract  *contractNode
+	stack     []string
+	types     map[string]string
+	lockIndex uint64
+}
+
+func compileContract(node *contractNode, fromAltStack bool) ([]byte, error) {
+	b := vmutil.NewBuilder()
+	if fromAltStack {
+		for range node.params {
+			b.AddOp(vm.OP_FROMALTSTACK)
+		}
+	}
+
+	clauseTargets := []int{}
+	if len(node.clauses) > 1 {
+		// bring the selector above the contract parameters and jump to the
+		// selected clause, the selector 0 falls through to the first one
+		if n := len(node.params); n > 0 {
+			addRoll(b, n)
+		}
+
+		clauseTargets = append(clauseTargets, 0)
+		for i := 1; i < len(node.clauses); i++ {
+			target := b.NewJumpTarget()
+			clauseTargets = append(clauseTargets, target)
+			b.AddOp(vm.OP_DUP).AddUint64(uint64(i)).AddOp(vm.OP_NUMEQUAL).AddJumpIf(target)
+		}
+		b.AddUint64(0).AddOp(vm.OP_NUMEQUAL).AddOp(vm.OP_VERIFY)
+	}
+
+	end := b.NewJumpTarget()
+	for i, clause := range node.clauses {
+		if i > 0 {
+			b.SetJumpTarget(clauseTargets[i])
+			b.AddOp(vm.OP_DROP)
+		}
+
+		c := &compiler{b: b, contract: node, types: map[string]string{}}
+		for _, param := range clause.params {
+			c.stack = append(c.stack, param.Name)
+			c.types[param.Name] = param.Type
+		}
+		for j := len(node.params) - 1; j >= 0; j-- {
+			c.stack = append(c.stack, node.params[j].Name)
+			c.types[node.params[j].Name] = node.params[j].Type
+		}
+
+		if err := c.compileBlock(clause.body); err != nil {
+			return nil, errors.WithDetailf(err, "clause %s", clause.name)
+		}
+
+		b.AddOp(vm.OP_TRUE)
+		if i < len(node.clauses)-1 {
+			b.AddJump(end)
+		}
+	}
+	b.SetJumpTarget(end)
+	return b.Build()
+}
+
+func addRoll(b *vmutil.Builder, depth int) {
+	if depth == 1 {
+		b.AddOp(vm.OP_SWAP)
+		return
+	}
+	b.AddUint64(uint64(depth)).AddOp(vm.OP_ROLL)
+}
+
+func (c *compiler) push(name string) {
+	c.stack = append(c.stack, name)
+}
+
+func (c *compiler) pop(n int) {
+	c.stack = c.stack[:len(c.stack)-n]
+}
+
+// depth returns the position of the variable from the top of the stack
+func (c *compiler) depth(name string) (int, bool) {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		if c.stack[i] == name {
+			return len(c.stack) - 1 - i, true
+		}
+	}
+	return 0, false
+}
+
+// compileBlock compiles the statements of a nested block, the variables it
+// defines are dropped at the end so every branch leaves the same stack
+func (c *compiler) compileBlock(stmts []statement) error {
+	stackSize := len(c.stack)
+	types := map[string]string{}
+	for name, typ := range c.types {
+		types[name] = typ
+	}
+
+	for _, stmt := range stmts {
+		if err := c.compileStatement(stmt); err != nil {
+			return err
+		}
+	}
+
+	for len(c.stack) > stackSize {
+		c.b.AddOp(vm.OP_DROP)
+		c.pop(1)
+	}
+	c.types = types
+	return nil
+}
+
+func (c *compiler) expectType(expr expression, want string, what string) error {
+	typ, err := c.compileExpr(expr)
+	if err != nil {
+		return err
+	}
+
+	if !compatible(want, typ) {
+		return errors.WithDetailf(ErrCompile, "%s got type %s want %s", what, typ, want)
+	}
+	return nil
+}
+
+func (c *compiler) compileStatement(stmt statement) error {
+	switch stmt := stmt.(type) {
+	case *verifyStatement:
+		if err := c.expectType(stmt.expr, booleanType, "verify"); err != nil {
+			return err
+		}
+
+		c.b.AddOp(vm.OP_VERIFY)
+		c.pop(1)
+
+	case *lockStatement:
+		c.b.AddUint64(c.lockIndex)
+		c.push("")
+		c.lockIndex++
+		if err := c.expectType(stmt.amount, amountType, "lock amount"); err != nil {
+			return err
+		}
+
+		if err := c.expectType(stmt.asset, assetType, "lock asset"); err != nil {
+			return err
+		}
+
+		c.b.AddUint64(1)
+		c.push("")
+		if err := c.expectType(stmt.program, programType, "lock program"); err != nil {
+			return err
+		}
+
+		c.b.AddOp(vm.OP_CHECKOUTPUT).AddOp(vm.OP_VERIFY)
+		c.pop(5)
+
+	case *unlockStatement:
+		// the value is released to the spender, the expressions are only
+		// checked for the types
+		for _, expr := range []expression{stmt.amount, stmt.asset} {
+			if err := c.checkOnly(expr); err != nil {
+				return err
+			}
+		}
+
+	case *defineStatement:
+		if !paramTypes[stmt.typ] {
+			return errors.WithDetailf(ErrCompile, "variable %s has unknown type %s", stmt.name, stmt.typ)
+		}
+
+		if _, ok := c.types[stmt.name]; ok || stmt.name == c.contract.valueAmount || stmt.name == c.contract.valueAsset {
+			return errors.WithDetailf(ErrCompile, "name %s is declared twice", stmt.name)
+		}
+
+		if err := c.expectType(stmt.expr, stmt.typ, "define "+stmt.name); err != nil {
+			return err
+		}
+
+		c.stack[len(c.stack)-1] = stmt.name
+		c.types[stmt.name] = stmt.typ
+
+	case *assignStatement:
+		typ, ok := c.types[stmt.name]
+		if !ok {
+			return errors.WithDetailf(ErrCompile, "assign to undefined variable %s", stmt.name)
+		}
+
+		if err := c.expectType(stmt.expr, typ, "assign "+stmt.name); err != nil {
+			return err
+		}
+
+		// replace the variable in place: drop the old value, then roll the
+		// items above it over the new one
+		depth, _ := c.depth(stmt.name)
+		addRoll(c.b, depth)
+		c.b.AddOp(vm.OP_DROP)
+		for i := 0; i < depth-1; i++ {
+			addRoll(c.b, depth-1)
+		}
+		c.pop(1)
+
+	case *ifStatement:
+		if err := c.expectType(stmt.cond, booleanType, "if condition"); err != nil {
+			return err
+		}
+
+		elseTarget, endTarget := c.b.NewJumpTarget(), c.b.NewJumpTarget()
+		c.b.AddOp(vm.OP_NOT).AddJumpIf(elseTarget)
+		c.pop(1)
+
+		lockIndex := c.lockIndex
+		if err := c.compileBlock(stmt.body); err != nil {
+			return err
+		}
+
+		bodyLockIndex := c.lockIndex
+		c.lockIndex = lockIndex
+		c.b.AddJump(endTarget)
+		c.b.SetJumpTarget(elseTarget)
+		if err := c.compileBlock(stmt.elseBody); err != nil {
+			return err
+		}
+
+		if bodyLockIndex > c.lockIndex {
+			c.lockIndex = bodyLockIndex
+		}
+		c.b.SetJumpTarget(endTarget)
+	}
+	return nil
+}
+
+// checkOnly type checks the expression without leaving its code
+func (c *compiler) checkOnly(expr expression) error {
+	saved := c.b
+	c.b = vmutil.NewBuilder()
+	_, err := c.compileExpr(expr)
+	c.b = saved
+	if err != nil {
+		return err
+	}
+
+	c.pop(1)
+	return nil
+}
+
+func (c *compiler) compileExpr(expr expression) (string, error) {
+	switch expr := expr.(type) {
+	case *identExpr:
+		switch expr.name {
+		case c.contract.valueAmount:
+			c.b.AddOp(vm.OP_AMOUNT)
+			c.push("")
+			return amountType, nil
+
+		case c.contract.valueAsset:
+			c.b.AddOp(vm.OP_ASSET)
+			c.push("")
+			return assetType, nil
+		}
+
+		depth, ok := c.depth(expr.name)
+		if !ok {
+			return "", errors.WithDetailf(ErrCompile, "undefined variable %s", expr.name)
+		}
+
+		switch depth {
+		case 0:
+			c.b.AddOp(vm.OP_DUP)
+		case 1:
+			c.b.AddOp(vm.OP_OVER)
+		default:
+			c.b.AddUint64(uint64(depth)).AddOp(vm.OP_PICK)
+		}
+		c.push("")
+		return c.types[expr.name], nil
+
+	case *integerLiteral:
+		c.b.AddUint64(expr.value)
+		c.push("")
+		return integerType, nil
+
+	case *bytesLiteral:
+		c.b.AddData(expr.value)
+		c.push("")
+		return stringType, nil
+
+	case *booleanLiteral:
+		c.b.AddData(vm.BoolBytes(expr.value))
+		c.push("")
+		return booleanType, nil
+
+	case *listExpr:
+		return "", errors.WithDetail(ErrCompile, "list is only allowed as an argument of checkTxMultiSig")
+
+	case *unaryExpr:
+		typ, err := c.compileExpr(expr.expr)
+		if err != nil {
+			return "", err
+		}
+
+		if expr.op == "!" {
+			if typ != booleanType {
+				return "", errors.WithDetailf(ErrCompile, "operator ! on type %s", typ)
+			}
+			c.b.AddOp(vm.OP_NOT)
+			return booleanType, nil
+		}
+
+		c.b.AddOp(vm.OP_INVERT)
+		return typ, nil
+
+	case *binaryExpr:
+		return c.compileBinary(expr)
+
+	case *callExpr:
+		return c.compileCall(expr)
+	}
+	return "", errors.WithDetailf(ErrCompile, "unexpected expression %T", expr)
+}
+
+var (
+	numericOps = map[string]vm.Op{
+		"+": vm.OP_ADD, "-": vm.OP_SUB, "*": vm.OP_MUL, "/": vm.OP_DIV, "%": vm.OP_MOD,
+		"<<": vm.OP_LSHIFT, ">>": vm.OP_RSHIFT,
+	}
+	comparisonOps = map[string]vm.Op{
+		"<": vm.OP_LESSTHAN, ">": vm.OP_GREATERTHAN, "<=": vm.OP_LESSTHANOREQUAL, ">=": vm.OP_GREATERTHANOREQUAL,
+	}
+	bitwiseOps = map[string]vm.Op{"&": vm.OP_AND, "|": vm.OP_OR, "^": vm.OP_XOR}
+	booleanOps = map[string]vm.Op{"&&": vm.OP_BOOLAND, "||": vm.OP_BOOLOR}
+)
+
+func (c *compiler) compileBinary(expr *binaryExpr) (string, error) {
+	left, err := c.compileExpr(expr.left)
+	if err != nil {
+		return "", err
+	}
+
+	right, err := c.compileExpr(expr.right)
+	if err != nil {
+		return "", err
+	}
+
+	c.pop(1)
+	mismatch := errors.WithDetailf(ErrCompile, "operator %s on types %s and %s", expr.op, left, right)
+	if op, ok := numericOps[expr.op]; ok {
+		if !isNumeric(left) || !isNumeric(right) {
+			return "", mismatch
+		}
+
+		c.b.AddOp(op)
+		if left == amountType && right == amountType && expr.op != "*" && expr.op != "/" {
+			return amountType, nil
+		}
+		return integerType, nil
+	}
+
+	if op, ok := comparisonOps[expr.op]; ok {
+		if !isNumeric(left) || !isNumeric(right) {
+			return "", mismatch
+		}
+
+		c.b.AddOp(op)
+		return booleanType, nil
+	}
+
+	if op, ok := bitwiseOps[expr.op]; ok {
+		if !compatible(left, right) && !compatible(right, left) {
+			return "", mismatch
+		}
+
+		c.b.AddOp(op)
+		return left, nil
+	}
+
+	if op, ok := booleanOps[expr.op]; ok {
+		if left != booleanType || right != booleanType {
+			return "", mismatch
+		}
+
+		c.b.AddOp(op)
+		return booleanType, nil
+	}
+
+	if !compatible(left, right) && !compatible(right, left) {
+		return "", mismatch
+	}
+
+	switch {
+	case isNumeric(left) && expr.op == "==":
+		c.b.AddOp(vm.OP_NUMEQUAL)
+	case isNumeric(left):
+		c.b.AddOp(vm.OP_NUMNOTEQUAL)
+	case expr.op == "==":
+		c.b.AddOp(vm.OP_EQUAL)
+	default:
+		c.b.AddOp(vm.OP_EQUAL).AddOp(vm.OP_NOT)
+	}
+	return booleanType, nil
+}
+
+func (c *compiler) compileArgs(name string, args []expression, types ...string) error {
+	if len(args) != len(types) {
+		return errors.WithDetailf(ErrCompile, "%s takes %d arguments, got %d", name, len(types), len(args))
+	}
+
+	for i, arg := range args {
+		if types[i] == "" {
+			typ, err := c.compileExpr(arg)
+			if err != nil {
+				return err
+			}
+
+			if !isBytes(typ) {
+				return errors.WithDetailf(ErrCompile, "%s argument %d got type %s want a byte string", name, i, typ)
+			}
+			continue
+		}
+
+		if err := c.expectType(arg, types[i], fmt.Sprintf("%s argument %d", name, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// builtin is a function compiled into a fixed opcode sequence, the empty
+// parameter type accepts any byte string
+type builtin struct {
+	params []string
+	ops    []vm.Op
+	result string
+}
+
+var builtins = map[string]*builtin{
+	"sha3":       {params: []string{""}, ops: []vm.Op{vm.OP_SHA3}, result: hashType},
+	"sha256":     {params: []string{""}, ops: []vm.Op{vm.OP_SHA256}, result: hashType},
+	"size":       {params: []string{""}, ops: []vm.Op{vm.OP_SIZE, vm.OP_NIP}, result: integerType},
+	"min":        {params: []string{integerType, integerType}, ops: []vm.Op{vm.OP_MIN}, result: integerType},
+	"max":        {params: []string{integerType, integerType}, ops: []vm.Op{vm.OP_MAX}, result: integerType},
+	"concat":     {params: []string{"", ""}, ops: []vm.Op{vm.OP_CAT}, result: stringType},
+	"concatpush": {params: []string{"", ""}, ops: []vm.Op{vm.OP_CATPUSHDATA}, result: stringType},
+	"below":      {params: []string{integerType}, ops: []vm.Op{vm.OP_BLOCKHEIGHT, vm.OP_GREATERTHAN}, result: booleanType},
+	"above":      {params: []string{integerType}, ops: []vm.Op{vm.OP_BLOCKHEIGHT, vm.OP_LESSTHAN}, result: booleanType},
+}
+
+func (c *compiler) compileCall(expr *callExpr) (string, error) {
+	switch expr.name {
+	case "checkTxSig":
+		if len(expr.args) != 2 {
+			return "", errors.WithDetailf(ErrCompile, "checkTxSig takes 2 arguments, got %d", len(expr.args))
+		}
+
+		if err := c.expectType(expr.args[1], signatureType, "checkTxSig signature"); err != nil {
+			return "", err
+		}
+
+		c.b.AddOp(vm.OP_TXSIGHASH)
+		c.push("")
+		if err := c.expectType(expr.args[0], publicKeyType, "checkTxSig public key"); err != nil {
+			return "", err
+		}
+
+		c.b.AddOp(vm.OP_CHECKSIG)
+		c.pop(2)
+		return booleanType, nil
+
+	case "checkTxMultiSig":
+		return c.compileMultiSig(expr)
+	}
+
+	fn, ok := builtins[expr.name]
+	if !ok {
+		return "", errors.WithDetailf(ErrCompile, "undefined function %s", expr.name)
+	}
+
+	if err := c.compileArgs(expr.name, expr.args, fn.params...); err != nil {
+		return "", err
+	}
+
+	for _, op := range fn.ops {
+		c.b.AddOp(op)
+	}
+	c.pop(len(fn.params) - 1)
+	return fn.result, nil
+}
+
+// compileMultiSig pushes the signatures and the keys in reverse order, so
+// CHECKMULTISIG matches them in the order of the lists
+func (c *compiler) compileMultiSig(expr *callExpr) (string, error) {
+	if len(expr.args) != 2 {
+		return "", errors.WithDetailf(ErrCompile, "checkTxMultiSig takes 2 arguments, got %d", len(expr.args))
+	}
+
+	keys, ok := expr.args[0].(*listExpr)
+	sigs, ok2 := expr.args[1].(*listExpr)
+	if !ok || !ok2 {
+		return "", errors.WithDetail(ErrCompile, "checkTxMultiSig takes a list of public keys and a list of signatures")
+	}
+
+	if len(sigs.items) == 0 || len(sigs.items) > len(keys.items) {
+		return "", errors.WithDetailf(ErrCompile, "checkTxMultiSig takes %d signatures of %d public keys", len(sigs.items), len(keys.items))
+	}
+
+	for i := len(sigs.items) - 1; i >= 0; i-- {
+		if err := c.expectType(sigs.items[i], signatureType, "checkTxMultiSig signature"); err != nil {
+			return "", err
+		}
+	}
+
+	c.b.AddOp(vm.OP_TXSIGHASH)
+	c.push("")
+	for i := len(keys.items) - 1; i >= 0; i-- {
+		if err := c.expectType(keys.items[i], publicKeyType, "checkTxMultiSig public key"); err != nil {
+			return "", err
+		}
+	}
+
+	c.b.AddUint64(uint64(len(sigs.items))).AddUint64(uint64(len(keys.items))).AddOp(vm.OP_CHECKMULTISIG)
+	c.pop(len(sigs.items) + len(keys.items))
+	return booleanType, nil
+}
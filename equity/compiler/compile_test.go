@@ -0,0 +1,213 @@
+package compiler
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/vm"
+	"github.com/bytom/bytom/testutil"
+)
+
+const lockWithPublicKey = `
+contract LockWithPublicKey(publicKey: PublicKey) locks valueAmount of valueAsset {
+  clause spend(sig: Signature) {
+    verify checkTxSig(publicKey, sig)
+    unlock valueAmount of valueAsset
+  }
+}`
+
+const tradeOffer = `
+// the value is sold for the requested asset, or reclaimed by the seller
+contract TradeOffer(assetRequested: Asset, amountRequested: Amount, seller: Program, cancelKey: PublicKey) locks valueAmount of valueAsset {
+  clause trade() {
+    lock amountRequested of assetRequested with seller
+    unlock valueAmount of valueAsset
+  }
+  clause cancel(sellerSig: Signature) {
+    verify checkTxSig(cancelKey, sellerSig)
+    unlock valueAmount of valueAsset
+  }
+}`
+
+const capped = `
+contract Capped(limit: Integer) locks valueAmount of valueAsset {
+  clause check(a, b: Integer, want: Integer) {
+    define total: Integer = a + b
+    if total > limit {
+      assign total = limit
+    } else {
+      define extra: Integer = 1
+      assign total = total + extra
+    }
+    verify total == want && min(a, b) <= total
+    verify size(sha3("bytom")) == 32
+    unlock valueAmount of valueAsset
+  }
+}`
+
+var sigHash = bytes.Repeat([]byte{0x01}, 32)
+
+func runProgram(program []byte, args, stateData [][]byte, checkOutput func(uint64, uint64, []byte, uint64, []byte, [][]byte, bool) (bool, error)) error {
+	txVersion, amount, assetID := uint64(1), uint64(100), bytes.Repeat([]byte{0xaa}, 32)
+	_, err := vm.Verify(&vm.Context{
+		VMVersion:   1,
+		Code:        program,
+		Arguments:   args,
+		StateData:   stateData,
+		TxVersion:   &txVersion,
+		Amount:      &amount,
+		AssetID:     &assetID,
+		TxSigHash:   func() []byte { return sigHash },
+		CheckOutput: checkOutput,
+	}, 100000)
+	return err
+}
+
+func stringArg(data []byte) ContractArg {
+	str := chainjson.HexBytes(data)
+	return ContractArg{String: &str}
+}
+
+func TestCompileLockWithPublicKey(t *testing.T) {
+	pub, prv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contract, err := Compile(lockWithPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if contract.Name != "LockWithPublicKey" || len(contract.Clauses) != 1 || contract.Clauses[0].Params[0].Type != signatureType {
+		t.Fatalf("got abi %+v", contract)
+	}
+
+	args := []ContractArg{stringArg(pub)}
+	program, err := contract.Instantiate(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stateData, err := contract.StateData(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := ed25519.Sign(prv, sigHash)
+	if err := runProgram(program, [][]byte{sig}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := runProgram(contract.Contract, [][]byte{sig}, [][]byte{stateData[0]}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	sig[0] ^= 0xff
+	if err := runProgram(program, [][]byte{sig}, nil, nil); errors.Root(err) != vm.ErrVerifyFailed {
+		t.Fatalf("got error %v want %v", err, vm.ErrVerifyFailed)
+	}
+
+	if _, err := contract.Instantiate([]ContractArg{}); errors.Root(err) != ErrContractArgs {
+		t.Fatalf("got error %v want %v", err, ErrContractArgs)
+	}
+}
+
+func TestCompileTradeOffer(t *testing.T) {
+	pub, prv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	contract, err := Compile(tradeOffer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantValues := []*ClauseValue{{Index: 0, Amount: "amountRequested", Asset: "assetRequested", Program: "seller"}}
+	if !testutil.DeepEqual(contract.Clauses[0].Values, wantValues) || len(contract.Clauses[1].Values) != 0 {
+		t.Fatalf("got clause values %v %v", contract.Clauses[0].Values, contract.Clauses[1].Values)
+	}
+
+	requested, seller, amount := bytes.Repeat([]byte{0xbb}, 32), []byte{0x51}, uint64(500)
+	program, err := contract.Instantiate([]ContractArg{stringArg(requested), {Integer: &amount}, stringArg(seller), stringArg(pub)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	checked := false
+	checkOutput := func(index uint64, gotAmount uint64, assetID []byte, vmVersion uint64, code []byte, state [][]byte, expansion bool) (bool, error) {
+		checked = true
+		return index == 0 && gotAmount == amount && bytes.Equal(assetID, requested) && vmVersion == 1 && bytes.Equal(code, seller) && len(state) == 0, nil
+	}
+
+	if err := runProgram(program, [][]byte{vm.Uint64Bytes(0)}, nil, checkOutput); err != nil || !checked {
+		t.Fatalf("trade clause got %v, output checked %v", err, checked)
+	}
+
+	amount = 400
+	if err := runProgram(program, [][]byte{vm.Uint64Bytes(0)}, nil, checkOutput); errors.Root(err) != vm.ErrVerifyFailed {
+		t.Fatalf("trade clause with a wrong payment got %v", err)
+	}
+
+	sig := ed25519.Sign(prv, sigHash)
+	if err := runProgram(program, [][]byte{sig, vm.Uint64Bytes(1)}, nil, nil); err != nil {
+		t.Fatalf("cancel clause got %v", err)
+	}
+
+	if err := runProgram(program, [][]byte{sig, vm.Uint64Bytes(2)}, nil, nil); err == nil {
+		t.Fatal("unknown selector succeeded")
+	}
+}
+
+func TestCompileStatements(t *testing.T) {
+	contract, err := Compile(capped)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limit := uint64(10)
+	program, err := contract.Instantiate([]ContractArg{{Integer: &limit}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		a, b, want uint64
+		ok         bool
+	}{
+		{a: 3, b: 4, want: 8, ok: true},
+		{a: 6, b: 7, want: 10, ok: true},
+		{a: 3, b: 4, want: 7, ok: false},
+	}
+	for i, c := range cases {
+		err := runProgram(program, [][]byte{vm.Uint64Bytes(c.a), vm.Uint64Bytes(c.b), vm.Uint64Bytes(c.want)}, nil, nil)
+		if (err == nil) != c.ok {
+			t.Errorf("case %d: got error %v want ok %v", i, err, c.ok)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	cases := []string{
+		`contract C() locks a of b { clause c() { verify x unlock a of b } }`,
+		`contract C() locks a of b { clause c() { verify 1 unlock a of b } }`,
+		`contract C(p: PublicKey) locks a of b { clause c() { verify checkTxSig(p) unlock a of b } }`,
+		`contract C(p: Integer) locks a of b { clause c() { verify p == 1 } }`,
+		`contract C(p: Money) locks a of b { clause c() { unlock a of b } }`,
+		`contract C(p: Integer) locks a of b { clause c(p: Integer) { unlock a of b } }`,
+		`contract C() locks a of b { clause c() { lock a of b with a unlock a of b } }`,
+		`contract C() locks a of b { clause c() { unlock a of b }`,
+		`contract C() locks a of b { clause c() { verify "abc } }`,
+	}
+
+	for i, src := range cases {
+		if _, err := Compile(src); errors.Root(err) != ErrCompile {
+			t.Errorf("case %d: got error %v want %v", i, err, ErrCompile)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package compiler
+
+import (
+	"encoding/binary"
+
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/vm"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+// ContractArg is an argument of a contract parameter, exactly one of the
+// fields is set by the parameter type
+type ContractArg struct {
+	Boolean *bool               `json:"boolean,omitempty"`
+	Integer *uint64             `json:"integer,omitempty"`
+	String  *chainjson.HexBytes `json:"string,omitempty"`
+}
+
+// encodeArgs converts the arguments to the stack items of the parameters
+func encodeArgs(params []*Param, args []ContractArg) ([][]byte, error) {
+	if len(args) != len(params) {
+		return nil, errors.WithDetailf(ErrContractArgs, "got %d arguments want %d", len(args), len(params))
+	}
+
+	items := [][]byte{}
+	for i, param := range params {
+		arg := args[i]
+		switch {
+		case param.Type == booleanType && arg.Boolean != nil:
+			items = append(items, vm.BoolBytes(*arg.Boolean))
+		case isNumeric(param.Type) && arg.Integer != nil:
+			items = append(items, vm.Uint64Bytes(*arg.Integer))
+		case isBytes(param.Type) && arg.String != nil:
+			items = append(items, *arg.String)
+		default:
+			return nil, errors.WithDetailf(ErrContractArgs, "argument %d doesn't match parameter %s of type %s", i, param.Name, param.Type)
+		}
+	}
+	return items, nil
+}
+
+// relocate moves the jump targets of the program by the offset, since the
+// jump addresses are absolute to the start of the program
+func relocate(program []byte, offset uint32) ([]byte, error) {
+	result := append([]byte{}, program...)
+	for pc := uint32(0); pc < uint32(len(result)); {
+		inst, err := vm.ParseOp(result, pc)
+		if err != nil {
+			return nil, err
+		}
+
+		if inst.Op == vm.OP_JUMP || inst.Op == vm.OP_JUMPIF {
+			address := result[pc+1 : pc+inst.Len]
+			binary.LittleEndian.PutUint32(address, binary.LittleEndian.Uint32(address)+offset)
+		}
+		pc += inst.Len
+	}
+	return result, nil
+}
+
+// Instantiate builds the control program of the contract with the arguments,
+// the first argument is pushed last to be on the top of the stack
+func (c *Contract) Instantiate(args []ContractArg) ([]byte, error) {
+	items, err := encodeArgs(c.Params, args)
+	if err != nil {
+		return nil, err
+	}
+
+	b := vmutil.NewBuilder()
+	for i := len(items) - 1; i >= 0; i-- {
+		b.AddData(items[i])
+	}
+
+	prefix, err := b.Build()
+	if err != nil {
+		return nil, err
+	}
+
+	program, err := relocate(c.Program, uint32(len(prefix)))
+	if err != nil {
+		return nil, err
+	}
+	return append(prefix, program...), nil
+}
+
+// StateData returns the state data of an output locked by the BCRP
+// registered contract, in the parameter order
+func (c *Contract) StateData(args []ContractArg) ([]chainjson.HexBytes, error) {
+	items, err := encodeArgs(c.Params, args)
+	if err != nil {
+		return nil, err
+	}
+
+	stateData := []chainjson.HexBytes{}
+	for _, item := range items {
+		stateData = append(stateData, item)
+	}
+	return stateData, nil
+}
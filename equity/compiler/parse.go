@@ -0,0 +1,499 @@
+package compiler
+
+import (
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bytom/bytom/errors"
+)
+
+const (
+	tokIdent = iota
+	tokInteger
+	tokHex
+	tokString
+	tokPunct
+	tokEOF
+)
+
+type token struct {
+	kind int
+	text string
+	line int
+}
+
+// punctuations of the language, the longer ones go first
+var puncts = []string{
+	"==", "!=", "<=", ">=", "<<", ">>", "&&", "||",
+	"(", ")", "{", "}", "[", "]", ",", ":", "=",
+	"<", ">", "+", "-", "*", "/", "%", "&", "|", "^", "!", "~",
+}
+
+func lex(src string) ([]*token, error) {
+	tokens := []*token{}
+	line := 1
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+
+		case strings.HasPrefix(src[i:], "0x"):
+			j := i + 2
+			for j < len(src) && strings.IndexByte("0123456789abcdefABCDEF", src[j]) >= 0 {
+				j++
+			}
+			tokens = append(tokens, &token{kind: tokHex, text: src[i+2 : j], line: line})
+			i = j
+
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(src) && src[j] >= '0' && src[j] <= '9' {
+				j++
+			}
+			tokens = append(tokens, &token{kind: tokInteger, text: src[i:j], line: line})
+			i = j
+
+		case c == '"':
+			j := strings.IndexByte(src[i+1:], '"')
+			if j < 0 {
+				return nil, errors.WithDetailf(ErrCompile, "line %d: unterminated string", line)
+			}
+			tokens = append(tokens, &token{kind: tokString, text: src[i+1 : i+1+j], line: line})
+			i += j + 2
+
+		case c == '_' || unicode.IsLetter(rune(c)):
+			j := i
+			for j < len(src) && (src[j] == '_' || unicode.IsLetter(rune(src[j])) || unicode.IsDigit(rune(src[j]))) {
+				j++
+			}
+			tokens = append(tokens, &token{kind: tokIdent, text: src[i:j], line: line})
+			i = j
+
+		default:
+			punct := ""
+			for _, p := range puncts {
+				if strings.HasPrefix(src[i:], p) {
+					punct = p
+					break
+				}
+			}
+			if punct == "" {
+				return nil, errors.WithDetailf(ErrCompile, "line %d: unexpected character %q", line, c)
+			}
+			tokens = append(tokens, &token{kind: tokPunct, text: punct, line: line})
+			i += len(punct)
+		}
+	}
+	return append(tokens, &token{kind: tokEOF, line: line}), nil
+}
+
+type parser struct {
+	tokens []*token
+	pos    int
+}
+
+func (p *parser) peek() *token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() *token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return errors.WithDetailf(ErrCompile, "line %d: "+format, append([]interface{}{p.peek().line}, args...)...)
+}
+
+// is reports whether the next token is the keyword or the punctuation
+func (p *parser) is(text string) bool {
+	tok := p.peek()
+	return (tok.kind == tokIdent || tok.kind == tokPunct) && tok.text == text
+}
+
+func (p *parser) expect(text string) error {
+	if !p.is(text) {
+		return p.errorf("expected %q, got %q", text, p.peek().text)
+	}
+	p.next()
+	return nil
+}
+
+func (p *parser) ident() (string, error) {
+	if p.peek().kind != tokIdent {
+		return "", p.errorf("expected identifier, got %q", p.peek().text)
+	}
+	return p.next().text, nil
+}
+
+func parse(src string) (*contractNode, error) {
+	tokens, err := lex(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	contract, err := p.parseContract()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().kind != tokEOF {
+		return nil, p.errorf("unexpected %q after the contract", p.peek().text)
+	}
+	return contract, nil
+}
+
+// contract Name(params) locks amount of asset { clauses }
+func (p *parser) parseContract() (*contractNode, error) {
+	contract := &contractNode{}
+	var err error
+	if err = p.expect("contract"); err != nil {
+		return nil, err
+	}
+
+	if contract.name, err = p.ident(); err != nil {
+		return nil, err
+	}
+
+	if contract.params, err = p.parseParams(); err != nil {
+		return nil, err
+	}
+
+	if err = p.expect("locks"); err != nil {
+		return nil, err
+	}
+
+	if contract.valueAmount, err = p.ident(); err != nil {
+		return nil, err
+	}
+
+	if err = p.expect("of"); err != nil {
+		return nil, err
+	}
+
+	if contract.valueAsset, err = p.ident(); err != nil {
+		return nil, err
+	}
+
+	if err = p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	for p.is("clause") {
+		clause, err := p.parseClause()
+		if err != nil {
+			return nil, err
+		}
+		contract.clauses = append(contract.clauses, clause)
+	}
+	return contract, p.expect("}")
+}
+
+// (a, b: Type, c: Type)
+func (p *parser) parseParams() ([]*Param, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	params := []*Param{}
+	for !p.is(")") {
+		if len(params) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		names := []string{}
+		for {
+			name, err := p.ident()
+			if err != nil {
+				return nil, err
+			}
+
+			names = append(names, name)
+			if !p.is(",") {
+				break
+			}
+			p.next()
+		}
+
+		if err := p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		typ, err := p.ident()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, name := range names {
+			params = append(params, &Param{Name: name, Type: typ})
+		}
+	}
+	p.next()
+	return params, nil
+}
+
+func (p *parser) parseClause() (*clauseNode, error) {
+	clause := &clauseNode{}
+	var err error
+	p.next()
+	if clause.name, err = p.ident(); err != nil {
+		return nil, err
+	}
+
+	if clause.params, err = p.parseParams(); err != nil {
+		return nil, err
+	}
+
+	clause.body, err = p.parseBlock()
+	return clause, err
+}
+
+func (p *parser) parseBlock() ([]statement, error) {
+	if err := p.expect("{"); err != nil {
+		return nil, err
+	}
+
+	stmts := []statement{}
+	for !p.is("}") {
+		stmt, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		stmts = append(stmts, stmt)
+	}
+	p.next()
+	return stmts, nil
+}
+
+func (p *parser) parseStatement() (statement, error) {
+	keyword, err := p.ident()
+	if err != nil {
+		return nil, err
+	}
+
+	switch keyword {
+	case "verify":
+		expr, err := p.parseExpr(0)
+		return &verifyStatement{expr: expr}, err
+
+	case "lock":
+		stmt := &lockStatement{}
+		if stmt.amount, err = p.parseExpr(0); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect("of"); err != nil {
+			return nil, err
+		}
+
+		if stmt.asset, err = p.parseExpr(0); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect("with"); err != nil {
+			return nil, err
+		}
+
+		stmt.program, err = p.parseExpr(0)
+		return stmt, err
+
+	case "unlock":
+		stmt := &unlockStatement{}
+		if stmt.amount, err = p.parseExpr(0); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect("of"); err != nil {
+			return nil, err
+		}
+
+		stmt.asset, err = p.parseExpr(0)
+		return stmt, err
+
+	case "define":
+		stmt := &defineStatement{}
+		if stmt.name, err = p.ident(); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect(":"); err != nil {
+			return nil, err
+		}
+
+		if stmt.typ, err = p.ident(); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect("="); err != nil {
+			return nil, err
+		}
+
+		stmt.expr, err = p.parseExpr(0)
+		return stmt, err
+
+	case "assign":
+		stmt := &assignStatement{}
+		if stmt.name, err = p.ident(); err != nil {
+			return nil, err
+		}
+
+		if err = p.expect("="); err != nil {
+			return nil, err
+		}
+
+		stmt.expr, err = p.parseExpr(0)
+		return stmt, err
+
+	case "if":
+		stmt := &ifStatement{}
+		if stmt.cond, err = p.parseExpr(0); err != nil {
+			return nil, err
+		}
+
+		if stmt.body, err = p.parseBlock(); err != nil {
+			return nil, err
+		}
+
+		if p.is("else") {
+			p.next()
+			stmt.elseBody, err = p.parseBlock()
+		}
+		return stmt, err
+	}
+	return nil, errors.WithDetailf(ErrCompile, "line %d: unknown statement %q", p.tokens[p.pos-1].line, keyword)
+}
+
+// binaryPrecedence of the binary operators, the greater binds tighter
+var binaryPrecedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3,
+	"<": 4, ">": 4, "<=": 4, ">=": 4,
+	"|":  5,
+	"^":  6,
+	"&":  7,
+	"<<": 8, ">>": 8,
+	"+": 9, "-": 9,
+	"*": 10, "/": 10, "%": 10,
+}
+
+// parseExpr parses the binary expression of the operators binding tighter
+// than the precedence
+func (p *parser) parseExpr(precedence int) (expression, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		opPrecedence, ok := binaryPrecedence[tok.text]
+		if tok.kind != tokPunct || !ok || opPrecedence <= precedence {
+			return left, nil
+		}
+
+		p.next()
+		right, err := p.parseExpr(opPrecedence)
+		if err != nil {
+			return nil, err
+		}
+		left = &binaryExpr{op: tok.text, left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (expression, error) {
+	if p.is("!") || p.is("~") {
+		op := p.next().text
+		expr, err := p.parseUnary()
+		return &unaryExpr{op: op, expr: expr}, err
+	}
+	return p.parsePrimary()
+}
+
+func (p *parser) parseExprList(end string) ([]expression, error) {
+	exprs := []expression{}
+	for !p.is(end) {
+		if len(exprs) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+
+		expr, err := p.parseExpr(0)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+	}
+	p.next()
+	return exprs, nil
+}
+
+func (p *parser) parsePrimary() (expression, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokInteger:
+		value, err := strconv.ParseUint(tok.text, 10, 64)
+		if err != nil {
+			return nil, errors.WithDetailf(ErrCompile, "line %d: invalid integer %s", tok.line, tok.text)
+		}
+		return &integerLiteral{value: value}, nil
+
+	case tokHex:
+		value, err := hex.DecodeString(tok.text)
+		if err != nil {
+			return nil, errors.WithDetailf(ErrCompile, "line %d: invalid hex 0x%s", tok.line, tok.text)
+		}
+		return &bytesLiteral{value: value}, nil
+
+	case tokString:
+		return &bytesLiteral{value: []byte(tok.text)}, nil
+
+	case tokIdent:
+		switch tok.text {
+		case "true", "false":
+			return &booleanLiteral{value: tok.text == "true"}, nil
+		}
+
+		if !p.is("(") {
+			return &identExpr{name: tok.text}, nil
+		}
+
+		p.next()
+		args, err := p.parseExprList(")")
+		return &callExpr{name: tok.text, args: args}, err
+
+	case tokPunct:
+		switch tok.text {
+		case "(":
+			expr, err := p.parseExpr(0)
+			if err != nil {
+				return nil, err
+			}
+			return expr, p.expect(")")
+
+		case "[":
+			items, err := p.parseExprList("]")
+			return &listExpr{items: items}, err
+		}
+	}
+	return nil, errors.WithDetailf(ErrCompile, "line %d: unexpected %q", tok.line, tok.text)
+}
@@ -32,6 +32,8 @@ var (
 	ErrWSInternal = errors.New("Websocket Internal error")
 	// ErrWSClientQuit means the websocket client is disconnected
 	ErrWSClientQuit = errors.New("Websocket client quit")
+	// ErrWSTraceID means the contract instance topic is requested without a trace id
+	ErrWSTraceID = errors.New("Websocket request missing trace id")
 
 	// timeZeroVal is simply the zero value for a time.Time and is used to avoid creating multiple instances.
 	timeZeroVal time.Time
@@ -47,16 +49,18 @@ func (s semaphore) acquire() { s <- struct{}{} }
 func (s semaphore) release() { <-s }
 
 // wsTopicHandler describes a callback function used to handle a specific topic.
-type wsTopicHandler func(*WSClient)
+type wsTopicHandler func(*WSClient, *WSRequest) error
 
 // wsHandlers maps websocket topic strings to appropriate websocket handler
 // functions.  This is set by init because help references wsHandlers and thus
 // causes a dependency loop.
 var wsHandlers = map[string]wsTopicHandler{
-	"notify_raw_blocks":            handleNotifyBlocks,
-	"notify_new_transactions":      handleNotifyNewTransactions,
-	"stop_notify_raw_blocks":       handleStopNotifyBlocks,
-	"stop_notify_new_transactions": handleStopNotifyNewTransactions,
+	"notify_raw_blocks":             handleNotifyBlocks,
+	"notify_new_transactions":       handleNotifyNewTransactions,
+	"notify_contract_instance":      handleNotifyContractInstance,
+	"stop_notify_raw_blocks":        handleStopNotifyBlocks,
+	"stop_notify_new_transactions":  handleStopNotifyNewTransactions,
+	"stop_notify_contract_instance": handleStopNotifyContractInstance,
 }
 
 // responseMessage houses a message to send to a connected websocket client as
@@ -159,7 +163,7 @@ out:
 
 		c.serviceRequestSem.acquire()
 		go func() {
-			c.serviceRequest(request.Topic)
+			c.serviceRequest(&request)
 			c.serviceRequestSem.release()
 		}()
 	}
@@ -170,15 +174,17 @@ out:
 	log.WithFields(log.Fields{"module": logModule, "remoteAddress": c.addr}).Debug("Websocket client input handler done")
 }
 
-func (c *WSClient) serviceRequest(topic string) {
+func (c *WSClient) serviceRequest(request *WSRequest) {
 	var respErr error
 
-	if wsHandler, ok := wsHandlers[topic]; ok {
-		wsHandler(c)
+	if wsHandler, ok := wsHandlers[request.Topic]; ok {
+		if err := wsHandler(c, request); err != nil {
+			respErr = errors.Wrap(err, ErrWSParse)
+		}
 	} else {
-		err := fmt.Errorf("There is not this topic: %s", topic)
+		err := fmt.Errorf("There is not this topic: %s", request.Topic)
 		respErr = errors.Wrap(err, ErrWSInternal)
-		log.WithFields(log.Fields{"module": logModule, "topic": topic}).Debug("There is not this topic")
+		log.WithFields(log.Fields{"module": logModule, "topic": request.Topic}).Debug("There is not this topic")
 	}
 
 	resp := NewWSResponse(NTRequestStatus.String(), nil, respErr)
@@ -352,21 +358,45 @@ func (c *WSClient) WaitForShutdown() {
 }
 
 // handleNotifyBlocks implements the notifyblocks topic extension for websocket connections.
-func handleNotifyBlocks(wsc *WSClient) {
+func handleNotifyBlocks(wsc *WSClient, _ *WSRequest) error {
 	wsc.notificationMgr.RegisterBlockUpdates(wsc)
+	return nil
 }
 
 // handleStopNotifyBlocks implements the stopnotifyblocks topic extension for websocket connections.
-func handleStopNotifyBlocks(wsc *WSClient) {
+func handleStopNotifyBlocks(wsc *WSClient, _ *WSRequest) error {
 	wsc.notificationMgr.UnregisterBlockUpdates(wsc)
+	return nil
 }
 
 // handleNotifyNewTransations implements the notifynewtransactions topic extension for websocket connections.
-func handleNotifyNewTransactions(wsc *WSClient) {
+func handleNotifyNewTransactions(wsc *WSClient, _ *WSRequest) error {
 	wsc.notificationMgr.RegisterNewMempoolTxsUpdates(wsc)
+	return nil
 }
 
 // handleStopNotifyNewTransations implements the stopnotifynewtransactions topic extension for websocket connections.
-func handleStopNotifyNewTransactions(wsc *WSClient) {
+func handleStopNotifyNewTransactions(wsc *WSClient, _ *WSRequest) error {
 	wsc.notificationMgr.UnregisterNewMempoolTxsUpdates(wsc)
+	return nil
+}
+
+// handleNotifyContractInstance implements the notifycontractinstance topic extension for websocket connections.
+func handleNotifyContractInstance(wsc *WSClient, request *WSRequest) error {
+	if request.TraceID == "" {
+		return ErrWSTraceID
+	}
+
+	wsc.notificationMgr.RegisterContractInstanceUpdates(wsc, request.TraceID)
+	return nil
+}
+
+// handleStopNotifyContractInstance implements the stopnotifycontractinstance topic extension for websocket connections.
+func handleStopNotifyContractInstance(wsc *WSClient, request *WSRequest) error {
+	if request.TraceID == "" {
+		return ErrWSTraceID
+	}
+
+	wsc.notificationMgr.UnregisterContractInstanceUpdates(wsc, request.TraceID)
+	return nil
 }
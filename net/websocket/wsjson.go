@@ -2,7 +2,8 @@ package websocket
 
 // WSRequest means the data structure of the request
 type WSRequest struct {
-	Topic string `json:"topic"`
+	Topic   string `json:"topic"`
+	TraceID string `json:"trace_id,omitempty"`
 }
 
 // NewWSRequest creates a request data object
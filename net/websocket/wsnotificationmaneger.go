@@ -7,6 +7,7 @@ import (
 
 	log "github.com/sirupsen/logrus"
 
+	"github.com/bytom/bytom/contract"
 	"github.com/bytom/bytom/event"
 	"github.com/bytom/bytom/protocol"
 	"github.com/bytom/bytom/protocol/bc"
@@ -17,6 +18,7 @@ import (
 type notificationBlockConnected types.Block
 type notificationBlockDisconnected types.Block
 type notificationTxDescAcceptedByMempool protocol.TxDesc
+type notificationContractInstance contract.InstanceEvent
 
 // Notification control requests
 type notificationRegisterClient WSClient
@@ -26,6 +28,14 @@ type notificationUnregisterBlocks WSClient
 type notificationRegisterNewMempoolTxs WSClient
 type notificationUnregisterNewMempoolTxs WSClient
 
+// notificationContractInstanceUpdates (un)registers a client for the state
+// changes of the contract instance with the trace id
+type notificationContractInstanceUpdates struct {
+	wsc      *WSClient
+	traceID  string
+	register bool
+}
+
 // NotificationType represents the type of a notification message.
 type NotificationType int
 
@@ -37,6 +47,8 @@ const (
 	NTRawBlockDisconnected
 	NTNewTransaction
 	NTRequestStatus
+	// NTContractInstance indicates the state of a traced contract instance changed.
+	NTContractInstance
 )
 
 // notificationTypeStrings is a map of notification types back to their constant
@@ -46,6 +58,7 @@ var notificationTypeStrings = map[NotificationType]string{
 	NTRawBlockDisconnected: "raw_blocks_disconnected",
 	NTNewTransaction:       "new_transaction",
 	NTRequestStatus:        "request_status",
+	NTContractInstance:     "contract_instance",
 }
 
 // String returns the NotificationType in human-readable form.
@@ -90,6 +103,7 @@ type WSNotificationManager struct {
 	chain                *protocol.Chain
 	eventDispatcher      *event.Dispatcher
 	txMsgSub             *event.Subscription
+	instanceSub          *event.Subscription
 }
 
 // NewWsNotificationManager returns a new notification manager ready for use. See WSNotificationManager for more details.
@@ -250,12 +264,44 @@ out:
 	m.wg.Done()
 }
 
+// instanceEventLoop constantly pass the state changes of the traced contract
+// instances to the notification manager for instance notification processing.
+func (m *WSNotificationManager) instanceEventLoop() {
+out:
+	for {
+		select {
+		case obj, ok := <-m.instanceSub.Chan():
+			if !ok {
+				log.WithFields(log.Fields{"module": logModule}).Warning("contract instance subscription channel closed")
+				break out
+			}
+
+			ev, ok := obj.Data.(contract.InstanceEvent)
+			if !ok {
+				log.WithFields(log.Fields{"module": logModule}).Error("event type error")
+				continue
+			}
+
+			select {
+			case m.queueNotification <- (*notificationContractInstance)(&ev):
+			case <-m.quit:
+				break out
+			}
+		case <-m.quit:
+			break out
+		}
+	}
+
+	m.wg.Done()
+}
+
 // notificationHandler reads notifications and control messages from the queue handler and processes one at a time.
 func (m *WSNotificationManager) notificationHandler() {
 	// clients is a map of all currently connected websocket clients.
 	clients := make(map[chan struct{}]*WSClient)
 	blockNotifications := make(map[chan struct{}]*WSClient)
 	txNotifications := make(map[chan struct{}]*WSClient)
+	instanceNotifications := make(map[string]map[chan struct{}]*WSClient)
 
 out:
 	for {
@@ -283,6 +329,25 @@ out:
 					m.notifyForNewTx(txNotifications, txDesc)
 				}
 
+			case *notificationContractInstance:
+				ev := (*contract.InstanceEvent)(n)
+				if clients, ok := instanceNotifications[ev.TraceID]; ok {
+					m.notifyContractInstance(clients, ev)
+				}
+
+			case *notificationContractInstanceUpdates:
+				if n.register {
+					if _, ok := instanceNotifications[n.traceID]; !ok {
+						instanceNotifications[n.traceID] = make(map[chan struct{}]*WSClient)
+					}
+					instanceNotifications[n.traceID][n.wsc.quit] = n.wsc
+				} else if clients, ok := instanceNotifications[n.traceID]; ok {
+					delete(clients, n.wsc.quit)
+					if len(clients) == 0 {
+						delete(instanceNotifications, n.traceID)
+					}
+				}
+
 			case *notificationRegisterBlocks:
 				wsc := (*WSClient)(n)
 				blockNotifications[wsc.quit] = wsc
@@ -307,6 +372,12 @@ out:
 				wsc := (*WSClient)(n)
 				delete(blockNotifications, wsc.quit)
 				delete(txNotifications, wsc.quit)
+				for traceID, instanceClients := range instanceNotifications {
+					delete(instanceClients, wsc.quit)
+					if len(instanceClients) == 0 {
+						delete(instanceNotifications, traceID)
+					}
+				}
 				delete(clients, wsc.quit)
 
 			default:
@@ -406,6 +477,33 @@ func (m *WSNotificationManager) notifyForNewTx(clients map[chan struct{}]*WSClie
 	}
 }
 
+// RegisterContractInstanceUpdates requests notifications to the passed websocket
+// client when the state of the contract instance with the trace id changes.
+func (m *WSNotificationManager) RegisterContractInstanceUpdates(wsc *WSClient, traceID string) {
+	m.queueNotification <- &notificationContractInstanceUpdates{wsc: wsc, traceID: traceID, register: true}
+}
+
+// UnregisterContractInstanceUpdates removes the contract instance notifications
+// of the trace id for the passed websocket client.
+func (m *WSNotificationManager) UnregisterContractInstanceUpdates(wsc *WSClient, traceID string) {
+	m.queueNotification <- &notificationContractInstanceUpdates{wsc: wsc, traceID: traceID}
+}
+
+// notifyContractInstance notifies websocket clients that have registered for
+// the contract instance when its state changes.
+func (m *WSNotificationManager) notifyContractInstance(clients map[chan struct{}]*WSClient, ev *contract.InstanceEvent) {
+	resp := NewWSResponse(NTContractInstance.String(), ev, nil)
+	marshalledJSON, err := json.Marshal(resp)
+	if err != nil {
+		log.WithFields(log.Fields{"module": logModule, "error": err}).Error("Failed to marshal contract instance notification")
+		return
+	}
+
+	for _, wsc := range clients {
+		wsc.QueueNotification(marshalledJSON)
+	}
+}
+
 // AddClient adds the passed websocket client to the notification manager.
 func (m *WSNotificationManager) AddClient(wsc *WSClient) {
 	m.queueNotification <- (*notificationRegisterClient)(wsc)
@@ -474,11 +572,17 @@ func (m *WSNotificationManager) Start() error {
 		return err
 	}
 
-	m.wg.Add(4)
+	m.instanceSub, err = m.eventDispatcher.Subscribe(contract.InstanceEvent{})
+	if err != nil {
+		return err
+	}
+
+	m.wg.Add(5)
 	go m.blockNotify()
 	go m.queueHandler()
 	go m.notificationHandler()
 	go m.memPoolTxQueryLoop()
+	go m.instanceEventLoop()
 	return nil
 }
 
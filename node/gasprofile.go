@@ -0,0 +1,79 @@
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/bytom/bytom/protocol"
+)
+
+const (
+	// a block failing to profile is retried before it is skipped
+	gasProfileRetries    = 3
+	gasProfileRetryDelay = time.Second
+)
+
+// dumpGasProfiles writes the gas profile of every block connected from now on
+// to the directory, one json file named by the height and the hash of the
+// block, so the blocks of a fork don't overwrite each other. It returns when
+// the quit channel is closed
+func dumpGasProfiles(chain *protocol.Chain, dir string, quit <-chan struct{}) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		log.WithFields(log.Fields{"module": logModule, "err": err, "dir": dir}).Error("fail on create gas profile dir")
+		return
+	}
+
+	for height := chain.BestBlockHeight() + 1; ; height++ {
+		select {
+		case <-chain.BlockWaiter(height):
+		case <-quit:
+			return
+		}
+
+		for retry := 0; ; retry++ {
+			err := dumpGasProfile(chain, dir, height)
+			if err == nil {
+				break
+			}
+
+			if retry == gasProfileRetries {
+				log.WithFields(log.Fields{"module": logModule, "err": err, "height": height}).Error("skip gas profile of the block")
+				break
+			}
+
+			log.WithFields(log.Fields{"module": logModule, "err": err, "height": height}).Warning("fail on dump gas profile, retry later")
+			select {
+			case <-time.After(gasProfileRetryDelay):
+			case <-quit:
+				return
+			}
+		}
+	}
+}
+
+// dumpGasProfile writes the gas profile of the main chain block at the height
+func dumpGasProfile(chain *protocol.Chain, dir string, height uint64) error {
+	block, err := chain.GetBlockByHeight(height)
+	if err != nil {
+		return err
+	}
+
+	profile, err := chain.ProfileBlock(block)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(profile, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	blockHash := block.Hash()
+	return ioutil.WriteFile(filepath.Join(dir, fmt.Sprintf("%d-%s.json", height, blockHash.String())), data, 0600)
+}
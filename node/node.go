@@ -6,6 +6,7 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"path/filepath"
+	"strings"
 
 	log "github.com/sirupsen/logrus"
 	cmn "github.com/tendermint/tmlibs/common"
@@ -19,9 +20,11 @@ import (
 	"github.com/bytom/bytom/api"
 	"github.com/bytom/bytom/asset"
 	"github.com/bytom/bytom/blockchain/pseudohsm"
+	"github.com/bytom/bytom/blockchain/signer"
 	cfg "github.com/bytom/bytom/config"
 	"github.com/bytom/bytom/consensus"
 	"github.com/bytom/bytom/contract"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
 	"github.com/bytom/bytom/database"
 	dbm "github.com/bytom/bytom/database/leveldb"
 	"github.com/bytom/bytom/env"
@@ -52,8 +55,10 @@ type Node struct {
 	api             *api.API
 	chain           *protocol.Chain
 	traceService    *contract.TraceService
+	bcrpIndex       *contract.BCRPIndex
 	blockProposer   *blockproposer.BlockProposer
 	miningEnable    bool
+	quit            chan struct{}
 }
 
 // NewNode create bytom node
@@ -72,6 +77,15 @@ func NewNode(config *cfg.Config) *Node {
 	tokenDB := dbm.NewDB("accesstoken", config.DBBackend, config.DBDir())
 	accessTokens := accesstoken.NewStore(tokenDB)
 
+	remoteSigner, err := initRemoteSigner(config)
+	if err != nil {
+		cmn.Exit(cmn.Fmt("Failed to init remote signer: %v", err))
+	}
+
+	if err := initFrostSigner(config); err != nil {
+		cmn.Exit(cmn.Fmt("Failed to init threshold signer: %v", err))
+	}
+
 	dispatcher := event.NewDispatcher()
 	txPool := protocol.NewTxPool(store, dispatcher)
 
@@ -80,7 +94,12 @@ func NewNode(config *cfg.Config) *Node {
 		cmn.Exit(cmn.Fmt("Failed to create chain structure: %v", err))
 	}
 
-	traceService := startTraceUpdater(chain, config)
+	traceService := startTraceUpdater(chain, config, dispatcher)
+	bcrpIndex := startBCRPIndex(chain, config)
+	quit := make(chan struct{})
+	if dir := config.GasProfileDir(); dir != "" {
+		go dumpGasProfiles(chain, dir, quit)
+	}
 
 	var accounts *account.Manager
 	var assets *asset.Registry
@@ -100,6 +119,16 @@ func NewNode(config *cfg.Config) *Node {
 		if err != nil {
 			log.WithFields(log.Fields{"module": logModule, "error": err}).Error("init NewWallet")
 		}
+	}
+
+	if wallet != nil {
+		if remoteSigner != nil {
+			wallet.Signer = remoteSigner
+		}
+
+		if wallet.PathSigner, err = initPathSigner(config, wallet.Signer); err != nil {
+			cmn.Exit(cmn.Fmt("Failed to init path signer: %v", err))
+		}
 
 		// trigger rescan wallet
 		if config.Wallet.Rescan {
@@ -135,8 +164,10 @@ func NewNode(config *cfg.Config) *Node {
 		wallet:          wallet,
 		chain:           chain,
 		traceService:    traceService,
+		bcrpIndex:       bcrpIndex,
 		miningEnable:    config.Mining,
 		notificationMgr: notificationMgr,
+		quit:            quit,
 	}
 
 	node.BaseService = *cmn.NewBaseService(nil, "Node", node)
@@ -144,15 +175,53 @@ func NewNode(config *cfg.Config) *Node {
 	return node
 }
 
-func startTraceUpdater(chain *protocol.Chain, cfg *cfg.Config) *contract.TraceService {
+func startTraceUpdater(chain *protocol.Chain, cfg *cfg.Config, dispatcher *event.Dispatcher) *contract.TraceService {
 	db := dbm.NewDB("trace", cfg.DBBackend, cfg.DBDir())
 	store := contract.NewTraceStore(db)
-	tracerService := contract.NewTraceService(contract.NewInfrastructure(chain, store))
+	tracerService := contract.NewTraceService(contract.NewInfrastructure(chain, store, dispatcher))
 	traceUpdater := contract.NewTraceUpdater(tracerService, chain)
 	go traceUpdater.Sync()
+
+	txMsgSub, err := dispatcher.Subscribe(protocol.TxMsgEvent{})
+	if err != nil {
+		cmn.Exit(cmn.Fmt("Failed to subscribe tx pool for trace service: %v", err))
+	}
+
+	go traceUnconfirmedTxs(tracerService, txMsgSub)
 	return tracerService
 }
 
+// traceUnconfirmedTxs passes the transactions accepted and removed by mempool to
+// the trace service, so the subscribers of an instance hear about it before
+// confirmation
+func traceUnconfirmedTxs(traceService *contract.TraceService, txMsgSub *event.Subscription) {
+	for obj := range txMsgSub.Chan() {
+		ev, ok := obj.Data.(protocol.TxMsgEvent)
+		if !ok {
+			log.WithFields(log.Fields{"module": logModule}).Error("event type error")
+			continue
+		}
+
+		switch ev.TxMsg.MsgType {
+		case protocol.MsgNewTx:
+			traceService.AddUnconfirmedTx(ev.TxMsg.TxDesc.Tx)
+		case protocol.MsgRemoveTx:
+			traceService.RemoveUnconfirmedTx(ev.TxMsg.TxDesc.Tx)
+		}
+	}
+}
+
+func startBCRPIndex(chain *protocol.Chain, cfg *cfg.Config) *contract.BCRPIndex {
+	db := dbm.NewDB("bcrp", cfg.DBBackend, cfg.DBDir())
+	bcrpIndex, err := contract.NewBCRPIndex(db, chain)
+	if err != nil {
+		cmn.Exit(cmn.Fmt("Failed to create bcrp contract index: %v", err))
+	}
+
+	go bcrpIndex.Sync()
+	return bcrpIndex
+}
+
 func initNodeConfig(config *cfg.Config) error {
 	if err := lockDataDirectory(config); err != nil {
 		cmn.Exit("Error: " + err.Error())
@@ -188,6 +257,68 @@ func initCommonConfig(config *cfg.Config) {
 	cfg.CommonConfig = config
 }
 
+// initRemoteSigner connects the remote signing daemon, and makes it sign the
+// proposed blocks when the validator key is configured
+func initRemoteSigner(config *cfg.Config) (*signer.RemoteSigner, error) {
+	if config.Signer.URL == "" {
+		return nil, nil
+	}
+
+	client, err := signer.NewTLSClient(config.Signer.TLSCert, config.Signer.TLSKey, config.Signer.TLSCA)
+	if err != nil {
+		return nil, err
+	}
+
+	remoteSigner := signer.NewRemoteSigner(config.Signer.URL, config.Signer.Token, client)
+	if config.Signer.ValidatorXPub == "" {
+		return remoteSigner, nil
+	}
+
+	var xpub chainkd.XPub
+	if err := xpub.UnmarshalText([]byte(config.Signer.ValidatorXPub)); err != nil {
+		return nil, err
+	}
+
+	config.SetValidatorSigner(xpub, func(msg []byte, req *cfg.ValidatorSignRequest) ([]byte, error) {
+		sig, err := remoteSigner.ValidatorSign(xpub, req)
+		if err != nil {
+			return nil, err
+		}
+
+		if !xpub.Verify(msg, sig) {
+			return nil, errors.New("validator signature of the remote signer does not match the message")
+		}
+		return sig, nil
+	})
+	return remoteSigner, nil
+}
+
+// initFrostSigner makes the threshold key share holders sign the proposed
+// blocks when the threshold validator key is configured
+func initFrostSigner(config *cfg.Config) error {
+	if config.Signer.FrostGroup == "" {
+		return nil
+	}
+
+	if config.Signer.ValidatorXPub != "" {
+		return errors.New("signer.frost_group conflicts with signer.validator_xpub")
+	}
+
+	group, err := signer.LoadFrostGroup(config.Signer.FrostGroup)
+	if err != nil {
+		return err
+	}
+
+	client, err := signer.NewTLSClient(config.Signer.TLSCert, config.Signer.TLSKey, config.Signer.TLSCA)
+	if err != nil {
+		return err
+	}
+
+	coordinator := signer.NewFrostCoordinator(group, strings.Split(config.Signer.FrostHolders, ","), config.Signer.Token, client)
+	config.SetValidatorSigner(group.XPub, coordinator.Sign)
+	return nil
+}
+
 // Lanch web broser or not
 func launchWebBrowser(port string) {
 	webAddress := webHost + ":" + port
@@ -199,7 +330,7 @@ func launchWebBrowser(port string) {
 }
 
 func (n *Node) initAndstartAPIServer() {
-	n.api = api.NewAPI(n.syncManager, n.wallet, n.blockProposer, n.chain, n.traceService, n.config, n.accessTokens, n.eventDispatcher, n.notificationMgr)
+	n.api = api.NewAPI(n.syncManager, n.wallet, n.blockProposer, n.chain, n.traceService, n.bcrpIndex, n.config, n.accessTokens, n.eventDispatcher, n.notificationMgr)
 
 	listenAddr := env.String("LISTEN", n.config.ApiAddress)
 	env.Parse()
@@ -238,6 +369,7 @@ func (n *Node) OnStart() error {
 }
 
 func (n *Node) OnStop() {
+	close(n.quit)
 	n.notificationMgr.Shutdown()
 	n.notificationMgr.WaitForShutdown()
 	n.BaseService.OnStop()
@@ -256,3 +388,17 @@ func (n *Node) RunForever() {
 		n.Stop()
 	})
 }
+
+// initPathSigner applies the path policy to the signer used for arbitrary
+// derivation paths, every key and path is denied without a policy and the
+// policy can't reach the key paths of the wallet
+func initPathSigner(config *cfg.Config, walletSigner signer.Signer) (signer.Signer, error) {
+	policy := &signer.PolicyConfig{}
+	if config.Signer.PathPolicy != "" {
+		var err error
+		if policy, err = signer.LoadPolicyConfig(config.Signer.PathPolicy); err != nil {
+			return nil, err
+		}
+	}
+	return signer.NewPathPolicySigner(walletSigner, policy)
+}
@@ -41,7 +41,7 @@ type BlockProposer struct {
 //
 // It must be run as a goroutine.
 func (b *BlockProposer) generateBlocks() {
-	xpub := config.CommonConfig.PrivateKey().XPub()
+	xpub := config.CommonConfig.ValidatorXPub()
 	xpubStr := hex.EncodeToString(xpub[:])
 	ticker := time.NewTicker(time.Duration(consensus.ActiveNetParams.BlockTimeInterval) * time.Millisecond / 4)
 	defer ticker.Stop()
@@ -91,7 +91,9 @@ func (b *blockBuilder) build() (*types.Block, error) {
 	}
 
 	blockHeader := &b.block.BlockHeader
-	b.chain.SignBlockHeader(blockHeader)
+	if err := b.chain.SignBlockHeader(blockHeader); err != nil {
+		return nil, err
+	}
 	return b.block, nil
 }
 
@@ -235,7 +237,7 @@ type validateTxResult struct {
 
 func (b *blockBuilder) preValidateTxs(txs []*protocol.TxDesc, chain *protocol.Chain, view *state.UtxoViewpoint, gasLeft int64) ([]*validateTxResult, int64) {
 	var results []*validateTxResult
-	bcBlock := &bc.Block{BlockHeader: &bc.BlockHeader{Height: chain.BestBlockHeight() + 1}}
+	bcBlock := &bc.Block{BlockHeader: &bc.BlockHeader{Height: chain.BestBlockHeight() + 1, Timestamp: b.block.Timestamp}}
 	bcTxs := make([]*bc.Tx, len(txs))
 	for i, tx := range txs {
 		bcTxs[i] = tx.Tx.Tx
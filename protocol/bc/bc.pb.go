@@ -317,6 +317,7 @@ type TxHeader struct {
 	SerializedSize uint64  `protobuf:"varint,2,opt,name=serialized_size,json=serializedSize" json:"serialized_size,omitempty"`
 	TimeRange      uint64  `protobuf:"varint,3,opt,name=time_range,json=timeRange" json:"time_range,omitempty"`
 	ResultIds      []*Hash `protobuf:"bytes,4,rep,name=result_ids,json=resultIds" json:"result_ids,omitempty"`
+	LockTime       uint64  `protobuf:"varint,5,opt,name=lock_time,json=lockTime" json:"lock_time,omitempty"`
 }
 
 func (m *TxHeader) Reset()                    { *m = TxHeader{} }
@@ -352,6 +353,13 @@ func (m *TxHeader) GetResultIds() []*Hash {
 	return nil
 }
 
+func (m *TxHeader) GetLockTime() uint64 {
+	if m != nil {
+		return m.LockTime
+	}
+	return 0
+}
+
 type Mux struct {
 	Sources             []*ValueSource      `protobuf:"bytes,1,rep,name=sources" json:"sources,omitempty"`
 	Program             *Program            `protobuf:"bytes,2,opt,name=program" json:"program,omitempty"`
@@ -629,6 +637,7 @@ type Spend struct {
 	WitnessDestination *ValueDestination `protobuf:"bytes,2,opt,name=witness_destination,json=witnessDestination" json:"witness_destination,omitempty"`
 	WitnessArguments   [][]byte          `protobuf:"bytes,3,rep,name=witness_arguments,json=witnessArguments,proto3" json:"witness_arguments,omitempty"`
 	Ordinal            uint64            `protobuf:"varint,4,opt,name=ordinal" json:"ordinal,omitempty"`
+	Sequence           uint64            `protobuf:"varint,5,opt,name=sequence" json:"sequence,omitempty"`
 }
 
 func (m *Spend) Reset()                    { *m = Spend{} }
@@ -664,6 +673,13 @@ func (m *Spend) GetOrdinal() uint64 {
 	return 0
 }
 
+func (m *Spend) GetSequence() uint64 {
+	if m != nil {
+		return m.Sequence
+	}
+	return 0
+}
+
 func init() {
 	proto.RegisterType((*Hash)(nil), "bc.Hash")
 	proto.RegisterType((*Program)(nil), "bc.Program")
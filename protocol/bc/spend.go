@@ -10,6 +10,9 @@ import "io"
 func (Spend) typ() string { return "spend1" }
 func (s *Spend) writeForHash(w io.Writer) {
 	mustWriteForHash(w, s.SpentOutputId)
+	if s.Sequence != 0 {
+		mustWriteForHash(w, s.Sequence)
+	}
 }
 
 // SetDestination will link the spend to the output
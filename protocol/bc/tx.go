@@ -32,6 +32,18 @@ func (tx *Tx) SigHash(n uint32) (hash Hash) {
 	return hash
 }
 
+// Sequences returns the relative lock of the spends keyed by the spent output
+// IDs, the spends without relative lock are left out
+func (tx *Tx) Sequences() map[Hash]uint64 {
+	sequences := make(map[Hash]uint64)
+	for _, id := range tx.InputIDs {
+		if sp, ok := tx.Entries[id].(*Spend); ok && sp.Sequence != 0 {
+			sequences[*sp.SpentOutputId] = sp.Sequence
+		}
+	}
+	return sequences
+}
+
 // OriginalOutput try to get the output entry by given hash
 func (tx *Tx) OriginalOutput(id Hash) (*OriginalOutput, error) {
 	e, ok := tx.Entries[id]
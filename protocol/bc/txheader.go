@@ -12,6 +12,11 @@ func (h *TxHeader) writeForHash(w io.Writer) {
 	mustWriteForHash(w, h.Version)
 	mustWriteForHash(w, h.TimeRange)
 	mustWriteForHash(w, h.ResultIds)
+	// the lock time is only committed when it's set, so the IDs of the
+	// transactions without lock time stay the same
+	if h.LockTime != 0 {
+		mustWriteForHash(w, h.LockTime)
+	}
 }
 
 // NewTxHeader creates an new TxHeader.
@@ -47,6 +47,7 @@ func (mh *mapHelper) addEntry(e bc.Entry) bc.Hash {
 
 func (mh *mapHelper) generateTx() *bc.Tx {
 	header := bc.NewTxHeader(mh.txData.Version, mh.txData.SerializedSize, mh.txData.TimeRange, mh.resultIDs)
+	header.LockTime = mh.txData.LockTime
 	return &bc.Tx{
 		TxHeader:       header,
 		ID:             mh.addEntry(header),
@@ -93,7 +94,7 @@ func (mh *mapHelper) mapIssuanceInput(i int, input *IssuanceInput) {
 	}
 }
 
-func (mh *mapHelper) mapSpendInput(i int, input *SpendInput) {
+func (mh *mapHelper) mapSpendInput(i int, input *SpendInput, sequence uint64) {
 	// create entry for prevout
 	prog := &bc.Program{VmVersion: input.VMVersion, Code: input.ControlProgram}
 	src := &bc.ValueSource{
@@ -108,6 +109,7 @@ func (mh *mapHelper) mapSpendInput(i int, input *SpendInput) {
 	// create entry for spend
 	spend := bc.NewSpend(&prevoutID, uint64(i))
 	spend.WitnessArguments = input.Arguments
+	spend.Sequence = sequence
 	mh.spends = append(mh.spends, spend)
 	mh.inputIDs[i] = mh.addEntry(spend)
 	mh.spentOutputIDs = append(mh.spentOutputIDs, prevoutID)
@@ -145,7 +147,7 @@ func (mh *mapHelper) mapInputs() {
 		case *IssuanceInput:
 			mh.mapIssuanceInput(i, typedInput)
 		case *SpendInput:
-			mh.mapSpendInput(i, typedInput)
+			mh.mapSpendInput(i, typedInput, input.Sequence)
 		case *VetoInput:
 			mh.mapVetoInput(i, typedInput)
 		case *CoinbaseInput:
@@ -7,6 +7,7 @@ import (
 	"github.com/bytom/bytom/encoding/blockchain"
 	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/vm"
 )
 
 // OutputCommitment contains the commitment data for a transaction output.
@@ -44,7 +45,7 @@ func (oc *OutputCommitment) readFrom(r *blockchain.Reader, assetVersion uint64)
 		if err != nil {
 			return errors.Wrap(err, "reading VM version")
 		}
-		if oc.VMVersion != 1 {
+		if !vm.IsSupportedVersion(oc.VMVersion) {
 			return fmt.Errorf("unrecognized VM version %d for asset version 1", oc.VMVersion)
 		}
 		oc.ControlProgram, err = blockchain.ReadVarstr31(r)
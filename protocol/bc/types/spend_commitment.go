@@ -7,6 +7,7 @@ import (
 	"github.com/bytom/bytom/encoding/blockchain"
 	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/vm"
 )
 
 // SpendCommitment contains the commitment data for a transaction output.
@@ -68,7 +69,7 @@ func (sc *SpendCommitment) readFrom(r *blockchain.Reader, assetVersion uint64) (
 			if sc.VMVersion, err = blockchain.ReadVarint63(r); err != nil {
 				return errors.Wrap(err, "reading VM version")
 			}
-			if sc.VMVersion != 1 {
+			if !vm.IsSupportedVersion(sc.VMVersion) {
 				return fmt.Errorf("unrecognized VM version %d for asset version 1", sc.VMVersion)
 			}
 			if sc.ControlProgram, err = blockchain.ReadVarstr31(r); err != nil {
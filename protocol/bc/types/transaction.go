@@ -12,7 +12,10 @@ import (
 	"github.com/bytom/bytom/protocol/bc"
 )
 
-const serRequired = 0x7 // Bit mask accepted serialization flag.
+const (
+	serRequired = 0x7 // Bit mask accepted serialization flag.
+	serLockTime = 0x8 // Bit mask of the serialized lock time and input sequences.
+)
 
 // Tx holds a transaction along with its hash.
 type Tx struct {
@@ -64,6 +67,7 @@ type TxData struct {
 	Version        uint64
 	SerializedSize uint64
 	TimeRange      uint64
+	LockTime       uint64
 	Inputs         []*TxInput
 	Outputs        []*TxOutput
 }
@@ -119,6 +123,30 @@ func (tx *TxData) Fee() uint64 {
 	return 0
 }
 
+// hasLockTime returns whether the transaction has lock time or any input
+// sequence, which are only serialized when present
+func (tx *TxData) hasLockTime() bool {
+	if tx.LockTime != 0 {
+		return true
+	}
+
+	for _, input := range tx.Inputs {
+		if input.Sequence != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func (tx *TxData) checkSequences() error {
+	for i, input := range tx.Inputs {
+		if input.Sequence != 0 && input.InputType() != SpendInputType {
+			return fmt.Errorf("sequence of input %d with type %d is not allowed", i, input.InputType())
+		}
+	}
+	return nil
+}
+
 func (tx *TxData) readFrom(r *blockchain.Reader) (err error) {
 	startSerializedSize := r.Len()
 	var serflags [1]byte
@@ -126,7 +154,7 @@ func (tx *TxData) readFrom(r *blockchain.Reader) (err error) {
 		return errors.Wrap(err, "reading serialization flags")
 	}
 
-	if serflags[0] != serRequired {
+	if serflags[0] != serRequired && serflags[0] != serRequired|serLockTime {
 		return fmt.Errorf("unsupported serflags %#x", serflags[0])
 	}
 
@@ -138,6 +166,12 @@ func (tx *TxData) readFrom(r *blockchain.Reader) (err error) {
 		return err
 	}
 
+	if serflags[0]&serLockTime != 0 {
+		if tx.LockTime, err = blockchain.ReadVarint63(r); err != nil {
+			return errors.Wrap(err, "reading transaction lock time")
+		}
+	}
+
 	n, err := blockchain.ReadVarint31(r)
 	if err != nil {
 		return errors.Wrap(err, "reading number of transaction inputs")
@@ -166,14 +200,35 @@ func (tx *TxData) readFrom(r *blockchain.Reader) (err error) {
 		tx.Outputs = append(tx.Outputs, to)
 	}
 
+	if serflags[0]&serLockTime != 0 {
+		for i, ti := range tx.Inputs {
+			if ti.Sequence, err = blockchain.ReadVarint63(r); err != nil {
+				return errors.Wrapf(err, "reading sequence of input %d", i)
+			}
+		}
+
+		if !tx.hasLockTime() {
+			return fmt.Errorf("serflags %#x without lock time", serflags[0])
+		}
+
+		if err = tx.checkSequences(); err != nil {
+			return err
+		}
+	}
+
 	tx.SerializedSize = uint64(startSerializedSize - r.Len())
 	return nil
 }
 
 // WriteTo writes tx to w.
 func (tx *TxData) WriteTo(w io.Writer) (int64, error) {
+	var serflags byte = serRequired
+	if tx.hasLockTime() {
+		serflags |= serLockTime
+	}
+
 	ew := errors.NewWriter(w)
-	if err := tx.writeTo(ew, serRequired); err != nil {
+	if err := tx.writeTo(ew, serflags); err != nil {
 		return 0, err
 	}
 
@@ -193,6 +248,16 @@ func (tx *TxData) writeTo(w io.Writer, serflags byte) error {
 		return errors.Wrap(err, "writing transaction maxtime")
 	}
 
+	if serflags&serLockTime != 0 {
+		if err := tx.checkSequences(); err != nil {
+			return err
+		}
+
+		if _, err := blockchain.WriteVarint63(w, tx.LockTime); err != nil {
+			return errors.Wrap(err, "writing transaction lock time")
+		}
+	}
+
 	if _, err := blockchain.WriteVarint31(w, uint64(len(tx.Inputs))); err != nil {
 		return errors.Wrap(err, "writing tx input count")
 	}
@@ -212,5 +277,13 @@ func (tx *TxData) writeTo(w io.Writer, serflags byte) error {
 			return errors.Wrapf(err, "writing tx output %d", i)
 		}
 	}
+
+	if serflags&serLockTime != 0 {
+		for i, ti := range tx.Inputs {
+			if _, err := blockchain.WriteVarint63(w, ti.Sequence); err != nil {
+				return errors.Wrapf(err, "writing sequence of input %d", i)
+			}
+		}
+	}
 	return nil
 }
@@ -209,6 +209,71 @@ func TestTransactionTrailingGarbage(t *testing.T) {
 	}
 }
 
+func TestTransactionLockTime(t *testing.T) {
+	newTxData := func(lockTime, sequence uint64) TxData {
+		spend := NewSpendInput([][]byte{[]byte("arguments")}, testutil.MustDecodeHash("fad5195a0c8e3b590b86a3c0a95e7529565888508aecca96e9aeda633002f409"), *consensus.BTMAssetID, 254354, 3, []byte("spendProgram"), nil)
+		spend.Sequence = sequence
+		return TxData{
+			Version:  1,
+			LockTime: lockTime,
+			Inputs:   []*TxInput{spend},
+			Outputs:  []*TxOutput{NewOriginalTxOutput(*consensus.BTMAssetID, 254354, []byte("true"), nil)},
+		}
+	}
+
+	unlocked := NewTx(newTxData(0, 0))
+	if got := testutil.Serialize(t, unlocked); got[0] != serRequired {
+		t.Errorf("got serflags %#x, want %#x", got[0], serRequired)
+	}
+
+	cases := []struct {
+		lockTime uint64
+		sequence uint64
+	}{
+		{lockTime: 100},
+		{sequence: 10},
+		{lockTime: consensus.LockTimeThreshold, sequence: 10},
+	}
+	for i, c := range cases {
+		tx := NewTx(newTxData(c.lockTime, c.sequence))
+		b := testutil.Serialize(t, tx)
+		if b[0] != serRequired|serLockTime {
+			t.Errorf("case %d: got serflags %#x, want %#x", i, b[0], serRequired|serLockTime)
+		}
+
+		if tx.ID == unlocked.ID {
+			t.Errorf("case %d: the lock doesn't change the tx id", i)
+		}
+
+		if tx.LockTime != c.lockTime || tx.Sequences()[tx.SpentOutputIDs[0]] != c.sequence {
+			t.Errorf("case %d: got lock time %d and sequences %v", i, tx.LockTime, tx.Sequences())
+		}
+
+		tx1 := new(TxData)
+		if err := tx1.UnmarshalText([]byte(hex.EncodeToString(b))); err != nil {
+			t.Fatalf("case %d: unexpected err %v", i, err)
+		}
+
+		tx.SerializedSize = uint64(len(b))
+		if !testutil.DeepEqual(*tx1, tx.TxData) {
+			t.Errorf("case %d: tx1 is:\n%swant:\n%s", i, spew.Sdump(*tx1), spew.Sdump(tx.TxData))
+		}
+	}
+
+	emptyLock := hex.EncodeToString(testutil.Serialize(t, unlocked))
+	emptyLock = "0f01" + "00" + "00" + emptyLock[6:] + "00"
+	if err := new(TxData).UnmarshalText([]byte(emptyLock)); err == nil {
+		t.Error("expected error with serflags of an empty lock but got nil")
+	}
+
+	issuance := NewIssuanceInput([]byte("nonce"), 254354, []byte("issuanceProgram"), nil, nil)
+	issuance.Sequence = 10
+	txData := &TxData{Version: 1, Inputs: []*TxInput{issuance}}
+	if _, err := txData.WriteTo(ioutil.Discard); err == nil {
+		t.Error("expected error with the sequence of an issuance but got nil")
+	}
+}
+
 func TestInvalidIssuance(t *testing.T) {
 	hex := strings.Join([]string{
 		"07",     // serflags
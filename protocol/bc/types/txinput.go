@@ -45,6 +45,10 @@ type (
 		TypedInput
 		CommitmentSuffix []byte
 		WitnessSuffix    []byte
+
+		// Sequence is the relative lock of a spend input, the spent output
+		// must be confirmed by at least this number of blocks
+		Sequence uint64
 	}
 
 	// TypedInput return the txinput type.
@@ -177,6 +177,11 @@ func (c *Chain) reorganizeChain(blockHeader *types.BlockHeader) error {
 		log.WithFields(log.Fields{"module": logModule, "num": len(txsToRestore)}).Debug("restore txs back to pool")
 	}
 
+	if len(detachNodes) > 0 {
+		c.relockPoolTxs(blockHeader)
+	}
+	c.releaseLockedTxs(blockHeader)
+
 	return nil
 }
 
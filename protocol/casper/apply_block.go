@@ -131,8 +131,8 @@ func (c *Casper) myVerification(target *state.Checkpoint) *verification {
 		return nil
 	}
 
-	prvKey := config.CommonConfig.PrivateKey()
-	v, err := convertVerification(source, target, &ValidCasperSignMsg{PubKey: prvKey.XPub().String()})
+	xpub := config.CommonConfig.ValidatorXPub()
+	v, err := convertVerification(source, target, &ValidCasperSignMsg{PubKey: xpub.String()})
 	if err != nil {
 		return nil
 	}
@@ -142,7 +142,8 @@ func (c *Casper) myVerification(target *state.Checkpoint) *verification {
 		return nil
 	}
 
-	if err := v.Sign(*prvKey); err != nil {
+	req := &config.ValidatorSignRequest{Verification: &config.Verification{SourceHash: v.SourceHash, TargetHash: v.TargetHash, SourceHeight: v.SourceHeight, TargetHeight: v.TargetHeight}}
+	if err := v.signBy(func(msg []byte) ([]byte, error) { return config.CommonConfig.ValidatorSign(msg, req) }); err != nil {
 		log.WithField("module", logModule).Error("myVerification fail on sign msg")
 		return nil
 	}
@@ -73,13 +73,20 @@ func supLinkToVerifications(source, target *state.Checkpoint, supLink *types.Sup
 
 // Sign used to sign the verification by specified xPrv
 func (v *verification) Sign(xPrv chainkd.XPrv) error {
+	return v.signBy(func(msg []byte) ([]byte, error) {
+		return xPrv.Sign(msg), nil
+	})
+}
+
+// signBy signs the verification by the signFn holding the validator key
+func (v *verification) signBy(signFn func(msg []byte) ([]byte, error)) error {
 	message, err := v.encodeMessage()
 	if err != nil {
 		return err
 	}
 
-	v.Signature = xPrv.Sign(message)
-	return nil
+	v.Signature, err = signFn(message)
+	return err
 }
 
 func (v *verification) toValidCasperSignMsg() ValidCasperSignMsg {
@@ -127,12 +134,18 @@ func (v *verification) verifySignature() error {
 
 // encodeMessage encode the verification for the validators to sign or verify
 func (v *verification) encodeMessage() ([]byte, error) {
+	return VerificationMessage(v.SourceHash, v.TargetHash)
+}
+
+// VerificationMessage is the message signed by the validators voting from the
+// source checkpoint to the target checkpoint
+func VerificationMessage(sourceHash, targetHash bc.Hash) ([]byte, error) {
 	buff := new(bytes.Buffer)
-	if _, err := v.SourceHash.WriteTo(buff); err != nil {
+	if _, err := sourceHash.WriteTo(buff); err != nil {
 		return nil, err
 	}
 
-	if _, err := v.TargetHash.WriteTo(buff); err != nil {
+	if _, err := targetHash.WriteTo(buff); err != nil {
 		return nil, err
 	}
 
@@ -0,0 +1,160 @@
+package protocol
+
+import (
+	"bytes"
+	"sort"
+
+	chainjson "github.com/bytom/bytom/encoding/json"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/protocol/validation"
+	"github.com/bytom/bytom/protocol/vm"
+)
+
+// maxProfilePrograms is the number of the most expensive programs listed by
+// a block profile
+const maxProfilePrograms = 20
+
+// OpGas is the gas charged by an opcode over all its executions
+type OpGas struct {
+	Op    string `json:"op"`
+	Count uint64 `json:"count"`
+	Gas   int64  `json:"gas"`
+}
+
+// ProgramGas is the VM gas used by the programs unlocking the outputs of a
+// control program, the contracts are told apart by it
+type ProgramGas struct {
+	ControlProgram chainjson.HexBytes `json:"control_program"`
+	Runs           uint64             `json:"runs"`
+	Gas            int64              `json:"gas"`
+}
+
+// GasProfile reports where the gas of the profiled transactions went, the
+// opcodes and the programs are sorted from the most expensive
+type GasProfile struct {
+	BlockHeight uint64        `json:"block_height"`
+	BlockHash   *bc.Hash      `json:"block_hash,omitempty"`
+	TxCount     int           `json:"tx_count"`
+	GasUsed     int64         `json:"gas_used"`
+	StorageGas  int64         `json:"storage_gas"`
+	VMGas       int64         `json:"vm_gas"`
+	Error       string        `json:"error,omitempty"`
+	Ops         []*OpGas      `json:"ops"`
+	Programs    []*ProgramGas `json:"programs"`
+}
+
+// gasProfiler accumulates the gas of the profiled transactions
+type gasProfiler struct {
+	profile  *GasProfile
+	ops      map[vm.Op]*OpGas
+	programs map[string]*ProgramGas
+}
+
+func newGasProfiler(height uint64) *gasProfiler {
+	return &gasProfiler{
+		profile:  &GasProfile{BlockHeight: height},
+		ops:      map[vm.Op]*OpGas{},
+		programs: map[string]*ProgramGas{},
+	}
+}
+
+func (p *gasProfiler) profileTx(tx *types.Tx, block *bc.Block, converter validation.ProgramConverterFunc) error {
+	programs := map[bc.Hash][]byte{}
+	for i, inputID := range tx.InputIDs {
+		programs[inputID] = tx.Inputs[i].ControlProgram()
+	}
+
+	profiler := func(entryID bc.Hash) vm.Profiler {
+		controlProgram, ok := programs[entryID]
+		if !ok {
+			return nil
+		}
+
+		program, ok := p.programs[string(controlProgram)]
+		if !ok {
+			program = &ProgramGas{ControlProgram: controlProgram}
+			p.programs[string(controlProgram)] = program
+		}
+		program.Runs++
+
+		return func(op vm.Op, gas int64) {
+			opGas, ok := p.ops[op]
+			if !ok {
+				opGas = &OpGas{Op: op.String()}
+				p.ops[op] = opGas
+			}
+
+			opGas.Count++
+			opGas.Gas += gas
+			program.Gas += gas
+			p.profile.VMGas += gas
+		}
+	}
+
+	p.profile.TxCount++
+	gasStatus, err := validation.ProfileTx(tx.Tx, block, converter, profiler)
+	if gasStatus != nil {
+		p.profile.GasUsed += gasStatus.GasUsed
+		p.profile.StorageGas += gasStatus.StorageGas
+	}
+	return err
+}
+
+// result sorts the accumulated gas, only the most expensive programs are kept
+// when maxPrograms is positive
+func (p *gasProfiler) result(maxPrograms int) *GasProfile {
+	p.profile.Ops = []*OpGas{}
+	for _, opGas := range p.ops {
+		p.profile.Ops = append(p.profile.Ops, opGas)
+	}
+	sort.Slice(p.profile.Ops, func(i, j int) bool {
+		if p.profile.Ops[i].Gas != p.profile.Ops[j].Gas {
+			return p.profile.Ops[i].Gas > p.profile.Ops[j].Gas
+		}
+		return p.profile.Ops[i].Op < p.profile.Ops[j].Op
+	})
+
+	p.profile.Programs = []*ProgramGas{}
+	for _, program := range p.programs {
+		p.profile.Programs = append(p.profile.Programs, program)
+	}
+	sort.Slice(p.profile.Programs, func(i, j int) bool {
+		if p.profile.Programs[i].Gas != p.profile.Programs[j].Gas {
+			return p.profile.Programs[i].Gas > p.profile.Programs[j].Gas
+		}
+		return bytes.Compare(p.profile.Programs[i].ControlProgram, p.profile.Programs[j].ControlProgram) < 0
+	})
+	if maxPrograms > 0 && len(p.profile.Programs) > maxPrograms {
+		p.profile.Programs = p.profile.Programs[:maxPrograms]
+	}
+	return p.profile
+}
+
+// ProfileTx validates the transaction against the best chain state and
+// reports the gas of its programs, a failed validation is reported in the
+// profile with the VM gas charged up to the failure
+func (c *Chain) ProfileTx(tx *types.Tx) (*GasProfile, error) {
+	bh := c.BestBlockHeader()
+	p := newGasProfiler(bh.Height)
+	if err := p.profileTx(tx, types.MapBlock(&types.Block{BlockHeader: *bh}), c.ProgramConverter); err != nil {
+		p.profile.Error = err.Error()
+	}
+	return p.result(0), nil
+}
+
+// ProfileBlock re-validates the transactions of the block and reports the
+// gas of their programs, with the most expensive programs of the block
+func (c *Chain) ProfileBlock(block *types.Block) (*GasProfile, error) {
+	blockHash := block.Hash()
+	p := newGasProfiler(block.Height)
+	p.profile.BlockHash = &blockHash
+
+	bcBlock := types.MapBlock(block)
+	for _, tx := range block.Transactions {
+		if err := p.profileTx(tx, bcBlock, c.ProgramConverter); err != nil {
+			return nil, err
+		}
+	}
+	return p.result(maxProfilePrograms), nil
+}
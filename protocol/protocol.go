@@ -192,10 +192,15 @@ func (c *Chain) InMainChain(hash bc.Hash) bool {
 	return *blockHash == hash
 }
 
-func (c *Chain) SignBlockHeader(blockHeader *types.BlockHeader) {
-	xprv := config.CommonConfig.PrivateKey()
-	signature := xprv.Sign(blockHeader.Hash().Bytes())
+// SignBlockHeader signs the block header with the validator key
+func (c *Chain) SignBlockHeader(blockHeader *types.BlockHeader) error {
+	signature, err := config.CommonConfig.ValidatorSign(blockHeader.Hash().Bytes(), &config.ValidatorSignRequest{BlockHeader: blockHeader})
+	if err != nil {
+		return err
+	}
+
 	blockHeader.Set(signature)
+	return nil
 }
 
 // This function must be called with mu lock in above level
@@ -0,0 +1,92 @@
+package protocol
+
+import (
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/protocol/validation"
+	"github.com/bytom/bytom/protocol/vm"
+)
+
+// maxTraceSteps bounds the steps kept in the trace of one input
+const maxTraceSteps = 10000
+
+// InputTrace is the execution trace of the program unlocked by an input,
+// FailedStep is the instruction the program fails at, the failures inside a
+// CHECKPREDICATE only push false and stay in the steps. Truncated marks the
+// steps cut down to maxTraceSteps, the failed step is always kept.
+type InputTrace struct {
+	InputIndex int             `json:"input_index"`
+	Executed   bool            `json:"executed"`
+	Steps      []*vm.TraceStep `json:"steps"`
+	Truncated  bool            `json:"truncated,omitempty"`
+	FailedStep *vm.TraceStep   `json:"failed_step,omitempty"`
+}
+
+// Simulation is the result of validating a transaction against the best
+// chain state without submitting it
+type Simulation struct {
+	TxID         bc.Hash       `json:"tx_id"`
+	Valid        bool          `json:"valid"`
+	Error        string        `json:"error,omitempty"`
+	MissingUtxos []bc.Hash     `json:"missing_utxos,omitempty"`
+	Fee          uint64        `json:"fee"`
+	GasUsed      int64         `json:"gas_used"`
+	StorageGas   int64         `json:"storage_gas"`
+	Inputs       []*InputTrace `json:"inputs"`
+}
+
+// SimulateTx validates the transaction the same way the pool does and traces
+// the program of every input, a signed or partially signed transaction works
+// alike since the signatures are only checked by the programs
+func (c *Chain) SimulateTx(tx *types.Tx) (*Simulation, error) {
+	missingUtxos, err := c.txPool.MissingUtxos(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	sim := &Simulation{TxID: tx.ID, MissingUtxos: missingUtxos, Fee: tx.Fee(), Inputs: []*InputTrace{}}
+	traces := map[bc.Hash]*InputTrace{}
+	for i, inputID := range tx.InputIDs {
+		trace := &InputTrace{InputIndex: i, Steps: []*vm.TraceStep{}}
+		sim.Inputs = append(sim.Inputs, trace)
+		traces[inputID] = trace
+	}
+
+	tracer := func(entryID bc.Hash) vm.Tracer {
+		trace, ok := traces[entryID]
+		if !ok {
+			return nil
+		}
+
+		trace.Executed = true
+		return func(step *vm.TraceStep) {
+			if step.Error != "" && step.Depth == 0 {
+				trace.FailedStep = step
+			}
+
+			if len(trace.Steps) >= maxTraceSteps {
+				trace.Truncated = true
+				return
+			}
+			trace.Steps = append(trace.Steps, step)
+		}
+	}
+
+	bh := c.BestBlockHeader()
+	gasStatus, err := validation.TraceTx(tx.Tx, types.MapBlock(&types.Block{BlockHeader: *bh}), c.ProgramConverter, tracer)
+	switch {
+	case err != nil:
+		sim.Error = err.Error()
+	case len(missingUtxos) != 0:
+		sim.Error = "transaction spends outputs that are spent or don't exist"
+	case c.txPool.IsDust(tx):
+		sim.Error = ErrDustTx.Error()
+	default:
+		sim.Valid = true
+	}
+
+	if gasStatus != nil {
+		sim.GasUsed, sim.StorageGas = gasStatus.GasUsed, gasStatus.StorageGas
+	}
+	return sim, nil
+}
@@ -7,6 +7,10 @@ import (
 	"github.com/bytom/bytom/protocol/bc"
 )
 
+// ErrSequenceLocked is returned when a spent output isn't confirmed by the
+// number of blocks of the spend sequence
+var ErrSequenceLocked = errors.New("utxo is within the relative lock of the spend")
+
 // UtxoViewpoint represents a view into the set of unspent transaction outputs
 type UtxoViewpoint struct {
 	Entries map[bc.Hash]*storage.UtxoEntry
@@ -28,6 +32,7 @@ func (view *UtxoViewpoint) ApplyTransaction(block *bc.Block, tx *bc.Tx) error {
 }
 
 func (view *UtxoViewpoint) applySpendUtxo(block *bc.Block, tx *bc.Tx) error {
+	sequences := tx.Sequences()
 	for _, prevout := range tx.SpentOutputIDs {
 		entry, ok := view.Entries[prevout]
 		if !ok {
@@ -47,6 +52,10 @@ func (view *UtxoViewpoint) applySpendUtxo(block *bc.Block, tx *bc.Tx) error {
 			}
 		}
 
+		if sequence, ok := sequences[prevout]; ok && consensus.IsLockTimeActive(block.Height) && entry.BlockHeight+sequence > block.Height {
+			return errors.WithDetailf(ErrSequenceLocked, "utxo height %d, sequence %d, block height %d", entry.BlockHeight, sequence, block.Height)
+		}
+
 		entry.SpendOutput()
 	}
 	return nil
@@ -40,7 +40,18 @@ var gasOnlyTxEntry = map[bc.Hash]bc.Entry{
 	},
 }
 
+var sequenceEntry = map[bc.Hash]bc.Entry{
+	bc.Hash{V0: 0}: defaultEntry[bc.Hash{V0: 0}],
+	bc.Hash{V2: 1}: &bc.Spend{
+		SpentOutputId: &bc.Hash{V0: 0},
+		Sequence:      5,
+	},
+}
+
 func TestApplyBlock(t *testing.T) {
+	defer func(params consensus.Params) { consensus.ActiveNetParams = params }(consensus.ActiveNetParams)
+	consensus.ActiveNetParams.LockTimeActivationHeight = 0
+
 	cases := []struct {
 		block     *bc.Block
 		inputView *UtxoViewpoint
@@ -270,6 +281,55 @@ func TestApplyBlock(t *testing.T) {
 			},
 			err: false,
 		},
+		{
+			// the spent output is confirmed by the blocks of the sequence
+			block: &bc.Block{
+				BlockHeader: &bc.BlockHeader{Height: 10},
+				Transactions: []*bc.Tx{
+					&bc.Tx{
+						TxHeader: &bc.TxHeader{
+							ResultIds: []*bc.Hash{},
+						},
+						InputIDs:       []bc.Hash{bc.Hash{V2: 1}},
+						SpentOutputIDs: []bc.Hash{bc.Hash{V0: 0}},
+						Entries:        sequenceEntry,
+					},
+				},
+			},
+			inputView: &UtxoViewpoint{
+				Entries: map[bc.Hash]*storage.UtxoEntry{
+					bc.Hash{V0: 0}: storage.NewUtxoEntry(storage.NormalUTXOType, 5, false),
+				},
+			},
+			fetchView: &UtxoViewpoint{
+				Entries: map[bc.Hash]*storage.UtxoEntry{
+					bc.Hash{V0: 0}: storage.NewUtxoEntry(storage.NormalUTXOType, 5, true),
+				},
+			},
+			err: false,
+		},
+		{
+			// the spent output is within the relative lock of the sequence
+			block: &bc.Block{
+				BlockHeader: &bc.BlockHeader{Height: 10},
+				Transactions: []*bc.Tx{
+					&bc.Tx{
+						TxHeader: &bc.TxHeader{
+							ResultIds: []*bc.Hash{},
+						},
+						InputIDs:       []bc.Hash{bc.Hash{V2: 1}},
+						SpentOutputIDs: []bc.Hash{bc.Hash{V0: 0}},
+						Entries:        sequenceEntry,
+					},
+				},
+			},
+			inputView: &UtxoViewpoint{
+				Entries: map[bc.Hash]*storage.UtxoEntry{
+					bc.Hash{V0: 0}: storage.NewUtxoEntry(storage.NormalUTXOType, 6, false),
+				},
+			},
+			err: true,
+		},
 	}
 
 	for i, c := range cases {
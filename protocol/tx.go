@@ -3,7 +3,9 @@ package protocol
 import (
 	log "github.com/sirupsen/logrus"
 
+	"github.com/bytom/bytom/consensus"
 	"github.com/bytom/bytom/consensus/bcrp"
+	"github.com/bytom/bytom/database/storage"
 	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/protocol/bc"
 	"github.com/bytom/bytom/protocol/bc/types"
@@ -19,6 +21,11 @@ func (c *Chain) GetTransactionsUtxo(view *state.UtxoViewpoint, txs []*bc.Tx) err
 	return c.store.GetTransactionsUtxo(view, txs)
 }
 
+// GetUtxo returns the unspent output entry of the best chain state
+func (c *Chain) GetUtxo(hash *bc.Hash) (*storage.UtxoEntry, error) {
+	return c.store.GetUtxo(hash)
+}
+
 // ValidateTx validates the given transaction. A cache holds
 // per-transaction validation results and is consulted before
 // performing full validation.
@@ -33,16 +40,127 @@ func (c *Chain) ValidateTx(tx *types.Tx) (bool, error) {
 	}
 
 	bh := c.BestBlockHeader()
-	gasStatus, err := validation.ValidateTx(tx.Tx, types.MapBlock(&types.Block{BlockHeader: *bh}), c.ProgramConverter)
+	lockHeight, lockTimestamp, err := c.txLockPoint(tx, bh)
+	if err != nil {
+		return false, err
+	}
+
+	// the transaction is validated against the block the proposer builds on
+	// the best block, or a mocked block at the lock point if it's still locked
+	block := nextBlock(bh)
+	locked := lockHeight > block.Height || lockTimestamp > block.Timestamp
+	if locked {
+		if err := checkLockHorizon(block.BlockHeader, lockHeight, lockTimestamp); err != nil {
+			c.txPool.AddErrCache(&tx.ID, err)
+			return false, err
+		}
+
+		block.Height, block.Timestamp = maxUint64(block.Height, lockHeight), maxUint64(block.Timestamp, lockTimestamp)
+	}
+
+	gasStatus, err := validation.ValidateTx(tx.Tx, block, c.ProgramConverter)
 	if err != nil {
 		log.WithFields(log.Fields{"module": logModule, "tx_id": tx.Tx.ID.String(), "error": err}).Info("transaction status fail")
 		c.txPool.AddErrCache(&tx.ID, err)
 		return false, err
 	}
 
+	if locked {
+		return false, c.txPool.AddLockedTransaction(tx, lockHeight, lockTimestamp, gasStatus.BTMValue)
+	}
+
 	return c.txPool.ProcessTransaction(tx, bh.Height, gasStatus.BTMValue)
 }
 
+// nextBlock mocks the lowest block the proposer can build on the best block
+func nextBlock(bh *types.BlockHeader) *bc.Block {
+	return &bc.Block{
+		BlockHeader: &bc.BlockHeader{
+			Version:   bh.Version,
+			Height:    bh.Height + 1,
+			Timestamp: bh.Timestamp + consensus.ActiveNetParams.BlockTimeInterval,
+		},
+	}
+}
+
+func maxUint64(a, b uint64) uint64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// txLockPoint returns the height and the timestamp of the block from which the
+// lock time and the sequences of the transaction are reached, the sequence of
+// a spent output not in the chain yet counts from the next block
+func (c *Chain) txLockPoint(tx *types.Tx, bh *types.BlockHeader) (uint64, uint64, error) {
+	var lockHeight, lockTimestamp uint64
+	if tx.LockTime < consensus.LockTimeThreshold {
+		lockHeight = tx.LockTime
+	} else {
+		lockTimestamp = tx.LockTime
+	}
+
+	sequences := tx.Sequences()
+	if len(sequences) == 0 {
+		return lockHeight, lockTimestamp, nil
+	}
+
+	view := state.NewUtxoViewpoint()
+	if err := c.store.GetTransactionsUtxo(view, []*bc.Tx{tx.Tx}); err != nil {
+		return 0, 0, err
+	}
+
+	for outputID, sequence := range sequences {
+		height := bh.Height + 1 + sequence
+		if view.CanSpend(&outputID) {
+			height = view.Entries[outputID].BlockHeight + sequence
+		}
+
+		if height > lockHeight {
+			lockHeight = height
+		}
+	}
+	return lockHeight, lockTimestamp, nil
+}
+
+// releaseLockedTxs validates again the held transactions whose lock is reached
+// by the next block of the best block
+func (c *Chain) releaseLockedTxs(bh *types.BlockHeader) {
+	next := nextBlock(bh)
+	for _, tx := range c.txPool.ReleaseLockedTransactions(next.Height, next.Timestamp) {
+		if _, err := c.ValidateTx(tx); err != nil {
+			log.WithFields(log.Fields{"module": logModule, "tx_id": tx.Tx.ID.String(), "error": err}).Info("release locked tx fail")
+		}
+	}
+}
+
+// relockPoolTxs moves the pool transactions whose lock isn't reached by the
+// next block of the best block after a reorganization back to the locked set
+func (c *Chain) relockPoolTxs(bh *types.BlockHeader) {
+	next := nextBlock(bh)
+	for _, txD := range c.txPool.GetTransactions() {
+		if txD.Tx.LockTime == 0 && len(txD.Tx.Sequences()) == 0 {
+			continue
+		}
+
+		lockHeight, lockTimestamp, err := c.txLockPoint(txD.Tx, bh)
+		if err != nil {
+			log.WithFields(log.Fields{"module": logModule, "tx_id": txD.Tx.ID.String(), "error": err}).Error("relock pool tx fail")
+			continue
+		}
+
+		if lockHeight <= next.Height && lockTimestamp <= next.Timestamp {
+			continue
+		}
+
+		c.txPool.RemoveTransaction(&txD.Tx.ID)
+		if err := c.txPool.AddLockedTransaction(txD.Tx, lockHeight, lockTimestamp, txD.Fee); err != nil {
+			log.WithFields(log.Fields{"module": logModule, "tx_id": txD.Tx.ID.String(), "error": err}).Info("relock pool tx fail")
+		}
+	}
+}
+
 //ProgramConverter convert program. Only for BCRP now
 func (c *Chain) ProgramConverter(prog []byte) ([]byte, error) {
 	hash, err := bcrp.ParseContractHash(prog)
@@ -29,9 +29,11 @@ var (
 	maxMsgChSize    = 1000
 	maxNewTxNum     = 10000
 	maxOrphanNum    = 2000
+	maxLockedNum    = 2000
 
 	orphanTTL                = 10 * time.Minute
 	orphanExpireScanInterval = 3 * time.Minute
+	lockedTTL                = 24 * time.Hour
 
 	// ErrTransactionNotExist is the pre-defined error message
 	ErrTransactionNotExist = errors.New("transaction are not existed in the mempool")
@@ -39,6 +41,8 @@ var (
 	ErrPoolIsFull = errors.New("transaction pool reach the max number")
 	// ErrDustTx indicates transaction is dust tx
 	ErrDustTx = errors.New("transaction is dust tx")
+	// ErrLockHorizon indicates the lock of the transaction is too far to hold
+	ErrLockHorizon = errors.New("transaction lock is beyond the locked pool horizon")
 )
 
 type TxMsgEvent struct{ TxMsg *TxPoolMsg }
@@ -63,6 +67,37 @@ type orphanTx struct {
 	expiration time.Time
 }
 
+// lockedTx is a transaction held until the next block reaches its lock height
+// and lock timestamp
+type lockedTx struct {
+	tx            *types.Tx
+	lockHeight    uint64
+	lockTimestamp uint64
+	fee           uint64
+	added         time.Time
+	expiration    time.Time
+}
+
+// worseThan returns whether the locked tx pays a lower fee per byte than the
+// other one, or the same fee per byte but is held earlier
+func (l *lockedTx) worseThan(other *lockedTx) bool {
+	a, b := l.fee*other.tx.SerializedSize, other.fee*l.tx.SerializedSize
+	if a != b {
+		return a < b
+	}
+	return l.added.Before(other.added)
+}
+
+// checkLockHorizon rejects the lock that the next block can't reach within the
+// time a locked transaction is held
+func checkLockHorizon(next *bc.BlockHeader, lockHeight, lockTimestamp uint64) error {
+	horizon := uint64(lockedTTL / time.Millisecond)
+	if lockHeight > next.Height+horizon/consensus.ActiveNetParams.BlockTimeInterval || lockTimestamp > next.Timestamp+horizon {
+		return ErrLockHorizon
+	}
+	return nil
+}
+
 // TxPool is use for store the unconfirmed transaction
 type TxPool struct {
 	lastUpdated     int64
@@ -72,6 +107,7 @@ type TxPool struct {
 	utxo            map[bc.Hash]*types.Tx
 	orphans         map[bc.Hash]*orphanTx
 	orphansByPrev   map[bc.Hash]map[bc.Hash]*orphanTx
+	locked          map[bc.Hash]*lockedTx
 	errCache        *lru.Cache
 	eventDispatcher *event.Dispatcher
 }
@@ -85,6 +121,7 @@ func NewTxPool(store state.Store, dispatcher *event.Dispatcher) *TxPool {
 		utxo:            make(map[bc.Hash]*types.Tx),
 		orphans:         make(map[bc.Hash]*orphanTx),
 		orphansByPrev:   make(map[bc.Hash]map[bc.Hash]*orphanTx),
+		locked:          make(map[bc.Hash]*lockedTx),
 		errCache:        lru.New(maxCachedErrTxs),
 		eventDispatcher: dispatcher,
 	}
@@ -112,6 +149,18 @@ func (tp *TxPool) ExpireOrphan(now time.Time) {
 	}
 }
 
+// ExpireLocked expire all the locked transactions held before the input time
+func (tp *TxPool) ExpireLocked(now time.Time) {
+	tp.mtx.Lock()
+	defer tp.mtx.Unlock()
+
+	for hash, locked := range tp.locked {
+		if locked.expiration.Before(now) {
+			delete(tp.locked, hash)
+		}
+	}
+}
+
 // GetErrCache return the error of the transaction
 func (tp *TxPool) GetErrCache(txHash *bc.Hash) error {
 	tp.mtx.Lock()
@@ -169,6 +218,15 @@ func (tp *TxPool) GetTransactions() []*TxDesc {
 	return txDs
 }
 
+// GetUtxoTx return the pool transaction which creates the given output
+func (tp *TxPool) GetUtxoTx(outputID *bc.Hash) (*types.Tx, bool) {
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+
+	tx, ok := tp.utxo[*outputID]
+	return tx, ok
+}
+
 // IsTransactionInPool check wheather a transaction in pool or not
 func (tp *TxPool) IsTransactionInPool(txHash *bc.Hash) bool {
 	tp.mtx.RLock()
@@ -187,9 +245,73 @@ func (tp *TxPool) IsTransactionInErrCache(txHash *bc.Hash) bool {
 	return ok
 }
 
+// IsTransactionLocked check wheather a transaction is held until its lock
+// time is reached or not
+func (tp *TxPool) IsTransactionLocked(txHash *bc.Hash) bool {
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+
+	_, ok := tp.locked[*txHash]
+	return ok
+}
+
 // HaveTransaction IsTransactionInErrCache check is  transaction in errCache or pool
 func (tp *TxPool) HaveTransaction(txHash *bc.Hash) bool {
-	return tp.IsTransactionInPool(txHash) || tp.IsTransactionInErrCache(txHash)
+	return tp.IsTransactionInPool(txHash) || tp.IsTransactionInErrCache(txHash) || tp.IsTransactionLocked(txHash)
+}
+
+// AddLockedTransaction holds the transaction until the next block reaches the
+// lock height and the lock timestamp, the locked tx paying the lowest fee per
+// byte is evicted when the locked set is full
+func (tp *TxPool) AddLockedTransaction(tx *types.Tx, lockHeight, lockTimestamp, fee uint64) error {
+	tp.mtx.Lock()
+	defer tp.mtx.Unlock()
+
+	now := time.Now()
+	locked := &lockedTx{
+		tx:            tx,
+		lockHeight:    lockHeight,
+		lockTimestamp: lockTimestamp,
+		fee:           fee,
+		added:         now,
+		expiration:    now.Add(lockedTTL),
+	}
+
+	if len(tp.locked) >= maxLockedNum {
+		var worst *lockedTx
+		for _, l := range tp.locked {
+			if worst == nil || l.worseThan(worst) {
+				worst = l
+			}
+		}
+
+		if !worst.worseThan(locked) {
+			return ErrPoolIsFull
+		}
+
+		delete(tp.locked, worst.tx.ID)
+		log.WithFields(log.Fields{"module": logModule, "tx_id": worst.tx.ID.String()}).Debug("evict locked tx")
+	}
+
+	tp.locked[tx.ID] = locked
+	log.WithFields(log.Fields{"module": logModule, "tx_id": tx.ID.String(), "lock_height": lockHeight, "lock_timestamp": lockTimestamp}).Debug("hold locked tx")
+	return nil
+}
+
+// ReleaseLockedTransactions removes and returns the held transactions whose
+// lock is reached by the block of the height and the timestamp
+func (tp *TxPool) ReleaseLockedTransactions(height, timestamp uint64) []*types.Tx {
+	tp.mtx.Lock()
+	defer tp.mtx.Unlock()
+
+	txs := []*types.Tx{}
+	for hash, locked := range tp.locked {
+		if locked.lockHeight <= height && locked.lockTimestamp <= timestamp {
+			txs = append(txs, locked.tx)
+			delete(tp.locked, hash)
+		}
+	}
+	return txs
 }
 
 func isTransactionNoBtmInput(tx *types.Tx) bool {
@@ -314,12 +436,33 @@ func (tp *TxPool) checkOrphanUtxos(tx *types.Tx) ([]*bc.Hash, error) {
 	return hashes, nil
 }
 
+// MissingUtxos returns the outputs spent by the transaction that are
+// neither unspent in the chain nor created by a pool transaction
+func (tp *TxPool) MissingUtxos(tx *types.Tx) ([]bc.Hash, error) {
+	view := state.NewUtxoViewpoint()
+	if err := tp.store.GetTransactionsUtxo(view, []*bc.Tx{tx.Tx}); err != nil {
+		return nil, err
+	}
+
+	tp.mtx.RLock()
+	defer tp.mtx.RUnlock()
+
+	hashes := []bc.Hash{}
+	for _, hash := range tx.SpentOutputIDs {
+		if !view.CanSpend(&hash) && tp.utxo[hash] == nil {
+			hashes = append(hashes, hash)
+		}
+	}
+	return hashes, nil
+}
+
 func (tp *TxPool) orphanExpireWorker() {
 	ticker := time.NewTicker(orphanExpireScanInterval)
 	defer ticker.Stop()
 
 	for now := range ticker.C {
 		tp.ExpireOrphan(now)
+		tp.ExpireLocked(now)
 	}
 }
 
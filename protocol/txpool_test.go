@@ -1,17 +1,21 @@
 package protocol
 
 import (
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/davecgh/go-spew/spew"
+	"github.com/golang/groupcache/lru"
 
 	"github.com/bytom/bytom/consensus"
 	"github.com/bytom/bytom/database/storage"
+	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/event"
 	"github.com/bytom/bytom/protocol/bc"
 	"github.com/bytom/bytom/protocol/bc/types"
 	"github.com/bytom/bytom/protocol/state"
+	"github.com/bytom/bytom/protocol/vm"
 	"github.com/bytom/bytom/testutil"
 )
 
@@ -370,6 +374,140 @@ func TestExpireOrphan(t *testing.T) {
 	}
 }
 
+func TestReleaseLockedTransactions(t *testing.T) {
+	tp := &TxPool{
+		locked:   make(map[bc.Hash]*lockedTx),
+		errCache: lru.New(maxCachedErrTxs),
+	}
+	if err := tp.AddLockedTransaction(testTxs[0], 100, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := tp.AddLockedTransaction(testTxs[1], 0, 1600000000000, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := tp.AddLockedTransaction(testTxs[2], 200, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if !tp.HaveTransaction(&testTxs[2].ID) {
+		t.Errorf("locked tx %s isn't in the pool", testTxs[2].ID.String())
+	}
+
+	if got := tp.ReleaseLockedTransactions(99, 1700000000000); len(got) != 1 || got[0] != testTxs[1] {
+		t.Errorf("got released txs %v, want tx1", got)
+	}
+
+	if got := tp.ReleaseLockedTransactions(150, 1700000000000); len(got) != 1 || got[0] != testTxs[0] {
+		t.Errorf("got released txs %v, want tx0", got)
+	}
+
+	if tp.IsTransactionLocked(&testTxs[0].ID) || !tp.IsTransactionLocked(&testTxs[2].ID) {
+		t.Errorf("got locked txs %v, want tx2", tp.locked)
+	}
+
+	tp.ExpireLocked(time.Now().Add(lockedTTL + time.Minute))
+	if len(tp.locked) != 0 {
+		t.Errorf("got locked txs %v after expired", tp.locked)
+	}
+}
+
+func TestEvictLockedTransaction(t *testing.T) {
+	defer func(num int) { maxLockedNum = num }(maxLockedNum)
+	maxLockedNum = 2
+
+	tp := &TxPool{locked: make(map[bc.Hash]*lockedTx)}
+	if err := tp.AddLockedTransaction(testTxs[0], 100, 0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if err := tp.AddLockedTransaction(testTxs[1], 100, 0, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tp.AddLockedTransaction(testTxs[3], 100, 0, 4); err != ErrPoolIsFull {
+		t.Errorf("got error %v when the tx pays the lowest fee, want %v", err, ErrPoolIsFull)
+	}
+
+	if err := tp.AddLockedTransaction(testTxs[4], 100, 0, 20); err != nil {
+		t.Fatal(err)
+	}
+
+	if tp.IsTransactionLocked(&testTxs[1].ID) || !tp.IsTransactionLocked(&testTxs[0].ID) || !tp.IsTransactionLocked(&testTxs[4].ID) {
+		t.Errorf("got locked txs %v, want tx0 and tx4", tp.locked)
+	}
+}
+
+func TestValidateLockedTx(t *testing.T) {
+	defer func(params consensus.Params) { consensus.ActiveNetParams = params }(consensus.ActiveNetParams)
+	consensus.ActiveNetParams.LockTimeActivationHeight = 0
+
+	newTx := func(lockTime uint64, program []byte) *types.Tx {
+		return types.NewTx(types.TxData{
+			Version:        1,
+			SerializedSize: 100,
+			LockTime:       lockTime,
+			Inputs: []*types.TxInput{
+				types.NewSpendInput(nil, bc.NewHash([32]byte{0x01}), *consensus.BTMAssetID, 100000000, 1, program, nil),
+			},
+			Outputs: []*types.TxOutput{
+				types.NewOriginalTxOutput(*consensus.BTMAssetID, 1, []byte{0x6a}, nil),
+			},
+		})
+	}
+
+	cases := []struct {
+		desc       string
+		tx         *types.Tx
+		wantErr    error
+		wantLocked bool
+	}{
+		{
+			desc:       "the lock is reached after the next block",
+			tx:         newTx(150, []byte{0x51}),
+			wantLocked: true,
+		},
+		{
+			desc: "the lock is reached by the next block",
+			tx:   newTx(101, []byte{0x51}),
+		},
+		{
+			desc:    "the locked tx fails the program",
+			tx:      newTx(150, []byte{0x00}),
+			wantErr: vm.ErrFalseVMResult,
+		},
+		{
+			desc:    "the lock is beyond the horizon",
+			tx:      newTx(100+20000, []byte{0x51}),
+			wantErr: ErrLockHorizon,
+		},
+	}
+
+	for i, c := range cases {
+		chain := &Chain{
+			txPool: &TxPool{
+				store:           &mockStore{},
+				pool:            make(map[bc.Hash]*TxDesc),
+				utxo:            make(map[bc.Hash]*types.Tx),
+				orphans:         make(map[bc.Hash]*orphanTx),
+				orphansByPrev:   make(map[bc.Hash]map[bc.Hash]*orphanTx),
+				locked:          make(map[bc.Hash]*lockedTx),
+				errCache:        lru.New(maxCachedErrTxs),
+				eventDispatcher: event.NewDispatcher(),
+			},
+			store:           &mockStore{},
+			cond:            sync.Cond{L: new(sync.Mutex)},
+			bestBlockHeader: &types.BlockHeader{Version: 1, Height: 100, Timestamp: 1600000000000},
+		}
+
+		if _, err := chain.ValidateTx(c.tx); errors.Root(err) != c.wantErr {
+			t.Errorf("case %d (%s): got error %v, want %v", i, c.desc, err, c.wantErr)
+		}
+
+		if locked := chain.txPool.IsTransactionLocked(&c.tx.ID); locked != c.wantLocked {
+			t.Errorf("case %d (%s): got locked %t, want %t", i, c.desc, locked, c.wantLocked)
+		}
+	}
+}
+
 func TestProcessOrphans(t *testing.T) {
 	t.Skip("Skipping testing in CI environment temp")
 	dispatcher := event.NewDispatcher()
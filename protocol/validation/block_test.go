@@ -7,9 +7,11 @@ import (
 	"time"
 
 	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
 	"github.com/bytom/bytom/protocol/bc"
 	"github.com/bytom/bytom/protocol/bc/types"
 	"github.com/bytom/bytom/protocol/state"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
 )
 
 func TestCheckBlockTime(t *testing.T) {
@@ -256,3 +258,75 @@ func TestValidateBlockHeader(t *testing.T) {
 		}
 	}
 }
+
+func TestValidateBlockLockTime(t *testing.T) {
+	defer func(params consensus.Params) { consensus.ActiveNetParams = params }(consensus.ActiveNetParams)
+
+	xprv, err := chainkd.NewXPrv(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	xpub := xprv.XPub()
+	checkpoint := &state.Checkpoint{
+		Status: state.Justified,
+		Votes:  map[string]uint64{hex.EncodeToString(xpub[:]): consensus.ActiveNetParams.MinValidatorVoteNum},
+	}
+
+	parent := &types.BlockHeader{
+		Version:   1,
+		Height:    1,
+		Timestamp: uint64(time.Now().UnixNano()/1e6) - consensus.ActiveNetParams.BlockTimeInterval,
+	}
+
+	cp, _ := vmutil.DefaultCoinbaseProgram()
+	coinbase := types.NewTx(types.TxData{
+		Version:        1,
+		SerializedSize: 1,
+		Inputs:         []*types.TxInput{types.NewCoinbaseInput([]byte("arbitrary"))},
+		Outputs:        []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 0, cp, nil)},
+	})
+	lockedTx := types.NewTx(types.TxData{
+		Version:        1,
+		SerializedSize: 1,
+		LockTime:       1,
+		Inputs:         []*types.TxInput{mockGasTxInput()},
+		Outputs:        []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 1, []byte{0x6a}, nil)},
+	})
+
+	block := &types.Block{
+		BlockHeader: types.BlockHeader{
+			Version:           1,
+			Height:            2,
+			PreviousBlockHash: parent.Hash(),
+			Timestamp:         parent.Timestamp + consensus.ActiveNetParams.BlockTimeInterval,
+		},
+		Transactions: []*types.Tx{coinbase, lockedTx},
+	}
+
+	merkleRoot, err := types.TxMerkleRoot([]*bc.Tx{coinbase.Tx, lockedTx.Tx})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block.TransactionsMerkleRoot = merkleRoot
+	block.BlockWitness = xprv.Sign(block.Hash().Bytes())
+
+	cases := []struct {
+		activation uint64
+		err        error
+	}{
+		{activation: 0, err: nil},
+		{activation: 2, err: nil},
+		{activation: 3, err: ErrLockTimeNotActive},
+		{activation: math.MaxUint64, err: ErrLockTimeNotActive},
+	}
+
+	converter := func(prog []byte) ([]byte, error) { return nil, nil }
+	for i, c := range cases {
+		consensus.ActiveNetParams.LockTimeActivationHeight = c.activation
+		if err := ValidateBlock(block, parent, checkpoint, converter); rootErr(err) != c.err {
+			t.Errorf("case %d: got error %v, want %v", i, err, c.err)
+		}
+	}
+}
@@ -38,6 +38,9 @@ var (
 	ErrVotePubKey                = errors.New("invalid public key of vote")
 	ErrVoteOutputAmount          = errors.New("invalid vote amount")
 	ErrVoteOutputAseet           = errors.New("incorrect asset_id while checking vote asset")
+	ErrVMVersion                 = errors.New("VM version is not active")
+	ErrTxNotFinal                = errors.New("transaction lock time is not reached")
+	ErrLockTimeNotActive         = errors.New("transaction lock time is not active")
 )
 
 // GasState record the gas usage status
@@ -104,6 +107,14 @@ func (g *GasState) updateUsage(gasLeft int64) error {
 // ProgramConverterFunc represent a func convert control program
 type ProgramConverterFunc func(prog []byte) ([]byte, error)
 
+// EntryTracerFunc returns the tracer of the program run by the entry, nil
+// for the entries not to be traced
+type EntryTracerFunc func(entryID bc.Hash) vm.Tracer
+
+// EntryProfilerFunc returns the gas profiler of the program run by the entry,
+// nil for the entries not to be profiled
+type EntryProfilerFunc func(entryID bc.Hash) vm.Profiler
+
 // validationState contains the context that must propagate through
 // the transaction graph when validating entries.
 type validationState struct {
@@ -115,6 +126,8 @@ type validationState struct {
 	destPos   uint64               // The destination position, for validate ValueDestinations
 	cache     map[bc.Hash]error    // Memoized per-entry validation results
 	converter ProgramConverterFunc // Program converter function
+	tracer    EntryTracerFunc      // Tracer of the programs, nil when not tracing
+	profiler  EntryProfilerFunc    // Gas profiler of the programs, nil when not profiling
 }
 
 func checkValid(vs *validationState, e bc.Entry) (err error) {
@@ -202,6 +215,10 @@ func checkValid(vs *validationState, e bc.Entry) (err error) {
 		}
 
 	case *bc.OriginalOutput:
+		if err = checkVMVersion(vs, e.ControlProgram); err != nil {
+			return errors.Wrap(err, "checking output program")
+		}
+
 		vs2 := *vs
 		vs2.sourcePos = 0
 		if err = checkValidSrc(&vs2, e.Source); err != nil {
@@ -219,6 +236,10 @@ func checkValid(vs *validationState, e bc.Entry) (err error) {
 			return ErrVotePubKey
 		}
 
+		if err = checkVMVersion(vs, e.ControlProgram); err != nil {
+			return errors.Wrap(err, "checking vote output program")
+		}
+
 		vs2 := *vs
 		vs2.sourcePos = 0
 		if err = checkValidSrc(&vs2, e.Source); err != nil {
@@ -238,6 +259,10 @@ func checkValid(vs *validationState, e bc.Entry) (err error) {
 			return errors.WithDetailf(ErrMismatchedAssetID, "asset ID is %x, issuance wants %x", computedAssetID.Bytes(), e.Value.AssetId.Bytes())
 		}
 
+		if err = checkVMVersion(vs, e.WitnessAssetDefinition.IssuanceProgram); err != nil {
+			return errors.Wrap(err, "checking issuance program")
+		}
+
 		gasLeft, err := vm.Verify(NewTxVMContext(vs, e, e.WitnessAssetDefinition.IssuanceProgram, [][]byte{}, e.WitnessArguments), vs.gasStatus.GasLeft)
 		if err != nil {
 			return errors.Wrap(err, "checking issuance program")
@@ -358,6 +383,16 @@ func checkValid(vs *validationState, e bc.Entry) (err error) {
 	return nil
 }
 
+// checkVMVersion rejects the programs of the VM versions which are not active
+// at the height of the validated block
+func checkVMVersion(vs *validationState, prog *bc.Program) error {
+	height := vs.block.BlockHeader.GetHeight()
+	if !vm.IsSupportedVersion(prog.VmVersion) || !consensus.IsVMVersionActive(prog.VmVersion, height) {
+		return errors.WithDetailf(ErrVMVersion, "VM version %d at height %d", prog.VmVersion, height)
+	}
+	return nil
+}
+
 func checkValidSrc(vstate *validationState, vs *bc.ValueSource) error {
 	if vs == nil {
 		return errors.Wrap(ErrMissingField, "empty value source")
@@ -524,8 +559,42 @@ func checkTimeRange(tx *bc.Tx, block *bc.Block) error {
 	return nil
 }
 
+func checkLockTime(tx *bc.Tx, block *bc.Block) error {
+	// the transaction serialized with the lock time flag always carries lock
+	// time or an input sequence, so checking the fields rejects the flag too
+	if !consensus.IsLockTimeActive(block.Height) {
+		if tx.LockTime != 0 || len(tx.Sequences()) != 0 {
+			return errors.WithDetailf(ErrLockTimeNotActive, "block height %d", block.Height)
+		}
+		return nil
+	}
+
+	if consensus.IsLockTimeReached(tx.LockTime, block.Height, block.Timestamp) {
+		return nil
+	}
+
+	return errors.WithDetailf(ErrTxNotFinal, "lock time %d, block height %d, block timestamp %d", tx.LockTime, block.Height, block.Timestamp)
+}
+
 // ValidateTx validates a transaction.
 func ValidateTx(tx *bc.Tx, block *bc.Block, converter ProgramConverterFunc) (*GasState, error) {
+	return validateTx(tx, block, converter, nil, nil)
+}
+
+// TraceTx validates a transaction the same way ValidateTx does, every step of
+// the executed programs is passed to the tracer of the entry
+func TraceTx(tx *bc.Tx, block *bc.Block, converter ProgramConverterFunc, tracer EntryTracerFunc) (*GasState, error) {
+	return validateTx(tx, block, converter, tracer, nil)
+}
+
+// ProfileTx validates a transaction the same way ValidateTx does, the gas of
+// every instruction of the executed programs is passed to the profiler of
+// the entry
+func ProfileTx(tx *bc.Tx, block *bc.Block, converter ProgramConverterFunc, profiler EntryProfilerFunc) (*GasState, error) {
+	return validateTx(tx, block, converter, nil, profiler)
+}
+
+func validateTx(tx *bc.Tx, block *bc.Block, converter ProgramConverterFunc, tracer EntryTracerFunc, profiler EntryProfilerFunc) (*GasState, error) {
 	if block.Version == 1 && tx.Version != 1 {
 		return nil, errors.WithDetailf(ErrTxVersion, "block version %d, transaction version %d", block.Version, tx.Version)
 	}
@@ -538,6 +607,10 @@ func ValidateTx(tx *bc.Tx, block *bc.Block, converter ProgramConverterFunc) (*Ga
 		return nil, err
 	}
 
+	if err := checkLockTime(tx, block); err != nil {
+		return nil, err
+	}
+
 	if err := checkDoubleSpend(tx); err != nil {
 		return nil, err
 	}
@@ -549,6 +622,8 @@ func ValidateTx(tx *bc.Tx, block *bc.Block, converter ProgramConverterFunc) (*Ga
 		gasStatus: &GasState{},
 		cache:     make(map[bc.Hash]error),
 		converter: converter,
+		tracer:    tracer,
+		profiler:  profiler,
 	}
 
 	if err := checkValid(vs, tx.TxHeader); err != nil {
@@ -986,6 +986,72 @@ func TestTimeRange(t *testing.T) {
 	}
 }
 
+func TestLockTime(t *testing.T) {
+	defer func(params consensus.Params) { consensus.ActiveNetParams = params }(consensus.ActiveNetParams)
+	converter := func(prog []byte) ([]byte, error) { return nil, nil }
+	cases := []struct {
+		activation uint64
+		lockTime   uint64
+		err        error
+	}{
+		{
+			lockTime: 0,
+			err:      nil,
+		},
+		{
+			lockTime: 333,
+			err:      nil,
+		},
+		{
+			lockTime: 334,
+			err:      ErrTxNotFinal,
+		},
+		{
+			lockTime: 1521625823000,
+			err:      nil,
+		},
+		{
+			lockTime: 1521625823001,
+			err:      ErrTxNotFinal,
+		},
+		{
+			activation: 334,
+			lockTime:   0,
+			err:        nil,
+		},
+		{
+			activation: 334,
+			lockTime:   333,
+			err:        ErrLockTimeNotActive,
+		},
+	}
+
+	block := &bc.Block{
+		BlockHeader: &bc.BlockHeader{
+			Height:    333,
+			Timestamp: 1521625823000,
+		},
+	}
+
+	tx := types.MapTx(&types.TxData{
+		SerializedSize: 1,
+		Inputs: []*types.TxInput{
+			mockGasTxInput(),
+		},
+		Outputs: []*types.TxOutput{
+			types.NewOriginalTxOutput(*consensus.BTMAssetID, 1, []byte{0x6a}, nil),
+		},
+	})
+
+	for i, c := range cases {
+		consensus.ActiveNetParams.LockTimeActivationHeight = c.activation
+		tx.LockTime = c.lockTime
+		if _, err := ValidateTx(tx, block, converter); rootErr(err) != c.err {
+			t.Errorf("#%d got error %v, want %v", i, err, c.err)
+		}
+	}
+}
+
 func TestValidateTxVersion(t *testing.T) {
 	converter := func(prog []byte) ([]byte, error) { return nil, nil }
 	cases := []struct {
@@ -1206,3 +1272,27 @@ func getMuxID(tx *bc.Tx) *bc.Hash {
 	}
 	return nil
 }
+
+func TestCheckVMVersion(t *testing.T) {
+	defer func(params consensus.Params) { consensus.ActiveNetParams = params }(consensus.ActiveNetParams)
+	consensus.ActiveNetParams.VMVersionActivations = []consensus.VMVersionActivation{{Version: 2, Height: 100}}
+
+	cases := []struct {
+		version uint64
+		height  uint64
+		wantErr error
+	}{
+		{version: 1, height: 0},
+		{version: 2, height: 99, wantErr: ErrVMVersion},
+		{version: 2, height: 100},
+		{version: vm.MaxVersion + 1, height: 100, wantErr: ErrVMVersion},
+		{version: 0, height: 100, wantErr: ErrVMVersion},
+	}
+
+	for i, c := range cases {
+		vs := &validationState{block: &bc.Block{BlockHeader: &bc.BlockHeader{Height: c.height}}}
+		if err := checkVMVersion(vs, &bc.Program{VmVersion: c.version, Code: []byte{byte(vm.OP_TRUE)}}); errors.Root(err) != c.wantErr {
+			t.Errorf("case %d: got error %v want %v", i, err, c.wantErr)
+		}
+	}
+}
@@ -23,6 +23,7 @@ func NewTxVMContext(vs *validationState, entry bc.Entry, prog *bc.Program, state
 		amount        *uint64
 		destPos       *uint64
 		spentOutputID *[]byte
+		sequence      *uint64
 	)
 
 	switch e := entry.(type) {
@@ -40,6 +41,7 @@ func NewTxVMContext(vs *validationState, entry bc.Entry, prog *bc.Program, state
 		destPos = &e.WitnessDestination.Position
 		s := e.SpentOutputId.Bytes()
 		spentOutputID = &s
+		sequence = &e.Sequence
 	}
 
 	var txSigHash *[]byte
@@ -74,6 +76,8 @@ func NewTxVMContext(vs *validationState, entry bc.Entry, prog *bc.Program, state
 
 		TxVersion:   &tx.Version,
 		BlockHeight: &blockHeight,
+		LockTime:    &tx.LockTime,
+		Sequence:    sequence,
 
 		TxSigHash:     txSigHashFn,
 		NumResults:    &numResults,
@@ -82,8 +86,16 @@ func NewTxVMContext(vs *validationState, entry bc.Entry, prog *bc.Program, state
 		DestPos:       destPos,
 		SpentOutputID: spentOutputID,
 		CheckOutput:   ec.checkOutput,
+
+		OutputStateData: ec.outputStateData,
 	}
 
+	if vs.tracer != nil {
+		result.Tracer = vs.tracer(entryID)
+	}
+	if vs.profiler != nil {
+		result.Profiler = vs.profiler(entryID)
+	}
 	return result
 }
 
@@ -202,6 +214,76 @@ func (ec *entryContext) checkOutput(index uint64, amount uint64, assetID []byte,
 	return false, vm.ErrContext
 }
 
+// outputStateData returns the state data of the output at the index of the
+// destinations of the entry
+func (ec *entryContext) outputStateData(index uint64) ([][]byte, error) {
+	dest, err := ec.destination(index)
+	if err != nil {
+		return nil, err
+	}
+
+	switch e := dest.(type) {
+	case *bc.OriginalOutput:
+		return e.StateData, nil
+
+	case *bc.VoteOutput:
+		return e.StateData, nil
+
+	case *bc.Retirement:
+		return [][]byte{}, nil
+	}
+	return nil, vm.ErrContext
+}
+
+// destination returns the entry at the index of the destinations of the entry,
+// the destinations of a mux are the ones of the mux the entry flows into
+func (ec *entryContext) destination(index uint64) (bc.Entry, error) {
+	muxDestination := func(m *bc.Mux) (bc.Entry, error) {
+		if index >= uint64(len(m.WitnessDestinations)) {
+			return nil, errors.Wrapf(vm.ErrBadValue, "index %d >= %d", index, len(m.WitnessDestinations))
+		}
+
+		eID := m.WitnessDestinations[index].Ref
+		e, ok := ec.entries[*eID]
+		if !ok {
+			return nil, errors.Wrapf(bc.ErrMissingEntry, "entry for mux destination %d, id %x, not found", index, eID.Bytes())
+		}
+		return e, nil
+	}
+
+	var dest *bc.ValueDestination
+	switch e := ec.entry.(type) {
+	case *bc.Mux:
+		return muxDestination(e)
+
+	case *bc.Issuance:
+		dest = e.WitnessDestination
+
+	case *bc.Spend:
+		dest = e.WitnessDestination
+
+	case *bc.VetoInput:
+		dest = e.WitnessDestination
+
+	default:
+		return nil, vm.ErrContext
+	}
+
+	d, ok := ec.entries[*dest.Ref]
+	if !ok {
+		return nil, errors.Wrapf(bc.ErrMissingEntry, "entry for destination %x not found", dest.Ref.Bytes())
+	}
+
+	if m, ok := d.(*bc.Mux); ok {
+		return muxDestination(m)
+	}
+
+	if index != 0 {
+		return nil, errors.Wrapf(vm.ErrBadValue, "index %d >= 1", index)
+	}
+	return d, nil
+}
+
 func bytesEqual(a, b [][]byte) bool {
 	if (a == nil) != (b == nil) {
 		return false
@@ -19,7 +19,19 @@ import (
 // be inferred.
 // Input may include jump-target labels of the form $foo, which can
 // then be used as JUMP:$foo or JUMPIF:$foo.
+// Only the ops of VM version 1 are assembled, see AssembleVersion.
 func Assemble(s string) (res []byte, err error) {
+	return AssembleVersion(s, 1)
+}
+
+// AssembleVersion assembles the program with the ops of the VM version, the
+// ops added by a later version are rejected as unknown tokens
+func AssembleVersion(s string, version uint64) (res []byte, err error) {
+	table, ok := versionOps[version]
+	if !ok {
+		return nil, ErrUnsupportedVM
+	}
+
 	// maps labels to the location each refers to
 	locations := make(map[string]uint32)
 
@@ -50,7 +62,7 @@ func Assemble(s string) (res []byte, err error) {
 	scanner.Split(split)
 	for scanner.Scan() {
 		token := scanner.Text()
-		if info, ok := opsByName[token]; ok {
+		if info, ok := table.byName[token]; ok {
 			if strings.HasPrefix(token, "PUSHDATA") || strings.HasPrefix(token, "JUMP") {
 				return nil, errors.Wrap(ErrToken, token)
 			}
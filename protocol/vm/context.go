@@ -22,6 +22,11 @@ type Context struct {
 	TxVersion   *uint64
 	BlockHeight *uint64
 
+	// LockTime is the lock time of the transaction and Sequence is the
+	// relative lock of the spend, they're introspected since VM version 2
+	LockTime *uint64
+	Sequence *uint64
+
 	// Fields below this point are required by particular opcodes when
 	// verifying transaction components.
 
@@ -33,4 +38,14 @@ type Context struct {
 
 	TxSigHash   func() []byte
 	CheckOutput func(index uint64, amount uint64, assetID []byte, vmVersion uint64, code []byte, state [][]byte, expansion bool) (bool, error)
+
+	// OutputStateData returns the state data of the output at the index of
+	// the destinations, it's introspected since VM version 2
+	OutputStateData func(index uint64) ([][]byte, error)
+
+	// Tracer, if non-nil, receives every executed step
+	Tracer Tracer
+
+	// Profiler, if non-nil, receives the gas of every executed instruction
+	Profiler Profiler
 }
@@ -84,6 +84,12 @@ func opCheckPredicate(vm *virtualMachine) error {
 	vm.dataStack = vm.dataStack[:l-n]
 
 	childErr := childVM.run()
+	if childErr == nil && childVM.falseResult() && vm.context != nil && vm.context.Tracer != nil {
+		childVM.traceResult(ErrFalseVMResult)
+	}
+	if vm.context != nil && vm.context.Profiler != nil {
+		vm.childGas = limit - childVM.runLimit
+	}
 
 	vm.deferCost(-childVM.runLimit)
 	vm.deferCost(-stackCost(childVM.dataStack))
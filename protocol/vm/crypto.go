@@ -71,6 +71,38 @@ func opCheckSig(vm *virtualMachine) error {
 	return vm.pushBool(ed25519.Verify(ed25519.PublicKey(pubkeyBytes), msg, sig), true)
 }
 
+// opCheckSigFromStack verifies the signature of a message of any length taken
+// from the stack, unlike CHECKSIG which only takes a 32 bytes hash
+func opCheckSigFromStack(vm *virtualMachine) error {
+	if err := vm.applyCost(1024); err != nil {
+		return err
+	}
+
+	pubkeyBytes, err := vm.pop(true)
+	if err != nil {
+		return err
+	}
+
+	msg, err := vm.pop(true)
+	if err != nil {
+		return err
+	}
+
+	sig, err := vm.pop(true)
+	if err != nil {
+		return err
+	}
+
+	if err := vm.applyCost(int64(len(msg))); err != nil {
+		return err
+	}
+
+	if len(pubkeyBytes) != ed25519.PublicKeySize {
+		return vm.pushBool(false, true)
+	}
+	return vm.pushBool(ed25519.Verify(ed25519.PublicKey(pubkeyBytes), msg, sig), true)
+}
+
 func opCheckMultiSig(vm *virtualMachine) error {
 	numPubkeysBigInt, err := vm.popBigInt(true)
 	if err != nil {
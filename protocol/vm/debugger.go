@@ -0,0 +1,105 @@
+package vm
+
+import (
+	"github.com/bytom/bytom/errors"
+)
+
+// ErrProgramDone is returned for stepping a program that already stopped
+var ErrProgramDone = errors.New("program execution is finished")
+
+// Debugger runs the program of the context one top level instruction at a
+// time, a CHECKPREDICATE runs its whole predicate in a single step
+type Debugger struct {
+	vm   *virtualMachine
+	done bool
+	err  error
+}
+
+// NewDebugger creates a debugger stopped before the first instruction
+func NewDebugger(context *Context, gasLimit int64) (*Debugger, error) {
+	if !IsSupportedVersion(context.VMVersion) {
+		return nil, ErrUnsupportedVM
+	}
+
+	vm, err := newVirtualMachine(context, gasLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Debugger{vm: vm}
+	d.checkDone()
+	return d, nil
+}
+
+func (d *Debugger) checkDone() {
+	if d.err == nil && d.vm.pc < uint32(len(d.vm.program)) {
+		return
+	}
+
+	d.done = true
+	if d.err == nil && d.vm.falseResult() {
+		d.err = ErrFalseVMResult
+	}
+}
+
+// Step executes the next instruction, the returned error is the failure of
+// the instruction
+func (d *Debugger) Step() (err error) {
+	if d.done {
+		return ErrProgramDone
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			if rErr, ok := r.(error); ok {
+				err = errors.Sub(ErrUnexpected, rErr)
+			} else {
+				err = errors.Wrap(ErrUnexpected, r)
+			}
+		}
+		d.err = err
+		d.checkDone()
+	}()
+
+	return d.vm.step()
+}
+
+// Done reports whether the program stopped, by running to the end or by
+// failing
+func (d *Debugger) Done() bool {
+	return d.done
+}
+
+// Err returns the result of the finished program, nil while it still runs
+// or when it succeeds
+func (d *Debugger) Err() error {
+	return d.err
+}
+
+// PC returns the position of the next instruction
+func (d *Debugger) PC() uint32 {
+	return d.vm.pc
+}
+
+// Next returns the instruction executed by the next step
+func (d *Debugger) Next() (Instruction, error) {
+	if d.done {
+		return Instruction{}, ErrProgramDone
+	}
+	return ParseOp(d.vm.program, d.vm.pc)
+}
+
+// GasLeft returns the gas the program can still use
+func (d *Debugger) GasLeft() int64 {
+	return d.vm.runLimit
+}
+
+// DataStack returns a copy of the data stack, the top item is the last
+func (d *Debugger) DataStack() [][]byte {
+	return append([][]byte{}, d.vm.dataStack...)
+}
+
+// AltStack returns a copy of the alt stack, the top item is the last
+func (d *Debugger) AltStack() [][]byte {
+	return append([][]byte{}, d.vm.altStack...)
+}
@@ -0,0 +1,70 @@
+package vm
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/bytom/bytom/errors"
+)
+
+func TestDebugger(t *testing.T) {
+	prog, err := Assemble("TOALTSTACK 2 ADD 3 NUMEQUAL")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	context := &Context{VMVersion: 1, Code: prog, Arguments: [][]byte{{0x01}, {0x07}}}
+	d, err := NewDebugger(context, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantOps := []Op{OP_TOALTSTACK, OP_2, OP_ADD, OP_3, OP_NUMEQUAL}
+	for i, op := range wantOps {
+		inst, err := d.Next()
+		if err != nil || inst.Op != op {
+			t.Fatalf("step %d: next instruction got %v %v want %v", i, inst.Op, err, op)
+		}
+
+		if err := d.Step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+
+		if i == 0 && (len(d.AltStack()) != 1 || !bytes.Equal(d.AltStack()[0], []byte{0x07})) {
+			t.Fatalf("alt stack after TOALTSTACK got %x", d.AltStack())
+		}
+	}
+
+	if !d.Done() || d.Err() != nil || d.PC() != uint32(len(prog)) {
+		t.Fatalf("finished program got done %v, err %v, pc %d", d.Done(), d.Err(), d.PC())
+	}
+
+	if err := d.Step(); err != ErrProgramDone {
+		t.Fatalf("step after done got %v want %v", err, ErrProgramDone)
+	}
+
+	context.Arguments = [][]byte{{0x02}, {0x07}}
+	if d, err = NewDebugger(context, 10000); err != nil {
+		t.Fatal(err)
+	}
+
+	for !d.Done() {
+		if err := d.Step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if d.Err() != ErrFalseVMResult {
+		t.Fatalf("got result %v want %v", d.Err(), ErrFalseVMResult)
+	}
+
+	context.Code, _ = Assemble("VERIFY TRUE")
+	context.Arguments = [][]byte{{}}
+	if d, err = NewDebugger(context, 10000); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := d.Step(); errors.Root(err) != ErrVerifyFailed || !d.Done() || d.Err() != err {
+		t.Fatalf("failed step got %v, done %v, result %v", err, d.Done(), d.Err())
+	}
+}
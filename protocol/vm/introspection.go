@@ -50,6 +50,39 @@ func opCheckOutput(vm *virtualMachine) error {
 	return vm.pushBool(ok, true)
 }
 
+// opOutputState pushes the state data items of the output at the popped index
+// followed by the number of the items
+func opOutputState(vm *virtualMachine) error {
+	if err := vm.applyCost(16); err != nil {
+		return err
+	}
+
+	index, err := vm.popBigInt(true)
+	if err != nil {
+		return err
+	}
+
+	if !index.IsUint64() {
+		return ErrBadValue
+	}
+
+	if vm.context.OutputStateData == nil {
+		return ErrContext
+	}
+
+	stateData, err := vm.context.OutputStateData(index.Uint64())
+	if err != nil {
+		return err
+	}
+
+	for _, state := range stateData {
+		if err := vm.pushDataStack(state, true); err != nil {
+			return err
+		}
+	}
+	return vm.pushBigInt(uint256.NewInt(uint64(len(stateData))), true)
+}
+
 func opAsset(vm *virtualMachine) error {
 	if err := vm.applyCost(1); err != nil {
 		return err
@@ -122,3 +155,31 @@ func opBlockHeight(vm *virtualMachine) error {
 
 	return vm.pushBigInt(uint256.NewInt(*vm.context.BlockHeight), true)
 }
+
+// opLockTime pushes the lock time of the transaction, the validation ensures
+// the transaction isn't packed before it
+func opLockTime(vm *virtualMachine) error {
+	if err := vm.applyCost(1); err != nil {
+		return err
+	}
+
+	if vm.context.LockTime == nil {
+		return ErrContext
+	}
+
+	return vm.pushBigInt(uint256.NewInt(*vm.context.LockTime), true)
+}
+
+// opSequence pushes the relative lock of the spend, the validation ensures
+// the spent output is confirmed by at least this number of blocks
+func opSequence(vm *virtualMachine) error {
+	if err := vm.applyCost(1); err != nil {
+		return err
+	}
+
+	if vm.context.Sequence == nil {
+		return ErrContext
+	}
+
+	return vm.pushBigInt(uint256.NewInt(*vm.context.Sequence), true)
+}
@@ -85,6 +85,56 @@ func TestBlockHeight(t *testing.T) {
 	}
 }
 
+func TestLockTimeAndSequence(t *testing.T) {
+	var lockTime, sequence uint64 = 6666, 10
+	cases := []struct {
+		prog    string
+		context *Context
+		wantErr error
+	}{
+		{
+			prog:    "LOCKTIME 6666 NUMEQUAL",
+			context: &Context{VMVersion: 2, LockTime: &lockTime},
+		},
+		{
+			prog:    "LOCKTIME 7777 NUMEQUAL",
+			context: &Context{VMVersion: 2, LockTime: &lockTime},
+			wantErr: ErrFalseVMResult,
+		},
+		{
+			prog:    "SEQUENCE 10 GREATERTHANOREQUAL",
+			context: &Context{VMVersion: 2, Sequence: &sequence},
+		},
+		{
+			prog:    "SEQUENCE 11 GREATERTHANOREQUAL",
+			context: &Context{VMVersion: 2, Sequence: &sequence},
+			wantErr: ErrFalseVMResult,
+		},
+		{
+			prog:    "SEQUENCE 10 GREATERTHANOREQUAL",
+			context: &Context{VMVersion: 2},
+			wantErr: ErrContext,
+		},
+	}
+
+	for i, c := range cases {
+		prog, err := AssembleVersion(c.prog, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		vm := &virtualMachine{runLimit: 50000, program: prog, context: c.context}
+		err = vm.run()
+		if err == nil && vm.falseResult() {
+			err = ErrFalseVMResult
+		}
+
+		if err != c.wantErr {
+			t.Errorf("case %d: got error %v, want %v", i, err, c.wantErr)
+		}
+	}
+}
+
 func TestIntrospectionOps(t *testing.T) {
 	// arbitrary
 	entryID := mustDecodeHex("2e68d78cdeaa98944c12512cf9c719eb4881e9afb61e4b766df5f369aee6392c")
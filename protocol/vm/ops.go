@@ -12,8 +12,10 @@ import (
 
 type Op uint8
 
+// String returns the name of the op in the latest VM version, so the ops
+// taken from the expansion space by a later version are named
 func (op Op) String() string {
-	return ops[op].name
+	return versionOps[MaxVersion].ops[op].name
 }
 
 type Instruction struct {
@@ -199,10 +201,15 @@ const (
 	OP_CHECKMULTISIG Op = 0xad
 	OP_TXSIGHASH     Op = 0xae
 
+	OP_CHECKSIGFROMSTACK Op = 0xc6
+
 	OP_CHECKOUTPUT Op = 0xc1
 	OP_ASSET       Op = 0xc2
 	OP_AMOUNT      Op = 0xc3
 	OP_PROGRAM     Op = 0xc4
+	OP_OUTPUTSTATE Op = 0xc5
+	OP_LOCKTIME    Op = 0xc7
+	OP_SEQUENCE    Op = 0xc8
 	OP_INDEX       Op = 0xc9
 	OP_ENTRYID     Op = 0xca
 	OP_OUTPUTID    Op = 0xcb
@@ -477,6 +484,7 @@ func init() {
 			isExpansion[i] = true
 		}
 	}
+	initVersionOps()
 }
 
 // IsPushdata judge instruction whether is a pushdata operation(include opFalse operation)
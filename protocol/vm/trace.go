@@ -0,0 +1,79 @@
+package vm
+
+import (
+	chainjson "github.com/bytom/bytom/encoding/json"
+)
+
+// maxTraceStackBytes bounds the bytes of each stack kept in a trace step,
+// the items on the top of the stack are kept first
+const maxTraceStackBytes = 4096
+
+// TraceStep is the state of the VM after executing one instruction, Error is
+// set on the instruction the execution fails at. A program left with a false
+// result ends with a step without op, Truncated marks the stacks cut down to
+// maxTraceStackBytes.
+type TraceStep struct {
+	Depth     int                  `json:"depth"`
+	PC        uint32               `json:"pc"`
+	Op        string               `json:"op,omitempty"`
+	Data      chainjson.HexBytes   `json:"data,omitempty"`
+	DataStack []chainjson.HexBytes `json:"data_stack"`
+	AltStack  []chainjson.HexBytes `json:"alt_stack"`
+	GasLeft   int64                `json:"gas_left"`
+	Truncated bool                 `json:"truncated,omitempty"`
+	Error     string               `json:"error,omitempty"`
+}
+
+// Tracer receives every step of the VM, the steps of a CHECKPREDICATE child
+// VM come with a greater depth
+type Tracer func(step *TraceStep)
+
+// Profiler receives the gas charged by every executed instruction, the gas of
+// a CHECKPREDICATE excludes its child program, whose instructions are
+// profiled on their own
+type Profiler func(op Op, gas int64)
+
+// traceStack copies the stack from the top down until maxTraceStackBytes,
+// it reports whether any byte is left out
+func traceStack(stack [][]byte) ([]chainjson.HexBytes, bool) {
+	kept, size := len(stack), 0
+	for ; kept > 0 && size+len(stack[kept-1]) <= maxTraceStackBytes; kept-- {
+		size += len(stack[kept-1])
+	}
+
+	result := make([]chainjson.HexBytes, 0, len(stack)-kept)
+	for _, item := range stack[kept:] {
+		result = append(result, append(chainjson.HexBytes{}, item...))
+	}
+	return result, kept > 0
+}
+
+func (vm *virtualMachine) traceStep(pc uint32, inst Instruction, err error) {
+	step := &TraceStep{
+		Depth:   vm.depth,
+		PC:      pc,
+		Op:      inst.Op.String(),
+		Data:    append(chainjson.HexBytes{}, inst.Data...),
+		GasLeft: vm.runLimit,
+	}
+	if len(inst.Data) == 0 {
+		step.Data = nil
+	}
+	vm.traceState(step, err)
+}
+
+// traceResult traces the end of a program leaving a false result
+func (vm *virtualMachine) traceResult(err error) {
+	vm.traceState(&TraceStep{Depth: vm.depth, PC: uint32(len(vm.program)), GasLeft: vm.runLimit}, err)
+}
+
+func (vm *virtualMachine) traceState(step *TraceStep, err error) {
+	var dataTruncated, altTruncated bool
+	step.DataStack, dataTruncated = traceStack(vm.dataStack)
+	step.AltStack, altTruncated = traceStack(vm.altStack)
+	step.Truncated = dataTruncated || altTruncated
+	if err != nil {
+		step.Error = err.Error()
+	}
+	vm.context.Tracer(step)
+}
@@ -0,0 +1,60 @@
+package vm
+
+// MaxVersion is the highest VM version the virtual machine runs, the chain
+// decides from which height each version is valid
+const MaxVersion = 2
+
+// opTable is the instruction set of a VM version, the unassigned opcodes are
+// expansion NOPs, byName holds the mnemonics the version assembles
+type opTable struct {
+	ops         [256]opInfo
+	isExpansion [256]bool
+	byName      map[string]opInfo
+}
+
+// versionOps maps the supported VM versions to their instruction sets
+var versionOps = map[uint64]*opTable{}
+
+// v2Ops are taken from the expansion space by VM version 2
+var v2Ops = []opInfo{
+	{OP_OUTPUTSTATE, "OUTPUTSTATE", opOutputState},
+	{OP_CHECKSIGFROMSTACK, "CHECKSIGFROMSTACK", opCheckSigFromStack},
+	{OP_LOCKTIME, "LOCKTIME", opLockTime},
+	{OP_SEQUENCE, "SEQUENCE", opSequence},
+}
+
+// initVersionOps builds the instruction set of each version on top of the
+// previous one, it runs after the version 1 table is completed
+func initVersionOps() {
+	v1 := &opTable{ops: ops, isExpansion: isExpansion, byName: opsByName}
+	versionOps[1] = v1
+
+	v2 := *v1
+	v2.byName = make(map[string]opInfo, len(v1.byName)+len(v2Ops))
+	for name, info := range v1.byName {
+		v2.byName[name] = info
+	}
+	for _, info := range v2Ops {
+		v2.ops[info.op] = info
+		v2.isExpansion[info.op] = false
+		v2.byName[info.name] = info
+	}
+	versionOps[2] = &v2
+}
+
+// IsSupportedVersion returns whether the virtual machine runs the VM version
+func IsSupportedVersion(version uint64) bool {
+	_, ok := versionOps[version]
+	return ok
+}
+
+// opTable returns the instruction set of the running program, a vm without
+// context runs version 1
+func (vm *virtualMachine) opTable() *opTable {
+	if vm.context != nil {
+		if table, ok := versionOps[vm.context.VMVersion]; ok {
+			return table
+		}
+	}
+	return versionOps[1]
+}
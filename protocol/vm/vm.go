@@ -28,6 +28,9 @@ type virtualMachine struct {
 	// CHECKPREDICATE spawns a child vm with depth+1
 	depth int
 
+	// childGas is the gas used by the child vm of the current instruction
+	childGas int64
+
 	// In each of these stacks, stack[len(stack)-1] is the top element.
 	dataStack [][]byte
 	altStack  [][]byte
@@ -49,10 +52,28 @@ func Verify(context *Context, gasLimit int64) (gasLeft int64, err error) {
 		}
 	}()
 
-	if context.VMVersion != 1 {
+	if !IsSupportedVersion(context.VMVersion) {
 		return gasLimit, ErrUnsupportedVM
 	}
 
+	vm, err := newVirtualMachine(context, gasLimit)
+	if err != nil {
+		return vm.runLimit, err
+	}
+
+	if err = vm.run(); err == nil && vm.falseResult() {
+		err = ErrFalseVMResult
+		if context.Tracer != nil {
+			vm.traceResult(err)
+		}
+	}
+
+	return vm.runLimit, wrapErr(err, vm, context.Arguments)
+}
+
+// newVirtualMachine creates the top level vm with the state data and the
+// arguments pushed
+func newVirtualMachine(context *Context, gasLimit int64) (*virtualMachine, error) {
 	vm := &virtualMachine{
 		expansionReserved: context.TxVersion != nil && *context.TxVersion == 1,
 		program:           context.Code,
@@ -61,22 +82,17 @@ func Verify(context *Context, gasLimit int64) (gasLeft int64, err error) {
 	}
 
 	for i, state := range context.StateData {
-		if err = vm.pushAltStack(state, false); err != nil {
-			return vm.runLimit, errors.Wrapf(err, "pushing initial statedata %d", i)
+		if err := vm.pushAltStack(state, false); err != nil {
+			return vm, errors.Wrapf(err, "pushing initial statedata %d", i)
 		}
 	}
 
 	for i, arg := range context.Arguments {
-		if err = vm.pushDataStack(arg, false); err != nil {
-			return vm.runLimit, errors.Wrapf(err, "pushing initial argument %d", i)
+		if err := vm.pushDataStack(arg, false); err != nil {
+			return vm, errors.Wrapf(err, "pushing initial argument %d", i)
 		}
 	}
-
-	if err = vm.run(); err == nil && vm.falseResult() {
-		err = ErrFalseVMResult
-	}
-
-	return vm.runLimit, wrapErr(err, vm, context.Arguments)
+	return vm, nil
 }
 
 // falseResult returns true iff the stack is empty or the top
@@ -97,9 +113,25 @@ func (vm *virtualMachine) run() error {
 func (vm *virtualMachine) step() error {
 	inst, err := ParseOp(vm.program, vm.pc)
 	if err != nil {
+		if vm.context != nil && vm.context.Tracer != nil {
+			vm.traceStep(vm.pc, Instruction{Op: Op(vm.program[vm.pc])}, err)
+		}
 		return err
 	}
 
+	pc, gasLeft := vm.pc, vm.runLimit
+	vm.childGas = 0
+	err = vm.execute(inst)
+	if vm.context != nil && vm.context.Tracer != nil {
+		vm.traceStep(pc, inst, err)
+	}
+	if vm.context != nil && vm.context.Profiler != nil {
+		vm.context.Profiler(inst.Op, gasLeft-vm.runLimit-vm.childGas)
+	}
+	return err
+}
+
+func (vm *virtualMachine) execute(inst Instruction) error {
 	vm.nextPC = vm.pc + inst.Len
 
 	if TraceOut != nil {
@@ -111,7 +143,8 @@ func (vm *virtualMachine) step() error {
 		fmt.Fprint(TraceOut, "\n")
 	}
 
-	if isExpansion[inst.Op] {
+	table := vm.opTable()
+	if table.isExpansion[inst.Op] {
 		if vm.expansionReserved {
 			return ErrDisallowedOpcode
 		}
@@ -122,11 +155,11 @@ func (vm *virtualMachine) step() error {
 
 	vm.deferredCost = 0
 	vm.data = inst.Data
-	if err = ops[inst.Op].fn(vm); err != nil {
+	if err := table.ops[inst.Op].fn(vm); err != nil {
 		return err
 	}
 
-	if err = vm.applyCost(vm.deferredCost); err != nil {
+	if err := vm.applyCost(vm.deferredCost); err != nil {
 		return err
 	}
 
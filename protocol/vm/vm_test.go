@@ -2,6 +2,8 @@ package vm
 
 import (
 	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
 	"fmt"
 	"os"
 	"strings"
@@ -172,7 +174,7 @@ func TestVerifyTxInput(t *testing.T) {
 			gasLeft: 9986,
 		},
 		{
-			vctx:    &Context{VMVersion: 2},
+			vctx:    &Context{VMVersion: MaxVersion + 1},
 			wantErr: ErrUnsupportedVM,
 			gasLeft: 10000,
 		},
@@ -427,3 +429,178 @@ func TestVerifyBlockHeaderQuickCheck(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestTracer(t *testing.T) {
+	prog, err := Assemble("2 ADD 3 NUMEQUAL VERIFY 0 VERIFY")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []*TraceStep{}
+	context := &Context{
+		VMVersion: 1,
+		Code:      prog,
+		Arguments: [][]byte{{0x01}},
+		Tracer:    func(step *TraceStep) { steps = append(steps, step) },
+	}
+	if _, err := Verify(context, 10000); errors.Root(err) != ErrVerifyFailed {
+		t.Fatalf("got error %v want %v", err, ErrVerifyFailed)
+	}
+
+	wantOps := []string{"2", "ADD", "3", "NUMEQUAL", "VERIFY", "FALSE", "VERIFY"}
+	if len(steps) != len(wantOps) {
+		t.Fatalf("got %d steps want %d", len(steps), len(wantOps))
+	}
+
+	for i, step := range steps {
+		if step.Op != wantOps[i] {
+			t.Errorf("step %d: got op %s want %s", i, step.Op, wantOps[i])
+		}
+
+		if i > 0 && step.PC <= steps[i-1].PC {
+			t.Errorf("step %d: pc %d doesn't advance from %d", i, step.PC, steps[i-1].PC)
+		}
+
+		if wantErr := i == len(steps)-1; (step.Error != "") != wantErr {
+			t.Errorf("step %d: got error %q", i, step.Error)
+		}
+	}
+
+	if stack := steps[1].DataStack; len(stack) != 1 || !bytes.Equal(stack[0], []byte{0x03}) {
+		t.Errorf("data stack after ADD got %x want [03]", stack)
+	}
+
+	big := bytes.Repeat([]byte{0xff}, maxTraceStackBytes)
+	cases := []struct {
+		code      []byte
+		arguments [][]byte
+		wantErr   error
+		wantOp    string
+		truncated bool
+	}{
+		{code: []byte{byte(OP_1), byte(OP_0)}, wantErr: ErrFalseVMResult},
+		{code: []byte{byte(OP_1), byte(OP_PUSHDATA1)}, wantErr: ErrShortProgram, wantOp: "PUSHDATA1"},
+		{code: []byte{byte(OP_DROP), byte(OP_0)}, arguments: [][]byte{{0x01}, big}, wantErr: ErrFalseVMResult},
+		{code: []byte{byte(OP_0)}, arguments: [][]byte{{0x01}, big}, wantErr: ErrFalseVMResult, truncated: true},
+	}
+
+	for i, c := range cases {
+		steps = []*TraceStep{}
+		context.Code, context.Arguments = c.code, c.arguments
+		if _, err := Verify(context, 10000); errors.Root(err) != c.wantErr {
+			t.Fatalf("case %d: got error %v want %v", i, err, c.wantErr)
+		}
+
+		last := steps[len(steps)-1]
+		if last.Op != c.wantOp || last.Error != c.wantErr.Error() || last.Truncated != c.truncated {
+			t.Errorf("case %d: got last step %+v", i, last)
+		}
+	}
+}
+
+func TestProfiler(t *testing.T) {
+	// the child program of CHECKPREDICATE is profiled on its own, so the
+	// profiled gas adds up to the gas used
+	prog, err := Assemble("0x01 0 0x51935287 0 CHECKPREDICATE VERIFY 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ops, total := map[string]int{}, int64(0)
+	context := &Context{
+		VMVersion: 1,
+		Code:      prog,
+		Profiler: func(op Op, gas int64) {
+			ops[op.String()]++
+			total += gas
+		},
+	}
+
+	gasLeft, err := Verify(context, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if total != 10000-gasLeft {
+		t.Errorf("got profiled gas %d want %d", total, 10000-gasLeft)
+	}
+
+	if ops["CHECKPREDICATE"] != 1 || ops["ADD"] != 1 || ops["EQUAL"] != 1 {
+		t.Errorf("got profiled ops %v", ops)
+	}
+}
+
+func TestVersionOps(t *testing.T) {
+	pub, prv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	msg := []byte("a message longer than the 32 bytes of a hash")
+	sig := ed25519.Sign(prv, msg)
+	badSig := append([]byte{}, sig...)
+	badSig[0] ^= 0xff
+
+	if _, err := Assemble("0 OUTPUTSTATE"); errors.Root(err) != ErrToken {
+		t.Errorf("assemble version 2 op for version 1 got error %v want %v", err, ErrToken)
+	}
+
+	checkSigProg, err := AssembleVersion(fmt.Sprintf("0x%x CHECKSIGFROMSTACK", []byte(pub)), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stateProg, err := AssembleVersion("0 OUTPUTSTATE 2 NUMEQUALVERIFY 2 EQUALVERIFY 1 EQUAL", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stateData := func(index uint64) ([][]byte, error) {
+		if index != 0 {
+			return nil, ErrBadValue
+		}
+		return [][]byte{{1}, {2}}, nil
+	}
+
+	txVersion := uint64(1)
+	cases := []struct {
+		vctx    *Context
+		wantErr error
+	}{
+		{
+			vctx:    &Context{VMVersion: 2, Code: checkSigProg, Arguments: [][]byte{sig, msg}},
+			wantErr: nil,
+		},
+		{
+			vctx:    &Context{VMVersion: 2, Code: checkSigProg, Arguments: [][]byte{badSig, msg}},
+			wantErr: ErrFalseVMResult,
+		},
+		{
+			// the op is an expansion NOP of version 1
+			vctx:    &Context{VMVersion: 1, Code: checkSigProg, Arguments: [][]byte{badSig, msg}},
+			wantErr: nil,
+		},
+		{
+			vctx:    &Context{VMVersion: 1, TxVersion: &txVersion, Code: checkSigProg, Arguments: [][]byte{sig, msg}},
+			wantErr: ErrDisallowedOpcode,
+		},
+		{
+			vctx:    &Context{VMVersion: 2, Code: stateProg, OutputStateData: stateData},
+			wantErr: nil,
+		},
+		{
+			vctx:    &Context{VMVersion: 2, Code: stateProg},
+			wantErr: ErrContext,
+		},
+	}
+
+	for i, c := range cases {
+		if _, err := Verify(c.vctx, 10000); errors.Root(err) != c.wantErr {
+			t.Errorf("case %d: got error %v want %v", i, err, c.wantErr)
+		}
+	}
+
+	if OP_CHECKSIGFROMSTACK.String() != "CHECKSIGFROMSTACK" || versionOps[1].ops[OP_CHECKSIGFROMSTACK].name != "NOPxc6" {
+		t.Errorf("got op names %s and %s", OP_CHECKSIGFROMSTACK, versionOps[1].ops[OP_CHECKSIGFROMSTACK].name)
+	}
+}
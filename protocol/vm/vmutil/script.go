@@ -123,6 +123,46 @@ func P2SPMultiSigProgram(pubkeys []ed25519.PublicKey, nrequired int) ([]byte, er
 	return builder.Build()
 }
 
+// ParseP2SPMultiSigProgram returns the public keys and the quorum of a
+// program built by P2SPMultiSigProgram
+func ParseP2SPMultiSigProgram(program []byte) ([]ed25519.PublicKey, int, error) {
+	insts, err := vm.ParseProgram(program)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(insts) < 5 || insts[0].Op != vm.OP_TXSIGHASH || insts[len(insts)-1].Op != vm.OP_CHECKMULTISIG {
+		return nil, 0, errors.WithDetail(ErrBadValue, "not a multisig program")
+	}
+
+	pubkeys := []ed25519.PublicKey{}
+	for _, inst := range insts[1 : len(insts)-3] {
+		if !inst.IsPushdata() || len(inst.Data) != ed25519.PublicKeySize {
+			return nil, 0, errors.WithDetail(ErrBadValue, "invalid public key in multisig program")
+		}
+		pubkeys = append(pubkeys, ed25519.PublicKey(inst.Data))
+	}
+
+	nrequired, err := vm.AsBigInt(insts[len(insts)-3].Data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	npubkeys, err := vm.AsBigInt(insts[len(insts)-2].Data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if !npubkeys.IsUint64() || npubkeys.Uint64() != uint64(len(pubkeys)) || !nrequired.IsUint64() {
+		return nil, 0, errors.WithDetail(ErrBadValue, "pubkey count mismatch in multisig program")
+	}
+
+	if err := checkMultiSigParams(int64(nrequired.Uint64()), int64(len(pubkeys))); err != nil {
+		return nil, 0, err
+	}
+	return pubkeys, int(nrequired.Uint64()), nil
+}
+
 // P2SPMultiSigProgramWithHeight generates the script with block height for control transaction output
 func P2SPMultiSigProgramWithHeight(pubkeys []ed25519.PublicKey, nrequired int, blockHeight uint64) ([]byte, error) {
 	builder := NewBuilder()
@@ -97,6 +97,19 @@ func TestP2SPMultiSigProgram(t *testing.T) {
 		if hex.EncodeToString(got) != test.wantProgram {
 			t.Errorf("TestP2SPMultiSigProgram #%d failed: got %v want %v", i, hex.EncodeToString(got), test.wantProgram)
 		}
+
+		if test.wantErr != nil {
+			continue
+		}
+
+		pubkeys, nrequired, err := ParseP2SPMultiSigProgram(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !reflect.DeepEqual(pubkeys, test.pubkeys) || nrequired != test.nrequired {
+			t.Errorf("TestP2SPMultiSigProgram #%d parse failed: got %v %d want %v %d", i, pubkeys, nrequired, test.pubkeys, test.nrequired)
+		}
 	}
 }
 
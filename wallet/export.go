@@ -0,0 +1,248 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"sort"
+	"strconv"
+
+	"github.com/bytom/bytom/blockchain/query"
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/errors"
+)
+
+const (
+	// ExportJSON exports the wallet history as json
+	ExportJSON = "json"
+	// ExportCSV exports the wallet history as csv
+	ExportCSV = "csv"
+)
+
+// ErrExportFormat the export format is neither json nor csv
+var ErrExportFormat = errors.New("invalid export format")
+
+// CostLot is a FIFO cost-basis lot created by value received in a transaction
+type CostLot struct {
+	TxID      string `json:"tx_id"`
+	Timestamp uint64 `json:"timestamp"`
+	Amount    uint64 `json:"amount"`
+	Remaining uint64 `json:"remaining"`
+}
+
+// LotUsage records how much of a lot is consumed by an outgoing entry
+type LotUsage struct {
+	TxID   string `json:"tx_id"`
+	Amount uint64 `json:"amount"`
+}
+
+// LedgerEntry is the change of one asset of one account in a transaction
+type LedgerEntry struct {
+	AccountID    string      `json:"account_id"`
+	AccountAlias string      `json:"account_alias"`
+	Timestamp    uint64      `json:"timestamp"`
+	BlockHeight  uint64      `json:"block_height"`
+	TxID         string      `json:"tx_id"`
+	AssetID      string      `json:"asset_id"`
+	AssetAlias   string      `json:"asset_alias"`
+	Amount       int64       `json:"amount"`
+	FeeShare     uint64      `json:"fee_share"`
+	Balance      int64       `json:"balance"`
+	ConsumedLots []*LotUsage `json:"consumed_lots,omitempty"`
+}
+
+// AccountLots holds the open lots of one asset of one account
+type AccountLots struct {
+	AccountID string     `json:"account_id"`
+	AssetID   string     `json:"asset_id"`
+	Lots      []*CostLot `json:"lots"`
+}
+
+// HistoryExport is the accounting ledger of the wallet accounts
+type HistoryExport struct {
+	Entries         []*LedgerEntry   `json:"entries"`
+	OpenLots        []*AccountLots   `json:"open_lots,omitempty"`
+	ClosingBalances []AccountBalance `json:"closing_balances"`
+}
+
+// ExportFilter selects the accounts and the time range of the exported
+// entries, the running balances always start from the whole history
+type ExportFilter struct {
+	AccountID string
+	StartTime uint64
+	EndTime   uint64
+	CostBasis bool
+}
+
+type ledgerKey struct {
+	accountID string
+	assetID   string
+}
+
+// txLedgerEntries calculates the signed amount and fee share for every
+// account and asset touched by the transaction. The voted BTM is excluded the
+// same way list-balances does, so a vote moves the value out of the balance
+// and a veto moves it back.
+func txLedgerEntries(tx *query.AnnotatedTx) []*LedgerEntry {
+	var btmIn, btmOut uint64
+	btmInByAccount := map[string]uint64{}
+	amounts := map[ledgerKey]int64{}
+	entries := map[ledgerKey]*LedgerEntry{}
+	keys := []ledgerKey{}
+
+	record := func(accountID, accountAlias, assetID, assetAlias string, amount int64) {
+		key := ledgerKey{accountID: accountID, assetID: assetID}
+		if _, ok := entries[key]; !ok {
+			entries[key] = &LedgerEntry{
+				AccountID:    accountID,
+				AccountAlias: accountAlias,
+				Timestamp:    tx.Timestamp,
+				BlockHeight:  tx.BlockHeight,
+				TxID:         tx.ID.String(),
+				AssetID:      assetID,
+				AssetAlias:   assetAlias,
+			}
+			keys = append(keys, key)
+		}
+		amounts[key] += amount
+	}
+
+	for _, input := range tx.Inputs {
+		if input.AssetID == *consensus.BTMAssetID {
+			btmIn += input.Amount
+			if input.AccountID != "" {
+				btmInByAccount[input.AccountID] += input.Amount
+			}
+		}
+
+		if input.AccountID != "" && input.Type != "veto" {
+			record(input.AccountID, input.AccountAlias, input.AssetID.String(), input.AssetAlias, -int64(input.Amount))
+		}
+	}
+
+	for _, output := range tx.Outputs {
+		if output.AssetID == *consensus.BTMAssetID {
+			btmOut += output.Amount
+		}
+
+		if output.AccountID != "" && output.Type != "vote" {
+			record(output.AccountID, output.AccountAlias, output.AssetID.String(), output.AssetAlias, int64(output.Amount))
+		}
+	}
+
+	result := []*LedgerEntry{}
+	for _, key := range keys {
+		entry := entries[key]
+		entry.Amount = amounts[key]
+		if key.assetID == consensus.BTMAssetID.String() && btmIn > btmOut {
+			entry.FeeShare = (btmIn - btmOut) * btmInByAccount[key.accountID] / btmIn
+		}
+
+		if entry.Amount != 0 || entry.FeeShare != 0 {
+			result = append(result, entry)
+		}
+	}
+	return result
+}
+
+// consumeLots takes the amount from the head lots in FIFO order
+func consumeLots(lots []*CostLot, amount uint64) ([]*CostLot, []*LotUsage) {
+	usages := []*LotUsage{}
+	for amount > 0 && len(lots) > 0 {
+		lot := lots[0]
+		used := lot.Remaining
+		if used > amount {
+			used = amount
+		}
+
+		lot.Remaining -= used
+		amount -= used
+		usages = append(usages, &LotUsage{TxID: lot.TxID, Amount: used})
+		if lot.Remaining == 0 {
+			lots = lots[1:]
+		}
+	}
+	return lots, usages
+}
+
+// ExportHistory builds the per-account ledger from the annotated transactions
+func (w *Wallet) ExportHistory(filter *ExportFilter) (*HistoryExport, error) {
+	txs, err := w.ListTransactions(&TxFilter{AccountID: filter.AccountID})
+	if err != nil {
+		return nil, err
+	}
+
+	balances, err := w.GetAccountBalances(filter.AccountID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	export := &HistoryExport{Entries: []*LedgerEntry{}, ClosingBalances: balances}
+	runningBalances := map[ledgerKey]int64{}
+	openLots := map[ledgerKey][]*CostLot{}
+	for i := len(txs) - 1; i >= 0; i-- {
+		for _, entry := range txLedgerEntries(txs[i]) {
+			if filter.AccountID != "" && entry.AccountID != filter.AccountID {
+				continue
+			}
+
+			key := ledgerKey{accountID: entry.AccountID, assetID: entry.AssetID}
+			runningBalances[key] += entry.Amount
+			entry.Balance = runningBalances[key]
+			if filter.CostBasis {
+				if entry.Amount > 0 {
+					openLots[key] = append(openLots[key], &CostLot{TxID: entry.TxID, Timestamp: entry.Timestamp, Amount: uint64(entry.Amount), Remaining: uint64(entry.Amount)})
+				} else {
+					openLots[key], entry.ConsumedLots = consumeLots(openLots[key], uint64(-entry.Amount))
+				}
+			}
+
+			if (filter.StartTime == 0 || entry.Timestamp >= filter.StartTime) && (filter.EndTime == 0 || entry.Timestamp <= filter.EndTime) {
+				export.Entries = append(export.Entries, entry)
+			}
+		}
+	}
+
+	if filter.CostBasis {
+		for key, lots := range openLots {
+			export.OpenLots = append(export.OpenLots, &AccountLots{AccountID: key.accountID, AssetID: key.assetID, Lots: lots})
+		}
+		sort.Slice(export.OpenLots, func(i, j int) bool {
+			if export.OpenLots[i].AccountID != export.OpenLots[j].AccountID {
+				return export.OpenLots[i].AccountID < export.OpenLots[j].AccountID
+			}
+			return export.OpenLots[i].AssetID < export.OpenLots[j].AssetID
+		})
+	}
+	return export, nil
+}
+
+// CSV encodes the ledger entries as csv with a header line
+func (e *HistoryExport) CSV() (string, error) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+	header := []string{"timestamp", "block_height", "tx_id", "account_id", "account_alias", "asset_id", "asset_alias", "amount", "fee_share", "balance"}
+	if err := writer.Write(header); err != nil {
+		return "", err
+	}
+
+	for _, entry := range e.Entries {
+		record := []string{
+			strconv.FormatUint(entry.Timestamp, 10),
+			strconv.FormatUint(entry.BlockHeight, 10),
+			entry.TxID,
+			entry.AccountID,
+			entry.AccountAlias,
+			entry.AssetID,
+			entry.AssetAlias,
+			strconv.FormatInt(entry.Amount, 10),
+			strconv.FormatUint(entry.FeeShare, 10),
+			strconv.FormatInt(entry.Balance, 10),
+		}
+		if err := writer.Write(record); err != nil {
+			return "", err
+		}
+	}
+
+	writer.Flush()
+	return buf.String(), writer.Error()
+}
@@ -0,0 +1,119 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/bytom/bytom/asset"
+	"github.com/bytom/bytom/blockchain/query"
+	"github.com/bytom/bytom/consensus"
+	dbm "github.com/bytom/bytom/database/leveldb"
+	"github.com/bytom/bytom/event"
+)
+
+func TestExportHistory(t *testing.T) {
+	dirPath, err := ioutil.TempDir(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	testDB := dbm.NewDB("testdb", "leveldb", dirPath)
+	w := mockWallet(testDB, nil, asset.NewRegistry(testDB, nil), nil, event.NewDispatcher(), false)
+
+	btm := *consensus.BTMAssetID
+	txs := []*query.AnnotatedTx{
+		{
+			BlockHeight: 1,
+			Timestamp:   1000,
+			Inputs:      []*query.AnnotatedInput{{Type: "coinbase"}},
+			Outputs:     []*query.AnnotatedOutput{{Type: "control", AccountID: "acc1", AssetID: btm, Amount: 1000}},
+		},
+		{
+			BlockHeight: 2,
+			Timestamp:   2000,
+			Inputs:      []*query.AnnotatedInput{{Type: "spend", AccountID: "acc1", AssetID: btm, Amount: 1000}},
+			Outputs: []*query.AnnotatedOutput{
+				{Type: "control", AssetID: btm, Amount: 300},
+				{Type: "control", AccountID: "acc1", AssetID: btm, Amount: 690},
+			},
+		},
+		{
+			BlockHeight: 3,
+			Timestamp:   3000,
+			Inputs:      []*query.AnnotatedInput{{Type: "spend", AccountID: "acc1", AssetID: btm, Amount: 690}},
+			Outputs: []*query.AnnotatedOutput{
+				{Type: "vote", AccountID: "acc1", AssetID: btm, Amount: 600},
+				{Type: "control", AccountID: "acc1", AssetID: btm, Amount: 80},
+			},
+		},
+		{
+			BlockHeight: 4,
+			Timestamp:   4000,
+			Inputs:      []*query.AnnotatedInput{{Type: "veto", AccountID: "acc1", AssetID: btm, Amount: 600}},
+			Outputs:     []*query.AnnotatedOutput{{Type: "control", AccountID: "acc1", AssetID: btm, Amount: 595}},
+		},
+	}
+	mockSaveTxs(t, w, txs)
+
+	export, err := w.ExportHistory(&ExportFilter{AccountID: "acc1", CostBasis: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantEntries := []struct {
+		amount   int64
+		feeShare uint64
+		balance  int64
+		lots     []*LotUsage
+	}{
+		{amount: 1000, balance: 1000},
+		{amount: -310, feeShare: 10, balance: 690, lots: []*LotUsage{{TxID: txs[0].ID.String(), Amount: 310}}},
+		{amount: -610, feeShare: 10, balance: 80, lots: []*LotUsage{{TxID: txs[0].ID.String(), Amount: 610}}},
+		{amount: 595, feeShare: 5, balance: 675},
+	}
+	if len(export.Entries) != len(wantEntries) {
+		t.Fatalf("got %d entries want %d", len(export.Entries), len(wantEntries))
+	}
+
+	for i, want := range wantEntries {
+		got := export.Entries[i]
+		if got.TxID != txs[i].ID.String() || got.Amount != want.amount || got.FeeShare != want.feeShare || got.Balance != want.balance {
+			t.Errorf("entry %d: got %+v want %+v", i, got, want)
+		}
+
+		if len(want.lots) != 0 && !reflect.DeepEqual(got.ConsumedLots, want.lots) {
+			t.Errorf("entry %d: consumed lots got %v want %v", i, got.ConsumedLots, want.lots)
+		}
+	}
+
+	wantLots := []*CostLot{
+		{TxID: txs[0].ID.String(), Timestamp: 1000, Amount: 1000, Remaining: 80},
+		{TxID: txs[3].ID.String(), Timestamp: 4000, Amount: 595, Remaining: 595},
+	}
+	if len(export.OpenLots) != 1 || !reflect.DeepEqual(export.OpenLots[0].Lots, wantLots) {
+		t.Errorf("open lots got %v want %v", export.OpenLots, wantLots)
+	}
+
+	export, err = w.ExportHistory(&ExportFilter{StartTime: 2500})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(export.Entries) != 2 || export.Entries[0].Balance != 80 || export.Entries[1].Balance != 675 {
+		t.Fatalf("time range export got %v", export.Entries)
+	}
+
+	csv, err := export.CSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(csv), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "timestamp,") || !strings.HasSuffix(lines[2], ",595,5,675") {
+		t.Errorf("csv export got %s", csv)
+	}
+}
@@ -0,0 +1,291 @@
+package wallet
+
+import (
+	"encoding/json"
+
+	"github.com/bytom/bytom/blockchain/query"
+	"github.com/bytom/bytom/consensus/segwit"
+	"github.com/bytom/bytom/errors"
+)
+
+const (
+	// DirectionIn filters the value received by the wallet
+	DirectionIn = "in"
+	// DirectionOut filters the value sent by the wallet
+	DirectionOut = "out"
+)
+
+var txFilterTypes = map[string]bool{
+	"issue":    true,
+	"vote":     true,
+	"veto":     true,
+	"retire":   true,
+	"contract": true,
+	"coinbase": true,
+}
+
+var (
+	// ErrTxFilter the transaction filter has invalid direction or type
+	ErrTxFilter = errors.New("invalid transaction filter")
+	// ErrTxCursor the pagination cursor is not a wallet transaction
+	ErrTxCursor = errors.New("invalid transaction cursor")
+)
+
+// TxFilter is the filter for listing the wallet transaction history, zero
+// value fields are not filtered on.
+type TxFilter struct {
+	AccountID   string
+	Unconfirmed bool
+	StartHeight uint64
+	EndHeight   uint64
+	StartTime   uint64
+	EndTime     uint64
+	AssetID     string
+	MinAmount   uint64
+	MaxAmount   uint64
+	Direction   string
+	Address     string
+	TxTypes     []string
+	Label       string
+	// Memo matches the transactions with a local output carrying the
+	// decrypted memo, such as the deposit tag of a shared address
+	Memo string
+
+	// Cursor is the ID of the last transaction of the previous page, the
+	// result begins right after it
+	Cursor string
+	// Count is the max number of transactions returned, 0 means no limit
+	Count uint
+}
+
+func (f *TxFilter) validate() error {
+	if f.Direction != "" && f.Direction != DirectionIn && f.Direction != DirectionOut {
+		return errors.WithDetailf(ErrTxFilter, "direction %s", f.Direction)
+	}
+
+	for _, txType := range f.TxTypes {
+		if !txFilterTypes[txType] {
+			return errors.WithDetailf(ErrTxFilter, "transaction type %s", txType)
+		}
+	}
+
+	if f.EndHeight != 0 && f.StartHeight > f.EndHeight {
+		return errors.WithDetail(ErrTxFilter, "start height is greater than end height")
+	}
+	return nil
+}
+
+// matchValue reports whether an input or output of the transaction moves
+// the filtered asset amount in the filtered direction
+func (f *TxFilter) matchValue(tx *query.AnnotatedTx) bool {
+	if f.AssetID == "" && f.MinAmount == 0 && f.MaxAmount == 0 && f.Direction == "" {
+		return true
+	}
+
+	match := func(accountID, assetID string, amount uint64) bool {
+		if accountID == "" || (f.AccountID != "" && accountID != f.AccountID) {
+			return false
+		}
+		if f.AssetID != "" && assetID != f.AssetID {
+			return false
+		}
+		return amount >= f.MinAmount && (f.MaxAmount == 0 || amount <= f.MaxAmount)
+	}
+
+	if f.Direction != DirectionIn {
+		for _, input := range tx.Inputs {
+			if match(input.AccountID, input.AssetID.String(), input.Amount) {
+				return true
+			}
+		}
+	}
+
+	if f.Direction != DirectionOut {
+		for _, output := range tx.Outputs {
+			if match(output.AccountID, output.AssetID.String(), output.Amount) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (f *TxFilter) matchAddress(tx *query.AnnotatedTx) bool {
+	if f.Address == "" {
+		return true
+	}
+
+	for _, input := range tx.Inputs {
+		if input.Address == f.Address {
+			return true
+		}
+	}
+
+	for _, output := range tx.Outputs {
+		if output.Address == f.Address {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *TxFilter) matchMemo(tx *query.AnnotatedTx) bool {
+	for _, output := range tx.Outputs {
+		if output.AccountID != "" && output.Memo == f.Memo {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *TxFilter) matchType(tx *query.AnnotatedTx) bool {
+	if len(f.TxTypes) == 0 {
+		return true
+	}
+
+	types := txTypes(tx)
+	for _, txType := range f.TxTypes {
+		if types[txType] {
+			return true
+		}
+	}
+	return false
+}
+
+// txTypes return all the filter types the transaction belongs to
+func txTypes(tx *query.AnnotatedTx) map[string]bool {
+	types := map[string]bool{}
+	for _, input := range tx.Inputs {
+		switch input.Type {
+		case "issue", "veto", "coinbase":
+			types[input.Type] = true
+		}
+
+		if input.Type == "spend" && !segwit.IsP2WScript(input.ControlProgram) {
+			types["contract"] = true
+		}
+	}
+
+	for _, output := range tx.Outputs {
+		switch output.Type {
+		case "vote", "retire":
+			types[output.Type] = true
+		}
+
+		if output.Type == "control" && !segwit.IsP2WScript(output.ControlProgram) {
+			types["contract"] = true
+		}
+	}
+	return types
+}
+
+// Match reports whether the transaction satisfies all the conditions of the filter
+func (f *TxFilter) Match(tx *query.AnnotatedTx) bool {
+	if f.AccountID != "" && !findTransactionsByAccount(tx, f.AccountID) {
+		return false
+	}
+
+	if f.StartHeight != 0 && tx.BlockHeight < f.StartHeight {
+		return false
+	}
+
+	if f.EndHeight != 0 && tx.BlockHeight > f.EndHeight {
+		return false
+	}
+
+	if f.StartTime != 0 && tx.Timestamp < f.StartTime {
+		return false
+	}
+
+	if f.EndTime != 0 && tx.Timestamp > f.EndTime {
+		return false
+	}
+
+	if f.Label != "" && !MatchTxLabel(tx, f.Label) {
+		return false
+	}
+
+	if f.Memo != "" && !f.matchMemo(tx) {
+		return false
+	}
+
+	return f.matchValue(tx) && f.matchAddress(tx) && f.matchType(tx)
+}
+
+// ListTransactions return the wallet transactions satisfying the filter,
+// newest first, unconfirmed transactions are placed ahead of confirmed ones.
+func (w *Wallet) ListTransactions(filter *TxFilter) ([]*query.AnnotatedTx, error) {
+	if err := filter.validate(); err != nil {
+		return nil, err
+	}
+
+	annotatedTxs := []*query.AnnotatedTx{}
+	cursor := filter.Cursor
+	if filter.Unconfirmed {
+		unconfirmedTxs, err := w.GetUnconfirmedTxs(filter.AccountID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range unconfirmedTxs {
+			if cursor != "" {
+				if tx.ID.String() == cursor {
+					cursor = ""
+				}
+				continue
+			}
+
+			if filter.Count != 0 && uint(len(annotatedTxs)) >= filter.Count {
+				return annotatedTxs, nil
+			}
+
+			// unconfirmed transactions has no block height
+			if filter.StartHeight == 0 && filter.EndHeight == 0 && filter.Match(tx) {
+				annotatedTxs = append(annotatedTxs, tx)
+			}
+		}
+	}
+
+	var start []byte
+	if filter.EndHeight != 0 {
+		start = calcDeleteKey(filter.EndHeight + 1)
+	}
+
+	if cursor != "" {
+		formatKey := w.DB.Get(calcTxIndexKey(cursor))
+		if formatKey == nil {
+			return nil, errors.WithDetailf(ErrTxCursor, "transaction %s not found", cursor)
+		}
+
+		if cursorKey := calcAnnotatedKey(string(formatKey)); start == nil || string(cursorKey) < string(start) {
+			start = cursorKey
+		}
+	}
+
+	txIter := w.DB.IteratorPrefixWithStart([]byte(TxPrefix), start, true)
+	defer txIter.Release()
+
+	for txIter.Next() {
+		if filter.Count != 0 && uint(len(annotatedTxs)) >= filter.Count {
+			break
+		}
+
+		annotatedTx := &query.AnnotatedTx{}
+		if err := json.Unmarshal(txIter.Value(), annotatedTx); err != nil {
+			return nil, err
+		}
+
+		if annotatedTx.BlockHeight < filter.StartHeight {
+			break
+		}
+
+		annotateTxsLabel(w, []*query.AnnotatedTx{annotatedTx})
+		w.annotateTxsMemo([]*query.AnnotatedTx{annotatedTx})
+		if filter.Match(annotatedTx) {
+			annotateTxsAsset(w, []*query.AnnotatedTx{annotatedTx})
+			annotatedTxs = append(annotatedTxs, annotatedTx)
+		}
+	}
+
+	return annotatedTxs, nil
+}
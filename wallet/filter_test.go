@@ -0,0 +1,133 @@
+package wallet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/bytom/bytom/asset"
+	"github.com/bytom/bytom/blockchain/query"
+	"github.com/bytom/bytom/consensus"
+	dbm "github.com/bytom/bytom/database/leveldb"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/event"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/testutil"
+)
+
+func mockFilterTxs(t *testing.T, w *Wallet) []*query.AnnotatedTx {
+	p2wpkh := testutil.MustDecodeHexString("00140876db6ca8f4542a836f0edd42b87d095d081182")
+	contract := testutil.MustDecodeHexString("20e9108d3ca8049800727f6a3505b3a2710dc579405dde03c250f16d9a7e1e6e787403ae7cac00c0")
+	otherAsset := bc.NewAssetID([32]byte{0x01})
+
+	txs := []*query.AnnotatedTx{
+		{
+			BlockHeight: 1,
+			Timestamp:   1000,
+			Inputs:      []*query.AnnotatedInput{{Type: "coinbase"}},
+			Outputs:     []*query.AnnotatedOutput{{Type: "control", AccountID: "acc1", AssetID: *consensus.BTMAssetID, Amount: 100, ControlProgram: p2wpkh, Address: "addr1", Memo: "10086"}},
+		},
+		{
+			BlockHeight: 2,
+			Timestamp:   2000,
+			Inputs:      []*query.AnnotatedInput{{Type: "issue", AssetID: otherAsset, Amount: 50}},
+			Outputs:     []*query.AnnotatedOutput{{Type: "control", AccountID: "acc2", AssetID: otherAsset, Amount: 50, ControlProgram: p2wpkh, Address: "addr2"}},
+		},
+		{
+			BlockHeight: 3,
+			Timestamp:   3000,
+			Inputs:      []*query.AnnotatedInput{{Type: "spend", AccountID: "acc1", AssetID: *consensus.BTMAssetID, Amount: 100, ControlProgram: p2wpkh, Address: "addr1"}},
+			Outputs:     []*query.AnnotatedOutput{{Type: "vote", AccountID: "acc1", AssetID: *consensus.BTMAssetID, Amount: 90, ControlProgram: p2wpkh, Address: "addr1"}},
+		},
+		{
+			BlockHeight: 4,
+			Timestamp:   4000,
+			Inputs:      []*query.AnnotatedInput{{Type: "veto", AccountID: "acc1", AssetID: *consensus.BTMAssetID, Amount: 90, ControlProgram: p2wpkh, Address: "addr1"}},
+			Outputs:     []*query.AnnotatedOutput{{Type: "control", AssetID: *consensus.BTMAssetID, Amount: 80, ControlProgram: contract}},
+		},
+		{
+			BlockHeight: 5,
+			Timestamp:   5000,
+			Inputs:      []*query.AnnotatedInput{{Type: "spend", AccountID: "acc2", AssetID: otherAsset, Amount: 50, ControlProgram: p2wpkh, Address: "addr2"}},
+			Outputs:     []*query.AnnotatedOutput{{Type: "retire", AssetID: otherAsset, Amount: 50}},
+		},
+	}
+
+	mockSaveTxs(t, w, txs)
+	return txs
+}
+
+// mockSaveTxs assigns the transaction IDs and stores them as indexed wallet transactions
+func mockSaveTxs(t *testing.T, w *Wallet, txs []*query.AnnotatedTx) {
+	for i, tx := range txs {
+		tx.ID = bc.NewHash([32]byte{byte(i + 1)})
+		rawTx, err := json.Marshal(tx)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		w.DB.Set(calcAnnotatedKey(formatKey(tx.BlockHeight, tx.Position)), rawTx)
+		w.DB.Set(calcTxIndexKey(tx.ID.String()), []byte(formatKey(tx.BlockHeight, tx.Position)))
+	}
+}
+
+func TestListTransactions(t *testing.T) {
+	dirPath, err := ioutil.TempDir(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	testDB := dbm.NewDB("testdb", "leveldb", dirPath)
+	w := mockWallet(testDB, nil, asset.NewRegistry(testDB, nil), nil, event.NewDispatcher(), false)
+	txs := mockFilterTxs(t, w)
+	if _, err := w.SetLabel(LabelTransaction, txs[1].ID.String(), []string{"invoice"}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		filter *TxFilter
+		want   []uint64
+		err    error
+	}{
+		{filter: &TxFilter{}, want: []uint64{5, 4, 3, 2, 1}},
+		{filter: &TxFilter{Count: 2}, want: []uint64{5, 4}},
+		{filter: &TxFilter{Cursor: txs[3].ID.String(), Count: 2}, want: []uint64{3, 2}},
+		{filter: &TxFilter{StartHeight: 2, EndHeight: 4}, want: []uint64{4, 3, 2}},
+		{filter: &TxFilter{EndHeight: 4, Cursor: txs[4].ID.String()}, want: []uint64{4, 3, 2, 1}},
+		{filter: &TxFilter{StartTime: 2500, EndTime: 4500}, want: []uint64{4, 3}},
+		{filter: &TxFilter{AccountID: "acc1"}, want: []uint64{4, 3, 1}},
+		{filter: &TxFilter{AccountID: "acc1", Direction: DirectionOut}, want: []uint64{4, 3}},
+		{filter: &TxFilter{Direction: DirectionIn, MinAmount: 60}, want: []uint64{3, 1}},
+		{filter: &TxFilter{AssetID: txs[1].Outputs[0].AssetID.String()}, want: []uint64{5, 2}},
+		{filter: &TxFilter{Address: "addr2"}, want: []uint64{5, 2}},
+		{filter: &TxFilter{TxTypes: []string{"issue", "retire"}}, want: []uint64{5, 2}},
+		{filter: &TxFilter{TxTypes: []string{"vote"}}, want: []uint64{3}},
+		{filter: &TxFilter{TxTypes: []string{"veto"}}, want: []uint64{4}},
+		{filter: &TxFilter{TxTypes: []string{"contract"}}, want: []uint64{4}},
+		{filter: &TxFilter{Label: "invoice"}, want: []uint64{2}},
+		{filter: &TxFilter{Memo: "10086"}, want: []uint64{1}},
+		{filter: &TxFilter{Memo: "10010"}, want: []uint64{}},
+		{filter: &TxFilter{TxTypes: []string{"transfer"}}, err: ErrTxFilter},
+		{filter: &TxFilter{Direction: "both"}, err: ErrTxFilter},
+		{filter: &TxFilter{Cursor: "ff"}, err: ErrTxCursor},
+	}
+
+	for i, c := range cases {
+		got, err := w.ListTransactions(c.filter)
+		if errors.Root(err) != c.err {
+			t.Fatalf("case %d: got error %v want %v", i, err, c.err)
+		}
+
+		if len(got) != len(c.want) {
+			t.Fatalf("case %d: got %d transactions want %d", i, len(got), len(c.want))
+		}
+
+		for j, tx := range got {
+			if tx.BlockHeight != c.want[j] {
+				t.Errorf("case %d: transaction %d got height %d want %d", i, j, tx.BlockHeight, c.want[j])
+			}
+		}
+	}
+}
@@ -119,6 +119,8 @@ type TxSummary struct {
 	Timestamp uint64    `json:"block_time"`
 	Inputs    []Summary `json:"inputs"`
 	Outputs   []Summary `json:"outputs"`
+	Labels    []string  `json:"labels,omitempty"`
+	Note      string    `json:"note,omitempty"`
 }
 
 // indexTransactions saves all annotated transactions to the database.
@@ -208,6 +210,8 @@ func (w *Wallet) getAccountTxByTxID(txID string) (*query.AnnotatedTx, error) {
 	}
 
 	annotateTxsAsset(w, []*query.AnnotatedTx{annotatedTx})
+	annotateTxsLabel(w, []*query.AnnotatedTx{annotatedTx})
+	w.annotateTxsMemo([]*query.AnnotatedTx{annotatedTx})
 	return annotatedTx, nil
 }
 
@@ -224,7 +228,10 @@ func (w *Wallet) getGlobalTxByTxID(txID string) (*query.AnnotatedTx, error) {
 	}
 
 	tx := block.Transactions[int(pos)]
-	return w.buildAnnotatedTransaction(tx, block, int(pos)), nil
+	annotatedTx := w.buildAnnotatedTransaction(tx, block, int(pos))
+	annotateTxsLabel(w, []*query.AnnotatedTx{annotatedTx})
+	w.annotateTxsMemo([]*query.AnnotatedTx{annotatedTx})
+	return annotatedTx, nil
 }
 
 // GetTransactionsSummary get transactions summary
@@ -237,6 +244,8 @@ func (w *Wallet) GetTransactionsSummary(transactions []*query.AnnotatedTx) []TxS
 			Outputs:   make([]Summary, len(annotatedTx.Outputs)),
 			ID:        annotatedTx.ID,
 			Timestamp: annotatedTx.Timestamp,
+			Labels:    annotatedTx.Labels,
+			Note:      annotatedTx.Note,
 		}
 
 		for i, input := range annotatedTx.Inputs {
@@ -293,6 +302,8 @@ func (w *Wallet) GetTransactions(accountID string) ([]*query.AnnotatedTx, error)
 
 		if accountID == "" || findTransactionsByAccount(annotatedTx, accountID) {
 			annotateTxsAsset(w, []*query.AnnotatedTx{annotatedTx})
+			annotateTxsLabel(w, []*query.AnnotatedTx{annotatedTx})
+			w.annotateTxsMemo([]*query.AnnotatedTx{annotatedTx})
 			annotatedTxs = append([]*query.AnnotatedTx{annotatedTx}, annotatedTxs...)
 		}
 	}
@@ -0,0 +1,212 @@
+package wallet
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/bytom/bytom/blockchain/query"
+	"github.com/bytom/bytom/errors"
+)
+
+const (
+	// LabelPrefix is wallet database label prefix
+	LabelPrefix = "LBL:"
+
+	// LabelTransaction labels a transaction by its tx id
+	LabelTransaction = "transaction"
+	// LabelOutput labels an output by its output id
+	LabelOutput = "output"
+	// LabelAddress labels an address
+	LabelAddress = "address"
+)
+
+var (
+	// ErrLabelTarget the label target is not transaction, output or address
+	ErrLabelTarget = errors.New("invalid label target")
+	// ErrLabelNotFound no label is attached to the target
+	ErrLabelNotFound = errors.New("label not found")
+)
+
+// Label is the user metadata attached to a transaction, output or address
+type Label struct {
+	Target string   `json:"target"`
+	ID     string   `json:"id"`
+	Labels []string `json:"labels"`
+	Note   string   `json:"note"`
+}
+
+// LabelImage is the struct for hold export label data
+type LabelImage struct {
+	Labels []*Label `json:"labels"`
+}
+
+func calcLabelPrefix(target string) []byte {
+	return []byte(LabelPrefix + target + ":")
+}
+
+func calcLabelKey(target, id string) []byte {
+	return append(calcLabelPrefix(target), id...)
+}
+
+func validLabelTarget(target string) bool {
+	switch target {
+	case LabelTransaction, LabelOutput, LabelAddress:
+		return true
+	}
+	return false
+}
+
+// Match reports whether the keyword equals one of the labels or is
+// contained in the note, case insensitive. A blank keyword matches nothing
+func (l *Label) Match(keyword string) bool {
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	if keyword == "" {
+		return false
+	}
+
+	for _, label := range l.Labels {
+		if label == keyword {
+			return true
+		}
+	}
+	return strings.Contains(strings.ToLower(l.Note), keyword)
+}
+
+// SetLabel attaches the labels and note to the target, replacing any existing one
+func (w *Wallet) SetLabel(target, id string, labels []string, note string) (*Label, error) {
+	if !validLabelTarget(target) {
+		return nil, errors.WithDetailf(ErrLabelTarget, "target %s", target)
+	}
+
+	label := &Label{Target: target, ID: id, Labels: []string{}, Note: note}
+	for _, l := range labels {
+		if l = strings.ToLower(strings.TrimSpace(l)); l != "" {
+			label.Labels = append(label.Labels, l)
+		}
+	}
+
+	rawLabel, err := json.Marshal(label)
+	if err != nil {
+		return nil, err
+	}
+
+	w.DB.Set(calcLabelKey(target, id), rawLabel)
+	return label, nil
+}
+
+// GetLabel return the label attached to the target, nil if there is none
+func (w *Wallet) GetLabel(target, id string) *Label {
+	rawLabel := w.DB.Get(calcLabelKey(target, id))
+	if rawLabel == nil {
+		return nil
+	}
+
+	label := &Label{}
+	if err := json.Unmarshal(rawLabel, label); err != nil {
+		return nil
+	}
+	return label
+}
+
+// DeleteLabel removes the label attached to the target
+func (w *Wallet) DeleteLabel(target, id string) error {
+	if !validLabelTarget(target) {
+		return errors.WithDetailf(ErrLabelTarget, "target %s", target)
+	}
+
+	key := calcLabelKey(target, id)
+	if w.DB.Get(key) == nil {
+		return ErrLabelNotFound
+	}
+
+	w.DB.Delete(key)
+	return nil
+}
+
+// ListLabels return all the labels of the target type matching the keyword,
+// every target type is listed when target is empty
+func (w *Wallet) ListLabels(target, keyword string) ([]*Label, error) {
+	prefix := []byte(LabelPrefix)
+	if target != "" {
+		if !validLabelTarget(target) {
+			return nil, errors.WithDetailf(ErrLabelTarget, "target %s", target)
+		}
+		prefix = calcLabelPrefix(target)
+	}
+
+	labels := []*Label{}
+	labelIter := w.DB.IteratorPrefix(prefix)
+	defer labelIter.Release()
+
+	for labelIter.Next() {
+		label := &Label{}
+		if err := json.Unmarshal(labelIter.Value(), label); err != nil {
+			return nil, err
+		}
+
+		if strings.TrimSpace(keyword) == "" || label.Match(keyword) {
+			labels = append(labels, label)
+		}
+	}
+	return labels, nil
+}
+
+// BackupLabels export all the labels into image
+func (w *Wallet) BackupLabels() (*LabelImage, error) {
+	labels, err := w.ListLabels("", "")
+	if err != nil {
+		return nil, err
+	}
+
+	return &LabelImage{Labels: labels}, nil
+}
+
+// RestoreLabels import the label image into wallet
+func (w *Wallet) RestoreLabels(image *LabelImage) error {
+	storeBatch := w.DB.NewBatch()
+	for _, label := range image.Labels {
+		if !validLabelTarget(label.Target) {
+			return errors.WithDetailf(ErrLabelTarget, "target %s", label.Target)
+		}
+
+		rawLabel, err := json.Marshal(label)
+		if err != nil {
+			return err
+		}
+
+		storeBatch.Set(calcLabelKey(label.Target, label.ID), rawLabel)
+	}
+
+	storeBatch.Write()
+	return nil
+}
+
+// annotateTxsLabel adds user labels and notes to transactions and their outputs
+func annotateTxsLabel(w *Wallet, txs []*query.AnnotatedTx) {
+	for _, tx := range txs {
+		if label := w.GetLabel(LabelTransaction, tx.ID.String()); label != nil {
+			tx.Labels, tx.Note = label.Labels, label.Note
+		}
+
+		for _, output := range tx.Outputs {
+			if label := w.GetLabel(LabelOutput, output.OutputID.String()); label != nil {
+				output.Labels, output.Note = label.Labels, label.Note
+			}
+		}
+	}
+}
+
+// MatchTxLabel reports whether the transaction or one of its outputs carries
+// a label or note matching the keyword
+func MatchTxLabel(tx *query.AnnotatedTx, keyword string) bool {
+	if (&Label{Labels: tx.Labels, Note: tx.Note}).Match(keyword) {
+		return true
+	}
+
+	for _, output := range tx.Outputs {
+		if (&Label{Labels: output.Labels, Note: output.Note}).Match(keyword) {
+			return true
+		}
+	}
+	return false
+}
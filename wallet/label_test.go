@@ -0,0 +1,98 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/bytom/bytom/blockchain/query"
+	dbm "github.com/bytom/bytom/database/leveldb"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/event"
+	"github.com/bytom/bytom/protocol/bc"
+)
+
+func TestWalletLabel(t *testing.T) {
+	dirPath, err := ioutil.TempDir(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	testDB := dbm.NewDB("testdb", "leveldb", dirPath)
+	w := mockWallet(testDB, nil, nil, nil, event.NewDispatcher(), false)
+
+	if _, err := w.SetLabel("block", "1", []string{"invoice"}, ""); errors.Root(err) != ErrLabelTarget {
+		t.Fatalf("set label with invalid target got error %v want %v", err, ErrLabelTarget)
+	}
+
+	txID := bc.NewHash([32]byte{0x01})
+	outputID := bc.NewHash([32]byte{0x02})
+	if _, err := w.SetLabel(LabelTransaction, txID.String(), []string{" Invoice ", ""}, "INV-2026-001 office rent"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.SetLabel(LabelOutput, outputID.String(), []string{"change"}, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.SetLabel(LabelAddress, "bn1qaddress", []string{"exchange"}, "hot wallet deposit"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := &Label{Target: LabelTransaction, ID: txID.String(), Labels: []string{"invoice"}, Note: "INV-2026-001 office rent"}
+	if got := w.GetLabel(LabelTransaction, txID.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("get label got %v want %v", got, want)
+	}
+
+	cases := []struct {
+		target  string
+		keyword string
+		count   int
+	}{
+		{target: "", keyword: "", count: 3},
+		{target: LabelAddress, keyword: "", count: 1},
+		{target: "", keyword: "  ", count: 3},
+		{target: "", keyword: "invoice", count: 1},
+		{target: "", keyword: "inv-2026", count: 1},
+		{target: LabelOutput, keyword: "invoice", count: 0},
+	}
+	for i, c := range cases {
+		labels, err := w.ListLabels(c.target, c.keyword)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(labels) != c.count {
+			t.Errorf("case %d: list labels got %d want %d", i, len(labels), c.count)
+		}
+	}
+
+	tx := &query.AnnotatedTx{ID: txID, Outputs: []*query.AnnotatedOutput{{OutputID: outputID}}}
+	annotateTxsLabel(w, []*query.AnnotatedTx{tx})
+	if !MatchTxLabel(tx, "invoice") || !MatchTxLabel(tx, "change") || MatchTxLabel(tx, "exchange") || MatchTxLabel(tx, " ") {
+		t.Errorf("annotated transaction labels mismatch: %v %v", tx.Labels, tx.Outputs[0].Labels)
+	}
+
+	image, err := w.BackupLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.DeleteLabel(LabelTransaction, txID.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := w.DeleteLabel(LabelTransaction, txID.String()); err != ErrLabelNotFound {
+		t.Fatalf("delete label twice got error %v want %v", err, ErrLabelNotFound)
+	}
+
+	if err := w.RestoreLabels(image); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := w.GetLabel(LabelTransaction, txID.String()); !reflect.DeepEqual(got, want) {
+		t.Errorf("restored label got %v want %v", got, want)
+	}
+}
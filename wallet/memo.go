@@ -0,0 +1,112 @@
+package wallet
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/bytom/bytom/account"
+	"github.com/bytom/bytom/blockchain/memo"
+	"github.com/bytom/bytom/blockchain/query"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+)
+
+var memoKeyKey = []byte("memoKey")
+
+// loadMemoKey loads the view key of the wallet, it's generated on the first
+// start. The memos are encrypted to its children instead of the account keys,
+// so they are read without unlocking a spending key or asking a remote signer
+func (w *Wallet) loadMemoKey() error {
+	if rawKey := w.DB.Get(memoKeyKey); rawKey != nil {
+		if len(rawKey) != len(w.memoKey) {
+			return errors.New("invalid memo key in the wallet database")
+		}
+
+		copy(w.memoKey[:], rawKey)
+		return nil
+	}
+
+	memoKey, err := chainkd.NewXPrv(rand.Reader)
+	if err != nil {
+		return err
+	}
+
+	w.DB.Set(memoKeyKey, memoKey.Bytes())
+	w.memoKey = memoKey
+	return nil
+}
+
+// memoPrv returns the key receiving the memos of the control program, a
+// hardened child of the wallet memo key for every address
+func (w *Wallet) memoPrv(cp *account.CtrlProgram) chainkd.XPrv {
+	var index [8]byte
+	binary.LittleEndian.PutUint64(index[:], cp.KeyIndex)
+	change := []byte{0}
+	if cp.Change {
+		change[0] = 1
+	}
+
+	xprv := w.memoKey
+	for _, sel := range [][]byte{[]byte(cp.AccountID), change, index[:]} {
+		xprv = xprv.Child(sel, true)
+	}
+	return xprv
+}
+
+// MemoPublicKey returns the key the senders encrypt the memos of the control
+// program to
+func (w *Wallet) MemoPublicKey(cp *account.CtrlProgram) ed25519.PublicKey {
+	return w.memoPrv(cp).XPub().PublicKey()
+}
+
+// openOutputMemo decrypts the memo of the local output, false is returned
+// when the output has no memo
+func (w *Wallet) openOutputMemo(output *query.AnnotatedOutput) (string, bool, error) {
+	stateData := [][]byte{}
+	for _, str := range output.StateData {
+		if data, err := hex.DecodeString(str); err == nil {
+			stateData = append(stateData, data)
+		}
+	}
+
+	envelope, ok := memo.Find(stateData)
+	if !ok {
+		return "", false, nil
+	}
+
+	cp, err := w.AccountMgr.GetLocalCtrlProgramByAddress(output.Address)
+	if err != nil {
+		return "", true, err
+	}
+
+	plaintext, err := envelope.Open(w.memoPrv(cp))
+	return string(plaintext), true, err
+}
+
+// annotateTxsMemo decrypts the memos of the local outputs with the memo key
+// of the wallet, a memo which can't be decrypted stays marked as encrypted.
+// It's only called when the transactions are read, the saved records keep
+// the ciphertext in the state data of the outputs
+func (w *Wallet) annotateTxsMemo(txs []*query.AnnotatedTx) {
+	for _, tx := range txs {
+		for _, output := range tx.Outputs {
+			if output.AccountID == "" || output.Memo != "" {
+				continue
+			}
+
+			text, ok, err := w.openOutputMemo(output)
+			if !ok {
+				continue
+			}
+
+			if err != nil {
+				log.WithFields(log.Fields{"module": logModule, "output_id": output.OutputID.String(), "err": err}).Debug("memo stays encrypted")
+			}
+			output.Memo, output.EncryptedMemo = text, err != nil
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/bytom/bytom/account"
+	"github.com/bytom/bytom/blockchain/memo"
+	"github.com/bytom/bytom/blockchain/query"
+	"github.com/bytom/bytom/blockchain/signers"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	dbm "github.com/bytom/bytom/database/leveldb"
+	"github.com/bytom/bytom/event"
+	"github.com/bytom/bytom/testutil"
+)
+
+func TestAnnotateTxsMemo(t *testing.T) {
+	dirPath, err := ioutil.TempDir(".", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dirPath)
+
+	testDB := dbm.NewDB("testdb", "leveldb", dirPath)
+	accountManager := account.NewManager(testDB, nil)
+	testAccount, err := accountManager.Create([]chainkd.XPub{testutil.TestXPub}, 1, "testAccount", signers.BIP0044)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cp, err := accountManager.CreateAddress(testAccount.ID, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := mockWallet(testDB, accountManager, nil, nil, event.NewDispatcher(), false)
+	if err := w.loadMemoKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	path, err := signers.Path(testAccount.Signer, signers.AccountKeySpace, cp.Change, cp.KeyIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(w.MemoPublicKey(cp)) == string(testutil.TestXPub.Derive(path).PublicKey()) {
+		t.Fatal("memo key is the account key of the address")
+	}
+
+	sealed, err := memo.Seal(w.MemoPublicKey(cp), []byte("deposit tag 10086"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sealedToAccount, err := memo.Seal(testutil.TestXPub.Derive(path).PublicKey(), []byte("deposit tag 10010"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txs := []*query.AnnotatedTx{{Outputs: []*query.AnnotatedOutput{
+		{AccountID: testAccount.ID, Address: cp.Address, StateData: []string{hex.EncodeToString(sealed)}},
+		{AccountID: testAccount.ID, Address: cp.Address, StateData: []string{hex.EncodeToString(sealedToAccount)}},
+		{AccountID: testAccount.ID, Address: cp.Address},
+	}}}
+	w.annotateTxsMemo(txs)
+
+	outputs := txs[0].Outputs
+	if outputs[0].Memo != "deposit tag 10086" || outputs[0].EncryptedMemo {
+		t.Errorf("memo to the memo key got %q, encrypted %v", outputs[0].Memo, outputs[0].EncryptedMemo)
+	}
+
+	if outputs[1].Memo != "" || !outputs[1].EncryptedMemo {
+		t.Errorf("memo to the account key got %q, encrypted %v", outputs[1].Memo, outputs[1].EncryptedMemo)
+	}
+
+	if outputs[2].Memo != "" || outputs[2].EncryptedMemo {
+		t.Errorf("output without memo got %q, encrypted %v", outputs[2].Memo, outputs[2].EncryptedMemo)
+	}
+
+	restarted := mockWallet(testDB, accountManager, nil, nil, event.NewDispatcher(), false)
+	if err := restarted.loadMemoKey(); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(restarted.MemoPublicKey(cp)) != string(w.MemoPublicKey(cp)) {
+		t.Error("memo key changes after restart")
+	}
+}
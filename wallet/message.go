@@ -0,0 +1,75 @@
+package wallet
+
+import (
+	"github.com/bytom/bytom/blockchain/message"
+	"github.com/bytom/bytom/blockchain/signers"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+// SignFunc signs the message with the key derived from the account xpub
+type SignFunc func(xpub chainkd.XPub, path [][]byte, msg []byte) ([]byte, error)
+
+// SignMessage signs the versioned message of the local address with every
+// account key held by the signer, the utxos must belong to the address
+func (w *Wallet) SignMessage(address string, msg []byte, utxos []bc.Hash, sign SignFunc) (*message.SignedMessage, error) {
+	cp, err := w.AccountMgr.GetLocalCtrlProgramByAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := w.AccountMgr.GetAccountByProgram(cp)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, utxo := range utxos {
+		accountUtxos := w.GetAccountUtxos(account.ID, utxo.String(), false, false, false)
+		if len(accountUtxos) != 1 || accountUtxos[0].Address != cp.Address {
+			return nil, errors.WithDetailf(message.ErrUTXO, "utxo %s", utxo.String())
+		}
+	}
+
+	path, err := signers.Path(account.Signer, signers.AccountKeySpace, cp.Change, cp.KeyIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	keys, err := w.Signer.ListKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	heldKeys := map[chainkd.XPub]bool{}
+	for _, key := range keys {
+		heldKeys[key.XPub] = true
+	}
+
+	signed := &message.SignedMessage{Statement: *message.NewStatement(cp.Address, msg, utxos), Signatures: []*message.Signature{}}
+	derivedXPubs := chainkd.DeriveXPubs(account.XPubs, path)
+	if len(account.XPubs) > 1 {
+		if signed.RedeemScript, err = vmutil.P2SPMultiSigProgram(chainkd.XPubKeys(derivedXPubs), account.Quorum); err != nil {
+			return nil, err
+		}
+	}
+
+	digest := signed.Digest()
+	for i, xpub := range account.XPubs {
+		if !heldKeys[xpub] {
+			continue
+		}
+
+		sig, err := sign(xpub, path, digest)
+		if err != nil {
+			return nil, err
+		}
+		signed.Signatures = append(signed.Signatures, &message.Signature{PublicKey: []byte(derivedXPubs[i].PublicKey()), Signature: sig})
+	}
+
+	if len(signed.Signatures) == 0 {
+		return nil, errors.WithDetail(message.ErrQuorum, "no key of the account is held by the signer")
+	}
+	return signed, nil
+}
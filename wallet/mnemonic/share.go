@@ -0,0 +1,137 @@
+package mnemonic
+
+import (
+	"encoding/binary"
+	"errors"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+)
+
+const (
+	// ShareValueLength is the byte length of the secret carried by a share
+	ShareValueLength = 64
+	// ShareWordsLength is the number of words of a share mnemonic, made of a
+	// 16 bits id, 4 bits threshold, 4 bits count, 4 bits index, the value and
+	// a 32 bits checksum
+	ShareWordsLength = (16 + 4 + 4 + 4 + ShareValueLength*8 + 32) / 11
+
+	shareChecksumBits = 32
+)
+
+var (
+	// ErrShareLength is returned when the share mnemonic has the wrong number
+	// of words or the share value has the wrong size
+	ErrShareLength = errors.New("Share mnemonic length error")
+
+	// ErrShareChecksum is returned when the share mnemonic has the incorrect
+	// checksum
+	ErrShareChecksum = errors.New("Share checksum incorrect")
+
+	// ErrShareParameter is returned when the threshold, count or index of the
+	// share is out of range
+	ErrShareParameter = errors.New("Share parameter out of range")
+)
+
+// Share is one of the M-of-N shares of a secret, every share of a set has the
+// same id, threshold and count
+type Share struct {
+	ID        uint16
+	Threshold int
+	Count     int
+	Index     int
+	Value     []byte
+}
+
+func (s *Share) validate() error {
+	if s.Count < 1 || s.Count > 16 || s.Threshold < 1 || s.Threshold > s.Count || s.Index < 1 || s.Index > 16 {
+		return ErrShareParameter
+	}
+
+	if len(s.Value) != ShareValueLength {
+		return ErrShareLength
+	}
+	return nil
+}
+
+func (s *Share) checksum() uint32 {
+	data := make([]byte, 5, 5+len(s.Value))
+	binary.BigEndian.PutUint16(data, s.ID)
+	data[2], data[3], data[4] = byte(s.Threshold), byte(s.Count), byte(s.Index)
+	hash := sha3.Sum256(append(data, s.Value...))
+	return binary.BigEndian.Uint32(hash[:4])
+}
+
+// NewShareMnemonic encodes the share as a mnemonic of ShareWordsLength words
+func NewShareMnemonic(share *Share, language string) (string, error) {
+	wordList, err := SetWordList(language)
+	if err != nil {
+		return "", err
+	}
+
+	if err := share.validate(); err != nil {
+		return "", err
+	}
+
+	header := uint64(share.ID)<<12 | uint64(share.Threshold-1)<<8 | uint64(share.Count-1)<<4 | uint64(share.Index-1)
+	data := new(big.Int).SetUint64(header)
+	data.Lsh(data, ShareValueLength*8)
+	data.Or(data, new(big.Int).SetBytes(share.Value))
+	data.Lsh(data, shareChecksumBits)
+	data.Or(data, new(big.Int).SetUint64(uint64(share.checksum())))
+
+	words := make([]string, ShareWordsLength)
+	word := big.NewInt(0)
+	for i := ShareWordsLength - 1; i >= 0; i-- {
+		word.And(data, last11BitsMask)
+		data.Rsh(data, 11)
+		words[i] = wordList[word.Uint64()]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// ParseShareMnemonic decodes the share mnemonic and verifies its checksum
+func ParseShareMnemonic(mnemonic string, language string) (*Share, error) {
+	wordMap, err := SetWordMap(language)
+	if err != nil {
+		return nil, err
+	}
+
+	words := strings.Fields(mnemonic)
+	if len(words) != ShareWordsLength {
+		return nil, ErrShareLength
+	}
+
+	data := big.NewInt(0)
+	for _, v := range words {
+		index, ok := wordMap[v]
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+
+		data.Lsh(data, 11)
+		data.Or(data, big.NewInt(int64(index)))
+	}
+
+	checksum := uint32(new(big.Int).And(data, big.NewInt(1<<shareChecksumBits-1)).Uint64())
+	data.Rsh(data, shareChecksumBits)
+	value := padByteSlice(new(big.Int).And(data, new(big.Int).Sub(new(big.Int).Lsh(bigOne, ShareValueLength*8), bigOne)).Bytes(), ShareValueLength)
+	header := data.Rsh(data, ShareValueLength*8).Uint64()
+
+	share := &Share{
+		ID:        uint16(header >> 12),
+		Threshold: int(header>>8&0xf) + 1,
+		Count:     int(header>>4&0xf) + 1,
+		Index:     int(header&0xf) + 1,
+		Value:     value,
+	}
+	if share.checksum() != checksum {
+		return nil, ErrShareChecksum
+	}
+
+	if err := share.validate(); err != nil {
+		return nil, err
+	}
+	return share, nil
+}
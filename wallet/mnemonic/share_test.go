@@ -0,0 +1,52 @@
+package mnemonic
+
+import (
+	"bytes"
+	"crypto/rand"
+	"strings"
+	"testing"
+)
+
+func TestShareMnemonic(t *testing.T) {
+	value := make([]byte, ShareValueLength)
+	if _, err := rand.Read(value); err != nil {
+		t.Fatal(err)
+	}
+
+	share := &Share{ID: 0xbeef, Threshold: 3, Count: 5, Index: 4, Value: value}
+	for _, language := range []string{"en", "zh_CN", "ja"} {
+		mnemonic, err := NewShareMnemonic(share, language)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		words := strings.Fields(mnemonic)
+		if len(words) != ShareWordsLength {
+			t.Fatalf("%s share got %d words want %d", language, len(words), ShareWordsLength)
+		}
+
+		got, err := ParseShareMnemonic(mnemonic, language)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.ID != share.ID || got.Threshold != share.Threshold || got.Count != share.Count || got.Index != share.Index || !bytes.Equal(got.Value, share.Value) {
+			t.Errorf("%s share got %+v want %+v", language, got, share)
+		}
+
+		words[7], words[8] = words[8], words[7]
+		if words[7] != words[8] {
+			if _, err := ParseShareMnemonic(strings.Join(words, " "), language); err != ErrShareChecksum {
+				t.Errorf("%s swapped words got error %v want %v", language, err, ErrShareChecksum)
+			}
+		}
+	}
+
+	if _, err := NewShareMnemonic(&Share{Threshold: 2, Count: 17, Index: 1, Value: value}, "en"); err != ErrShareParameter {
+		t.Errorf("count out of range got error %v want %v", err, ErrShareParameter)
+	}
+
+	if _, err := ParseShareMnemonic("abandon abandon", "en"); err != ErrShareLength {
+		t.Errorf("short share got error %v want %v", err, ErrShareLength)
+	}
+}
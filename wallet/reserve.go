@@ -0,0 +1,303 @@
+package wallet
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/bytom/bytom/blockchain/message"
+	"github.com/bytom/bytom/common"
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/protocol"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+// ErrReserveProof the reserve proof does not verify
+var ErrReserveProof = errors.New("invalid reserve proof")
+
+// ReserveUTXO is an output of the reserves with the transaction creating it
+// and the merkle proof of the transaction in its block
+type ReserveUTXO struct {
+	OutputID    bc.Hash    `json:"output_id"`
+	Address     string     `json:"address"`
+	AssetID     bc.AssetID `json:"asset_id"`
+	Amount      uint64     `json:"amount"`
+	BlockHash   bc.Hash    `json:"block_hash"`
+	Transaction *types.Tx  `json:"raw_transaction"`
+	TxHashes    []*bc.Hash `json:"tx_hashes"`
+	Flags       []uint32   `json:"flags"`
+}
+
+// ReserveProof lists the outputs confirmed at the snapshot block, every
+// address signs the snapshot block hash over its outputs
+type ReserveProof struct {
+	BlockHeight uint64                   `json:"block_height"`
+	BlockHash   bc.Hash                  `json:"block_hash"`
+	UTXOs       []*ReserveUTXO           `json:"utxos"`
+	Signatures  []*message.SignedMessage `json:"signatures"`
+}
+
+// ReserveReport is the result of a verified reserve proof, the totals only
+// count the outputs proven unspent at the snapshot block
+type ReserveReport struct {
+	BlockHeight uint64            `json:"block_height"`
+	BlockHash   bc.Hash           `json:"block_hash"`
+	Totals      map[string]uint64 `json:"totals"`
+	SpentUTXOs  []bc.Hash         `json:"spent_utxos"`
+}
+
+func addressProgram(address string) ([]byte, error) {
+	addr, err := common.DecodeAddress(address, &consensus.ActiveNetParams)
+	if err != nil {
+		return nil, err
+	}
+
+	switch addr.(type) {
+	case *common.AddressWitnessPubKeyHash:
+		return vmutil.P2WPKHProgram(addr.ScriptAddress())
+	case *common.AddressWitnessScriptHash:
+		return vmutil.P2WSHProgram(addr.ScriptAddress())
+	}
+	return nil, message.ErrAddress
+}
+
+// newReserveUTXO builds the inclusion proof of the output in the block
+func newReserveUTXO(block *types.Block, outputID bc.Hash, address string) (*ReserveUTXO, error) {
+	for _, tx := range block.Transactions {
+		for i := range tx.Outputs {
+			if *tx.OutputID(i) != outputID {
+				continue
+			}
+
+			hashes, compactFlags := types.GetTxMerkleTreeProof(block.Transactions, []*types.Tx{tx})
+			flags := make([]uint32, len(compactFlags))
+			for j, flag := range compactFlags {
+				flags[j] = uint32(flag)
+			}
+
+			return &ReserveUTXO{
+				OutputID:    outputID,
+				Address:     address,
+				AssetID:     *tx.Outputs[i].AssetId,
+				Amount:      tx.Outputs[i].Amount,
+				BlockHash:   block.Hash(),
+				Transaction: tx,
+				TxHashes:    hashes,
+				Flags:       flags,
+			}, nil
+		}
+	}
+	return nil, errors.WithDetailf(ErrReserveProof, "output %s not found in block %d", outputID.String(), block.Height)
+}
+
+// verify checks the output is created by the transaction with the claimed
+// value and address, and the transaction is in the block of the header
+func (u *ReserveUTXO) verify(header *types.BlockHeader) error {
+	if u.Transaction == nil {
+		return errors.WithDetailf(ErrReserveProof, "output %s has no transaction", u.OutputID.String())
+	}
+
+	flags := make([]uint8, len(u.Flags))
+	for i, flag := range u.Flags {
+		flags[i] = uint8(flag)
+	}
+
+	if !types.ValidateTxMerkleTreeProof(u.TxHashes, flags, []*bc.Hash{&u.Transaction.ID}, header.TransactionsMerkleRoot) {
+		return errors.WithDetailf(ErrReserveProof, "merkle proof of output %s", u.OutputID.String())
+	}
+
+	program, err := addressProgram(u.Address)
+	if err != nil {
+		return err
+	}
+
+	for i, output := range u.Transaction.Outputs {
+		if *u.Transaction.OutputID(i) != u.OutputID {
+			continue
+		}
+
+		if *output.AssetId != u.AssetID || output.Amount != u.Amount || !bytes.Equal(output.ControlProgram, program) {
+			return errors.WithDetailf(ErrReserveProof, "output %s does not match the transaction", u.OutputID.String())
+		}
+		return nil
+	}
+	return errors.WithDetailf(ErrReserveProof, "output %s not found in the transaction", u.OutputID.String())
+}
+
+// spentAfter collects the outputs spent by the main chain blocks after the
+// snapshot height, these outputs were still unspent at the snapshot
+func spentAfter(chain *protocol.Chain, height uint64) (map[bc.Hash]bool, error) {
+	spent := map[bc.Hash]bool{}
+	for h := height + 1; h <= chain.BestBlockHeight(); h++ {
+		block, err := chain.GetBlockByHeight(h)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range block.Transactions {
+			for _, inputID := range tx.InputIDs {
+				if spend, err := tx.Spend(inputID); err == nil {
+					spent[*spend.SpentOutputId] = true
+				}
+			}
+		}
+	}
+	return spent, nil
+}
+
+// unspentAt checks the output is unspent at the snapshot, it is either unspent
+// now or spent by a block after the snapshot
+func unspentAt(chain *protocol.Chain, outputID bc.Hash, spent map[bc.Hash]bool) bool {
+	if entry, err := chain.GetUtxo(&outputID); err == nil && !entry.Spent {
+		return true
+	}
+	return spent[outputID]
+}
+
+// GenerateReserveProof proves the outputs of the accounts unspent at the
+// block height, the best block is used when the height is zero
+func (w *Wallet) GenerateReserveProof(accountIDs []string, blockHeight uint64, sign SignFunc) (*ReserveProof, error) {
+	if blockHeight == 0 {
+		blockHeight = w.chain.BestBlockHeight()
+	}
+
+	header, err := w.chain.GetHeaderByHeight(blockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	spent, err := spentAfter(w.chain, blockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &ReserveProof{BlockHeight: blockHeight, BlockHash: header.Hash(), UTXOs: []*ReserveUTXO{}, Signatures: []*message.SignedMessage{}}
+	addressUTXOs := map[string][]bc.Hash{}
+	blocks := map[uint64]*types.Block{}
+	for _, accountID := range accountIDs {
+		if _, err := w.AccountMgr.FindByID(accountID); err != nil {
+			return nil, err
+		}
+
+		txs, err := w.GetTransactions(accountID)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, tx := range txs {
+			if tx.BlockHeight > blockHeight {
+				continue
+			}
+
+			for _, output := range tx.Outputs {
+				if output.AccountID != accountID || output.Type != "control" || !unspentAt(w.chain, output.OutputID, spent) {
+					continue
+				}
+
+				block, ok := blocks[tx.BlockHeight]
+				if !ok {
+					if block, err = w.chain.GetBlockByHeight(tx.BlockHeight); err != nil {
+						return nil, err
+					}
+					blocks[tx.BlockHeight] = block
+				}
+
+				reserveUTXO, err := newReserveUTXO(block, output.OutputID, output.Address)
+				if err != nil {
+					return nil, err
+				}
+
+				proof.UTXOs = append(proof.UTXOs, reserveUTXO)
+				addressUTXOs[output.Address] = append(addressUTXOs[output.Address], output.OutputID)
+			}
+		}
+	}
+
+	addresses := []string{}
+	for address := range addressUTXOs {
+		addresses = append(addresses, address)
+	}
+	sort.Strings(addresses)
+
+	for _, address := range addresses {
+		signed, err := w.SignMessage(address, proof.BlockHash.Bytes(), addressUTXOs[address], sign)
+		if err != nil {
+			return nil, err
+		}
+		proof.Signatures = append(proof.Signatures, signed)
+	}
+	return proof, nil
+}
+
+// VerifyReserveProof checks the proof against the main chain, it needs no
+// wallet so any node can verify the reserves
+func VerifyReserveProof(chain *protocol.Chain, proof *ReserveProof) (*ReserveReport, error) {
+	header, err := chain.GetHeaderByHeight(proof.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	if header.Hash() != proof.BlockHash {
+		return nil, errors.WithDetailf(ErrReserveProof, "block %d is not in the main chain", proof.BlockHeight)
+	}
+
+	signedUTXOs := map[bc.Hash]string{}
+	for _, signed := range proof.Signatures {
+		if err := message.Verify(signed, &consensus.ActiveNetParams); err != nil {
+			return nil, errors.WithDetailf(ErrReserveProof, "signature of address %s: %v", signed.Address, err)
+		}
+
+		if !bytes.Equal(signed.Message, proof.BlockHash.Bytes()) {
+			return nil, errors.WithDetailf(ErrReserveProof, "signature of address %s is not for the snapshot block", signed.Address)
+		}
+
+		for _, utxo := range signed.UTXOs {
+			signedUTXOs[utxo] = signed.Address
+		}
+	}
+
+	spent, err := spentAfter(chain, proof.BlockHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReserveReport{
+		BlockHeight: proof.BlockHeight,
+		BlockHash:   proof.BlockHash,
+		Totals:      map[string]uint64{},
+		SpentUTXOs:  []bc.Hash{},
+	}
+	seen := map[bc.Hash]bool{}
+	for _, utxo := range proof.UTXOs {
+		if seen[utxo.OutputID] {
+			return nil, errors.WithDetailf(ErrReserveProof, "duplicated output %s", utxo.OutputID.String())
+		}
+		seen[utxo.OutputID] = true
+
+		if signedUTXOs[utxo.OutputID] != utxo.Address {
+			return nil, errors.WithDetailf(ErrReserveProof, "output %s is not signed by address %s", utxo.OutputID.String(), utxo.Address)
+		}
+
+		utxoHeader, err := chain.GetHeaderByHash(&utxo.BlockHash)
+		if err != nil {
+			return nil, err
+		}
+
+		if !chain.InMainChain(utxo.BlockHash) || utxoHeader.Height > proof.BlockHeight {
+			return nil, errors.WithDetailf(ErrReserveProof, "block of output %s is not in the main chain before the snapshot", utxo.OutputID.String())
+		}
+
+		if err := utxo.verify(utxoHeader); err != nil {
+			return nil, err
+		}
+
+		if !unspentAt(chain, utxo.OutputID, spent) {
+			report.SpentUTXOs = append(report.SpentUTXOs, utxo.OutputID)
+			continue
+		}
+		report.Totals[utxo.AssetID.String()] += utxo.Amount
+	}
+	return report, nil
+}
@@ -0,0 +1,153 @@
+package wallet
+
+import (
+	"os"
+	"testing"
+
+	"github.com/bytom/bytom/common"
+	"github.com/bytom/bytom/consensus"
+	"github.com/bytom/bytom/database"
+	dbm "github.com/bytom/bytom/database/leveldb"
+	"github.com/bytom/bytom/database/storage"
+	"github.com/bytom/bytom/errors"
+	"github.com/bytom/bytom/event"
+	"github.com/bytom/bytom/protocol"
+	"github.com/bytom/bytom/protocol/bc"
+	"github.com/bytom/bytom/protocol/bc/types"
+	"github.com/bytom/bytom/protocol/state"
+	"github.com/bytom/bytom/protocol/vm/vmutil"
+)
+
+func TestReserveUTXOProof(t *testing.T) {
+	address, err := common.NewAddressWitnessPubKeyHash(make([]byte, 20), &consensus.ActiveNetParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	program, err := vmutil.P2WPKHProgram(make([]byte, 20))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block := &types.Block{}
+	for i := 0; i < 5; i++ {
+		block.Transactions = append(block.Transactions, types.NewTx(types.TxData{
+			Version: 1,
+			Inputs:  []*types.TxInput{types.NewSpendInput(nil, bc.NewHash([32]byte{byte(i)}), *consensus.BTMAssetID, 1000, 0, []byte{0x51}, nil)},
+			Outputs: []*types.TxOutput{
+				types.NewOriginalTxOutput(*consensus.BTMAssetID, 100, []byte{0x51}, nil),
+				types.NewOriginalTxOutput(*consensus.BTMAssetID, uint64(800+i), program, nil),
+			},
+		}))
+	}
+
+	txs := []*bc.Tx{}
+	for _, tx := range block.Transactions {
+		txs = append(txs, tx.Tx)
+	}
+
+	if block.TransactionsMerkleRoot, err = types.TxMerkleRoot(txs); err != nil {
+		t.Fatal(err)
+	}
+
+	outputID := *block.Transactions[3].OutputID(1)
+	utxo, err := newReserveUTXO(block, outputID, address.EncodeAddress())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if utxo.Amount != 803 || utxo.AssetID != *consensus.BTMAssetID {
+		t.Fatalf("reserve utxo got %d of %s", utxo.Amount, utxo.AssetID.String())
+	}
+
+	if err := utxo.verify(&block.BlockHeader); err != nil {
+		t.Fatal(err)
+	}
+
+	utxo.Amount++
+	if err := utxo.verify(&block.BlockHeader); errors.Root(err) != ErrReserveProof {
+		t.Errorf("verify changed amount got error %v want %v", err, ErrReserveProof)
+	}
+
+	utxo.Amount--
+	utxo.Transaction = block.Transactions[2]
+	if err := utxo.verify(&block.BlockHeader); errors.Root(err) != ErrReserveProof {
+		t.Errorf("verify another transaction got error %v want %v", err, ErrReserveProof)
+	}
+
+	if _, err := newReserveUTXO(block, bc.NewHash([32]byte{0xff}), address.EncodeAddress()); errors.Root(err) != ErrReserveProof {
+		t.Errorf("proof of unknown output got error %v want %v", err, ErrReserveProof)
+	}
+}
+
+func TestReserveUnspentAt(t *testing.T) {
+	testDB := dbm.NewDB("testdb", "leveldb", "temp")
+	defer os.RemoveAll("temp")
+
+	store := database.NewStore(testDB)
+	dispatcher := event.NewDispatcher()
+	chain, err := protocol.NewChain(store, protocol.NewTxPool(store, dispatcher), dispatcher)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesis, err := chain.GetHeaderByHeight(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spendTx := types.NewTx(types.TxData{
+		Version: 1,
+		Inputs:  []*types.TxInput{types.NewSpendInput(nil, bc.Hash{V0: 1}, *consensus.BTMAssetID, 100, 0, []byte{0x51}, nil)},
+		Outputs: []*types.TxOutput{types.NewOriginalTxOutput(*consensus.BTMAssetID, 100, []byte{0x51}, nil)},
+	})
+	spend, err := spendTx.Spend(spendTx.InputIDs[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	unspentID, spentLaterID, spentBeforeID := bc.Hash{V0: 7}, *spend.SpentOutputId, bc.Hash{V0: 8}
+	block1 := &types.Block{BlockHeader: types.BlockHeader{Version: 1, Height: 1, PreviousBlockHash: genesis.Hash(), Timestamp: genesis.Timestamp + 1}}
+	block2 := &types.Block{
+		BlockHeader:  types.BlockHeader{Version: 1, Height: 2, PreviousBlockHash: block1.Hash(), Timestamp: genesis.Timestamp + 2},
+		Transactions: []*types.Tx{spendTx},
+	}
+
+	view := state.NewUtxoViewpoint()
+	view.Entries[unspentID] = storage.NewUtxoEntry(storage.NormalUTXOType, 1, false)
+	for _, block := range []*types.Block{block1, block2} {
+		if err := store.SaveBlock(block); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := store.SaveChainStatus(&block2.BlockHeader, []*types.BlockHeader{&block1.BlockHeader, &block2.BlockHeader}, view, state.NewContractViewpoint(), 0, &bc.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if chain, err = protocol.NewChain(store, protocol.NewTxPool(store, dispatcher), dispatcher); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		height   uint64
+		outputID bc.Hash
+		want     bool
+	}{
+		{height: 1, outputID: unspentID, want: true},
+		{height: 1, outputID: spentLaterID, want: true},
+		{height: 1, outputID: spentBeforeID, want: false},
+		{height: 2, outputID: unspentID, want: true},
+		{height: 2, outputID: spentLaterID, want: false},
+	}
+
+	for i, c := range cases {
+		spent, err := spentAfter(chain, c.height)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := unspentAt(chain, c.outputID, spent); got != c.want {
+			t.Errorf("case %d: unspent at %d got %v want %v", i, c.height, got, c.want)
+		}
+	}
+}
@@ -58,6 +58,8 @@ func (w *Wallet) GetUnconfirmedTxs(accountID string) ([]*query.AnnotatedTx, erro
 
 		if accountID == "" || findTransactionsByAccount(annotatedTx, accountID) {
 			annotateTxsAsset(w, []*query.AnnotatedTx{annotatedTx})
+			annotateTxsLabel(w, []*query.AnnotatedTx{annotatedTx})
+			w.annotateTxsMemo([]*query.AnnotatedTx{annotatedTx})
 			annotatedTxs = append([]*query.AnnotatedTx{annotatedTx}, annotatedTxs...)
 		}
 	}
@@ -79,6 +81,8 @@ func (w *Wallet) GetUnconfirmedTxByTxID(txID string) (*query.AnnotatedTx, error)
 	}
 
 	annotateTxsAsset(w, []*query.AnnotatedTx{annotatedTx})
+	annotateTxsLabel(w, []*query.AnnotatedTx{annotatedTx})
+	w.annotateTxsMemo([]*query.AnnotatedTx{annotatedTx})
 	return annotatedTx, nil
 }
 
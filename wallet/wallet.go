@@ -9,7 +9,9 @@ import (
 	"github.com/bytom/bytom/account"
 	"github.com/bytom/bytom/asset"
 	"github.com/bytom/bytom/blockchain/pseudohsm"
+	"github.com/bytom/bytom/blockchain/signer"
 	"github.com/bytom/bytom/contract"
+	"github.com/bytom/bytom/crypto/ed25519/chainkd"
 	dbm "github.com/bytom/bytom/database/leveldb"
 	"github.com/bytom/bytom/errors"
 	"github.com/bytom/bytom/event"
@@ -51,12 +53,15 @@ type Wallet struct {
 	AssetReg        *asset.Registry
 	ContractReg     *contract.Registry
 	Hsm             *pseudohsm.HSM
+	Signer          signer.Signer
+	PathSigner      signer.Signer
 	chain           *protocol.Chain
 	RecoveryMgr     *recoveryManager
 	eventDispatcher *event.Dispatcher
 	txMsgSub        *event.Subscription
 
 	rescanCh chan struct{}
+	memoKey  chainkd.XPrv
 }
 
 //NewWallet return a new wallet instance
@@ -68,12 +73,24 @@ func NewWallet(walletDB dbm.DB, account *account.Manager, asset *asset.Registry,
 		ContractReg:     contract,
 		chain:           chain,
 		Hsm:             hsm,
+		Signer:          signer.NewLocalSigner(hsm),
 		RecoveryMgr:     newRecoveryManager(walletDB, account),
 		eventDispatcher: dispatcher,
 		rescanCh:        make(chan struct{}, 1),
 		TxIndexFlag:     txIndexFlag,
 	}
 
+	// arbitrary path signing is denied until a path policy is configured
+	pathSigner, err := signer.NewPolicySigner(w.Signer, &signer.PolicyConfig{})
+	if err != nil {
+		return nil, err
+	}
+	w.PathSigner = pathSigner
+
+	if err := w.loadMemoKey(); err != nil {
+		return nil, err
+	}
+
 	if err := w.loadWalletInfo(); err != nil {
 		return nil, err
 	}
@@ -82,7 +99,6 @@ func NewWallet(walletDB dbm.DB, account *account.Manager, asset *asset.Registry,
 		return nil, err
 	}
 
-	var err error
 	w.txMsgSub, err = w.eventDispatcher.Subscribe(protocol.TxMsgEvent{})
 	if err != nil {
 		return nil, err